@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
@@ -27,6 +28,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vote"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/signer/core"
@@ -213,6 +216,49 @@ Generate ownership proof for the selected BLS account from the BLS wallet. The p
 					},
 				},
 			},
+			{
+				Name:      "slashing-protection",
+				Usage:     "Manage the slashing protection history of BLS accounts",
+				ArgsUsage: "",
+				Category:  "BLS ACCOUNT COMMANDS",
+				Description: `
+
+The node keeps the highest source and target numbers of the votes signed with each
+BLS account in the chain database, and refuses to sign votes below them, which
+could be slashed.
+
+When moving a BLS account to another node, export the history from the old node
+after stopping it, and import it into the new node before voting with the account.
+The history is in the interchange JSON format of EIP-3076, with the votes in place
+of the attestations.`,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "Export the slashing protection history",
+						Action:    blsSlashingProtectionExport,
+						ArgsUsage: "<file>",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+						Description: `
+	geth bls slashing-protection export <file>
+
+Export the slashing protection history of all BLS accounts into <file>.`,
+					},
+					{
+						Name:      "import",
+						Usage:     "Import a slashing protection history",
+						Action:    blsSlashingProtectionImport,
+						ArgsUsage: "<file>",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+						Description: `
+	geth bls slashing-protection import <file>
+
+Import the slashing protection history in <file>, merging it into the history
+of the node. The file must be of the same chain.`,
+					},
+				},
+			},
 		},
 	}
 )
@@ -665,3 +711,63 @@ func blsAccountGenerateProof(ctx *cli.Context) error {
 
 	return nil
 }
+
+// blsSlashingProtectionExport exports the slashing protection history of the
+// BLS accounts into a file.
+func blsSlashingProtectionExport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true, false)
+	defer db.Close()
+
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		utils.Fatalf("Chain database not initialized.")
+	}
+	interchange := vote.ExportSlashingProtection(db, genesis)
+	data, err := json.MarshalIndent(interchange, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode slashing protection history: %v", err)
+	}
+	if err := os.WriteFile(ctx.Args().First(), data, 0600); err != nil {
+		utils.Fatalf("Failed to write slashing protection history: %v", err)
+	}
+	fmt.Printf("Exported the slashing protection history of %d BLS accounts.\n", len(interchange.Data))
+	return nil
+}
+
+// blsSlashingProtectionImport imports the slashing protection history of the
+// BLS accounts from a file.
+func blsSlashingProtectionImport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	data, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read slashing protection history: %v", err)
+	}
+	interchange := new(vote.Interchange)
+	if err := json.Unmarshal(data, interchange); err != nil {
+		utils.Fatalf("Failed to decode slashing protection history: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false, false)
+	defer db.Close()
+
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		utils.Fatalf("Chain database not initialized.")
+	}
+	imported, err := vote.ImportSlashingProtection(db, genesis, interchange)
+	if err != nil {
+		utils.Fatalf("Failed to import slashing protection history: %v", err)
+	}
+	fmt.Printf("Imported the slashing protection history of %d BLS accounts.\n", imported)
+	return nil
+}
@@ -46,6 +46,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quant"
 	"github.com/naoina/toml"
 	"github.com/urfave/cli/v2"
 )
@@ -112,6 +113,7 @@ type gethConfig struct {
 	Ethstats   ethstatsConfig
 	Metrics    metrics.Config
 	FakeBeacon fakebeacon.Config
+	Quant      quant.Config
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -148,6 +150,7 @@ func loadBaseConfig(ctx *cli.Context) gethConfig {
 		Eth:     ethconfig.Defaults,
 		Node:    defaultNodeConfig(),
 		Metrics: metrics.DefaultConfig,
+		Quant:   quant.DefaultConfig,
 	}
 
 	// Load config file.
@@ -189,6 +192,7 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 		cfg.Ethstats.URL = ctx.String(utils.EthStatsURLFlag.Name)
 	}
 	applyMetricConfig(ctx, &cfg)
+	utils.SetQuantConfig(ctx, &cfg.Quant)
 
 	return stack, cfg
 }
@@ -237,7 +241,7 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
 	// 启动一个Quant Service
-	utils.RegisterQuantService(backend, eth)
+	utils.RegisterQuantService(stack, backend, eth, &cfg.Quant, quantConfigReloader(ctx))
 
 	// Create gauge with geth system and build information
 	if eth != nil { // The 'eth' backend may be nil in light mode
@@ -285,6 +289,24 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	return stack, backend
 }
 
+// quantConfigReloader returns a function re-reading the [Quant] section of the
+// config file, or nil if the node was started without one. The command line
+// flags are applied over the file as at startup.
+func quantConfigReloader(ctx *cli.Context) func() (quant.Config, error) {
+	file := ctx.String(configFileFlag.Name)
+	if file == "" {
+		return nil
+	}
+	return func() (quant.Config, error) {
+		cfg := gethConfig{Quant: quant.DefaultConfig}
+		if err := loadConfig(file, &cfg); err != nil {
+			return quant.Config{}, err
+		}
+		utils.SetQuantConfig(ctx, &cfg.Quant)
+		return cfg.Quant, nil
+	}
+}
+
 // dumpConfig is the dumpconfig command.
 func dumpConfig(ctx *cli.Context) error {
 	_, cfg := makeConfigNode(ctx)
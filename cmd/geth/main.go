@@ -99,6 +99,10 @@ var (
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
+		utils.BundlePoolEnabledFlag,
+		utils.BundlePoolMaxBundlesFlag,
+		utils.BundlePoolAccountSlotsFlag,
+		utils.BundlePoolMaxBlocksAheadFlag,
 		utils.SyncModeFlag,
 		utils.TriesVerifyModeFlag,
 		// utils.SyncTargetFlag,
@@ -150,6 +154,8 @@ var (
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNewPayloadTimeoutFlag, // deprecated
 		utils.MinerDelayLeftoverFlag,
+		utils.BuilderEnabledFlag,
+		utils.BuilderAccountFlag,
 		// utils.MinerNewPayloadTimeout,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
@@ -179,11 +185,20 @@ var (
 		utils.BlockAmountReserved,
 		utils.CheckSnapshotWithMPT,
 		utils.EnableDoubleSignMonitorFlag,
+		utils.DoubleSignReporterFlag,
 		utils.VotingEnabledFlag,
 		utils.DisableVoteAttestationFlag,
 		utils.EnableMaliciousVoteMonitorFlag,
+		utils.MaliciousVoteReporterFlag,
+		utils.MaliciousVoteDryRunFlag,
 		utils.BLSPasswordFileFlag,
 		utils.BLSWalletDirFlag,
+		utils.BLSRemoteSignerFlag,
+		utils.BLSRemoteSignerPubKeyFlag,
+		utils.BLSRemoteSignerCACertFlag,
+		utils.BLSRemoteSignerClientCertFlag,
+		utils.BLSRemoteSignerClientKeyFlag,
+		utils.BLSRemoteSignerTimeoutFlag,
 		utils.VoteJournalDirFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
@@ -252,6 +267,14 @@ var (
 		utils.FakeBeaconAddrFlag,
 		utils.FakeBeaconPortFlag,
 	}
+
+	quantFlags = []cli.Flag{
+		utils.QuantSinkFlag,
+		utils.QuantSinkElasticURLFlag,
+		utils.QuantSinkDirFlag,
+		utils.QuantSinkMaxSizeFlag,
+		utils.QuantSinkSQLitePathFlag,
+	}
 )
 
 var app = flags.NewApp("the go-ethereum command line interface")
@@ -294,6 +317,10 @@ func init() {
 		blsCommand,
 		// See verkle.go
 		verkleCommand,
+		// See quantcmd.go
+		quantCommand,
+		// See mevcmd.go
+		mevCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
@@ -307,6 +334,7 @@ func init() {
 		debug.Flags,
 		metricsFlags,
 		fakeBeaconFlags,
+		quantFlags,
 	)
 	flags.AutoEnvVars(app.Flags, "GETH")
 
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	mevCommand = &cli.Command{
+		Name:  "mev",
+		Usage: "A set of commands to audit the MEV bids",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "replay-bid",
+				Usage:     "Replay the simulation of a dumped bid",
+				ArgsUsage: "<file>",
+				Action:    replayBid,
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth mev replay-bid <file>
+Re-runs the simulation of a bid dumped by the validator (see Mev.BidDumpDir)
+against the state of its parent block, and prints the gas used and the revert
+status of each tx along with the validator reward. The result is compared with
+the result of the original simulation.`,
+			},
+		},
+	}
+)
+
+func replayBid(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires the bid dump file as argument.")
+	}
+	dump, err := miner.ReadBidDump(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read bid dump: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	result, err := miner.ReplayBid(chain, dump)
+	if err != nil {
+		utils.Fatalf("Failed to replay bid: %v", err)
+	}
+	fmt.Printf("Bid %s of builder %s for block %d\n", dump.BidHash, dump.Builder, dump.BlockNumber)
+	for i, tx := range result.Txs {
+		status := "success"
+		if tx.Reverted {
+			status = "reverted"
+		}
+		merged := ""
+		if tx.Merged {
+			merged = " (merged)"
+		}
+		fmt.Printf("  tx %3d %s gas %9d %s%s\n", i, tx.TxHash, tx.GasUsed, status, merged)
+	}
+	fmt.Printf("Gas used:         %d\n", result.GasUsed)
+	fmt.Printf("Block reward:     %v\n", result.BlockReward)
+	fmt.Printf("Validator reward: %v\n", result.ValidatorReward)
+	if result.Error != "" {
+		fmt.Printf("Error:            %s\n", result.Error)
+	}
+	if dump.Result == nil {
+		return nil
+	}
+	if mismatch := compareBidResults(dump.Result, result); mismatch != "" {
+		fmt.Printf("Verdict: replay differs from the original simulation: %s\n", mismatch)
+	} else {
+		fmt.Println("Verdict: replay matches the original simulation")
+	}
+	return nil
+}
+
+// compareBidResults returns the first difference between the results of two
+// simulations of the same bid, or an empty string if they match.
+func compareBidResults(original, replay *miner.BidSimResult) string {
+	if len(original.Txs) != len(replay.Txs) {
+		return fmt.Sprintf("%d txs committed, originally %d", len(replay.Txs), len(original.Txs))
+	}
+	for i := range original.Txs {
+		if original.Txs[i] != replay.Txs[i] {
+			return fmt.Sprintf("tx %d %+v, originally %+v", i, replay.Txs[i], original.Txs[i])
+		}
+	}
+	switch {
+	case original.GasUsed != replay.GasUsed:
+		return fmt.Sprintf("gas used %d, originally %d", replay.GasUsed, original.GasUsed)
+	case original.BlockReward.Cmp(replay.BlockReward) != 0:
+		return fmt.Sprintf("block reward %v, originally %v", replay.BlockReward, original.BlockReward)
+	case original.ValidatorReward.Cmp(replay.ValidatorReward) != 0:
+		return fmt.Sprintf("validator reward %v, originally %v", replay.ValidatorReward, original.ValidatorReward)
+	case original.Error != replay.Error:
+		return fmt.Sprintf("error %q, originally %q", replay.Error, original.Error)
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/quant"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	backtestFromFlag = &cli.Uint64Flag{
+		Name:     "from",
+		Usage:    "First block to backtest",
+		Required: true,
+		Category: flags.QuantCategory,
+	}
+	backtestToFlag = &cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "Last block to backtest (default = from)",
+		Category: flags.QuantCategory,
+	}
+	backtestFormatFlag = &cli.StringFlag{
+		Name:     "format",
+		Usage:    "Report format (csv, json)",
+		Value:    "csv",
+		Category: flags.QuantCategory,
+	}
+	backtestOutputFlag = &cli.StringFlag{
+		Name:     "output",
+		Usage:    "File the report is written to (default = stdout)",
+		Category: flags.QuantCategory,
+	}
+	backtestReexecFlag = &cli.Uint64Flag{
+		Name:     "reexec",
+		Usage:    "Number of blocks to re-execute to regenerate a missing parent state",
+		Value:    128,
+		Category: flags.QuantCategory,
+	}
+)
+
+var quantCommand = &cli.Command{
+	Name:     "quant",
+	Usage:    "Tools of the quant service",
+	Category: "QUANT COMMANDS",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "backtest",
+			Usage:  "Replay historical blocks through the quant strategy",
+			Action: quantBacktest,
+			Flags: slices.Concat([]cli.Flag{
+				backtestFromFlag,
+				backtestToFlag,
+				backtestFormatFlag,
+				backtestOutputFlag,
+				backtestReexecFlag,
+				configFileFlag,
+			}, utils.NetworkFlags, utils.DatabaseFlags),
+			Description: `
+geth quant backtest --from N --to M
+
+replays the transactions of the blocks N to M of the local chain as if they
+were pending on top of their parent block, and reports per block how many swaps
+the strategy would have sandwiched, the gas of the bundles it would have sent
+and the hypothetical profit in BNB. Missing parent states are regenerated from
+the closest available one, up to --reexec blocks back.`,
+		},
+	},
+}
+
+func quantBacktest(ctx *cli.Context) error {
+	var (
+		from = ctx.Uint64(backtestFromFlag.Name)
+		to   = from
+	)
+	if ctx.IsSet(backtestToFlag.Name) {
+		to = ctx.Uint64(backtestToFlag.Name)
+	}
+	out := io.Writer(os.Stdout)
+	if file := ctx.String(backtestOutputFlag.Name); file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w, err := quant.NewReportWriter(out, ctx.String(backtestFormatFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	stack, cfg := makeConfigNode(ctx)
+	defer stack.Close()
+
+	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
+	chain := eth.BlockChain()
+	if head := chain.CurrentBlock().Number.Uint64(); to > head {
+		return errors.New("backtest range beyond the local head")
+	}
+	signer := quant.NewSigner(eth.AccountManager(), cfg.Quant.Account, chain.Config().ChainID, backend)
+	builder, _ := quant.NewBuilder(nil)
+	strategy := quant.NewStrategy(backend, eth, signer, builder, cfg.Quant.Balances)
+
+	reexec := ctx.Uint64(backtestReexecFlag.Name)
+	stateAt := func(ctx context.Context, block *types.Block) (*state.StateDB, func(), error) {
+		return eth.APIBackend.StateAtBlock(ctx, block, reexec, nil, true, false)
+	}
+	backtester, err := quant.NewBacktester(chain, strategy, stateAt)
+	if err != nil {
+		return err
+	}
+	var (
+		start  = time.Now()
+		blocks int
+		swaps  int
+		hits   int
+		failed int
+		gas    uint64
+		profit = new(big.Int)
+	)
+	err = backtester.Run(context.Background(), from, to, func(r *quant.BlockReport) error {
+		blocks++
+		swaps += r.Swaps
+		hits += r.Hits
+		failed += r.Failed
+		gas += r.GasSpent
+		profit.Add(profit, r.Profit)
+		return w.Write(r)
+	})
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("Backtest finished", "blocks", blocks, "swaps", swaps, "hits", hits, "failed", failed, "gas", gas,
+		"profit", profit, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
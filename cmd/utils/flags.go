@@ -47,6 +47,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -537,6 +538,30 @@ var (
 		Value:    ethconfig.Defaults.BlobPool.PriceBump,
 		Category: flags.BlobPoolCategory,
 	}
+	// Bundle pool settings
+	BundlePoolEnabledFlag = &cli.BoolFlag{
+		Name:     "bundlepool",
+		Usage:    "Enable the bundle pool accepting eth_sendBundle (always on in builder mode)",
+		Category: flags.TxPoolCategory,
+	}
+	BundlePoolMaxBundlesFlag = &cli.Uint64Flag{
+		Name:     "bundlepool.maxbundles",
+		Usage:    "Maximum number of bundles kept by the bundle pool",
+		Value:    ethconfig.Defaults.BundlePool.MaxBundles,
+		Category: flags.TxPoolCategory,
+	}
+	BundlePoolAccountSlotsFlag = &cli.Uint64Flag{
+		Name:     "bundlepool.accountslots",
+		Usage:    "Maximum number of bundles kept per sender of their first transaction",
+		Value:    ethconfig.Defaults.BundlePool.AccountSlots,
+		Category: flags.TxPoolCategory,
+	}
+	BundlePoolMaxBlocksAheadFlag = &cli.Uint64Flag{
+		Name:     "bundlepool.maxblocksahead",
+		Usage:    "Maximum number of blocks ahead of the chain head a bundle can target",
+		Value:    ethconfig.Defaults.BundlePool.MaxBlocksAhead,
+		Category: flags.TxPoolCategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -652,6 +677,16 @@ var (
 		Value:    ethconfig.Defaults.Miner.DelayLeftOver,
 		Category: flags.MinerCategory,
 	}
+	BuilderEnabledFlag = &cli.BoolFlag{
+		Name:     "builder",
+		Usage:    "Enable the builder mode, bidding blocks built from the received bundles to the configured validators",
+		Category: flags.MinerCategory,
+	}
+	BuilderAccountFlag = &cli.StringFlag{
+		Name:     "builder.account",
+		Usage:    "Account signing the bids of the builder mode",
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -1153,6 +1188,12 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.MinerCategory,
 	}
 
+	DoubleSignReporterFlag = &cli.StringFlag{
+		Name:     "monitor.doublesign.reporter",
+		Usage:    "Account submitting the evidences of the double signs to the slash contract (enables the double sign monitor, the account must be unlocked)",
+		Category: flags.MinerCategory,
+	}
+
 	VotingEnabledFlag = &cli.BoolFlag{
 		Name:     "vote",
 		Usage:    "Enable voting when mining",
@@ -1170,6 +1211,16 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Usage:    "Enable malicious vote monitor to check whether any validator violates the voting rules of fast finality",
 		Category: flags.FastFinalityCategory,
 	}
+	MaliciousVoteReporterFlag = &cli.StringFlag{
+		Name:     "monitor.maliciousvote.reporter",
+		Usage:    "Account submitting the evidences of the malicious votes to the slash contract (enables the malicious vote monitor, the account must be unlocked)",
+		Category: flags.FastFinalityCategory,
+	}
+	MaliciousVoteDryRunFlag = &cli.BoolFlag{
+		Name:     "monitor.maliciousvote.dryrun",
+		Usage:    "Only write the evidences of the malicious votes to files in the data directory instead of submitting them (enables the malicious vote monitor)",
+		Category: flags.FastFinalityCategory,
+	}
 
 	BLSPasswordFileFlag = &cli.StringFlag{
 		Name:     "blspassword",
@@ -1183,6 +1234,38 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.AccountCategory,
 	}
 
+	BLSRemoteSignerFlag = &cli.StringFlag{
+		Name:     "blsremotesigner",
+		Usage:    "URL of the remote signer speaking the Web3Signer eth2 API to sign the votes with, instead of the BLS wallet",
+		Category: flags.AccountCategory,
+	}
+	BLSRemoteSignerPubKeyFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.pubkey",
+		Usage:    "BLS public key of the remote signer to sign the votes with (default = the first key of the signer)",
+		Category: flags.AccountCategory,
+	}
+	BLSRemoteSignerCACertFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.tls.cacert",
+		Usage:    "PEM file of the CA certificates verifying the remote signer (default = the system ones)",
+		Category: flags.AccountCategory,
+	}
+	BLSRemoteSignerClientCertFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.tls.cert",
+		Usage:    "PEM file of the client certificate authenticating to the remote signer",
+		Category: flags.AccountCategory,
+	}
+	BLSRemoteSignerClientKeyFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.tls.key",
+		Usage:    "PEM file of the client private key authenticating to the remote signer",
+		Category: flags.AccountCategory,
+	}
+	BLSRemoteSignerTimeoutFlag = &cli.DurationFlag{
+		Name:     "blsremotesigner.timeout",
+		Usage:    "Timeout of the requests to the remote signer",
+		Value:    5 * time.Second,
+		Category: flags.AccountCategory,
+	}
+
 	VoteJournalDirFlag = &flags.DirectoryFlag{
 		Name:     "vote-journal-path",
 		Usage:    "Path for the voteJournal dir in fast finality feature (default = inside the datadir)",
@@ -1215,6 +1298,37 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    fakebeacon.DefaultPort,
 		Category: flags.APICategory,
 	}
+
+	// Quant settings
+	QuantSinkFlag = &cli.StringFlag{
+		Name:     "quant.sink",
+		Usage:    "Storage backend of the quant service (elasticsearch, file, sqlite, memory)",
+		Value:    quant.DefaultSinkConfig.Type,
+		Category: flags.QuantCategory,
+	}
+	QuantSinkElasticURLFlag = &cli.StringFlag{
+		Name:     "quant.sink.es.url",
+		Usage:    "Elasticsearch endpoint of the quant elasticsearch sink",
+		Category: flags.QuantCategory,
+	}
+	QuantSinkDirFlag = &cli.StringFlag{
+		Name:     "quant.sink.dir",
+		Usage:    "Directory of the quant file sink, relative paths are resolved in the data directory",
+		Value:    quant.DefaultSinkConfig.FileDir,
+		Category: flags.QuantCategory,
+	}
+	QuantSinkMaxSizeFlag = &cli.Uint64Flag{
+		Name:     "quant.sink.maxsize",
+		Usage:    "Size in bytes after which files of the quant file sink are rotated (0 = never)",
+		Value:    quant.DefaultSinkConfig.FileMaxSize,
+		Category: flags.QuantCategory,
+	}
+	QuantSinkSQLitePathFlag = &cli.StringFlag{
+		Name:     "quant.sink.sqlite.path",
+		Usage:    "Database file of the quant sqlite sink, relative paths are resolved in the data directory",
+		Value:    quant.DefaultSinkConfig.SQLitePath,
+		Category: flags.QuantCategory,
+	}
 )
 
 var (
@@ -1507,9 +1621,29 @@ func setMonitors(ctx *cli.Context, cfg *node.Config) {
 	if ctx.Bool(EnableDoubleSignMonitorFlag.Name) {
 		cfg.EnableDoubleSignMonitor = true
 	}
+	if ctx.IsSet(DoubleSignReporterFlag.Name) {
+		addr := ctx.String(DoubleSignReporterFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid double sign reporter address %q", addr)
+		}
+		cfg.DoubleSignReporter = common.HexToAddress(addr)
+		cfg.EnableDoubleSignMonitor = true
+	}
 	if ctx.Bool(EnableMaliciousVoteMonitorFlag.Name) {
 		cfg.EnableMaliciousVoteMonitor = true
 	}
+	if ctx.IsSet(MaliciousVoteReporterFlag.Name) {
+		addr := ctx.String(MaliciousVoteReporterFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid malicious vote reporter address %q", addr)
+		}
+		cfg.MaliciousVoteReporter = common.HexToAddress(addr)
+		cfg.EnableMaliciousVoteMonitor = true
+	}
+	if ctx.Bool(MaliciousVoteDryRunFlag.Name) {
+		cfg.MaliciousVoteDryRun = true
+		cfg.EnableMaliciousVoteMonitor = true
+	}
 }
 
 // MakeDatabaseHandles raises out the number of allowed file handles per process
@@ -1713,6 +1847,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BLSPasswordFileFlag.Name) {
 		cfg.BLSPasswordFile = ctx.String(BLSPasswordFileFlag.Name)
 	}
+	setBLSRemoteSigner(ctx, cfg)
 	if ctx.IsSet(DBEngineFlag.Name) {
 		dbEngine := ctx.String(DBEngineFlag.Name)
 		if dbEngine != "leveldb" && dbEngine != "pebble" {
@@ -1777,6 +1912,27 @@ func setBLSWalletDir(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+func setBLSRemoteSigner(ctx *cli.Context, cfg *node.Config) {
+	if ctx.IsSet(BLSRemoteSignerFlag.Name) {
+		cfg.BLSRemoteSigner = ctx.String(BLSRemoteSignerFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerPubKeyFlag.Name) {
+		cfg.BLSRemoteSignerPubKey = ctx.String(BLSRemoteSignerPubKeyFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerCACertFlag.Name) {
+		cfg.BLSRemoteSignerCACert = ctx.String(BLSRemoteSignerCACertFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerClientCertFlag.Name) {
+		cfg.BLSRemoteSignerClientCert = ctx.String(BLSRemoteSignerClientCertFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerClientKeyFlag.Name) {
+		cfg.BLSRemoteSignerClientKey = ctx.String(BLSRemoteSignerClientKeyFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerTimeoutFlag.Name) {
+		cfg.BLSRemoteSignerTimeout = ctx.Duration(BLSRemoteSignerTimeoutFlag.Name)
+	}
+}
+
 func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.IsSet(GpoBlocksFlag.Name) {
 		cfg.Blocks = ctx.Int(GpoBlocksFlag.Name)
@@ -1853,6 +2009,21 @@ func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
 	}
 }
 
+func setBundlePool(ctx *cli.Context, cfg *bundlepool.Config) {
+	if ctx.IsSet(BundlePoolEnabledFlag.Name) {
+		cfg.Enabled = ctx.Bool(BundlePoolEnabledFlag.Name)
+	}
+	if ctx.IsSet(BundlePoolMaxBundlesFlag.Name) {
+		cfg.MaxBundles = ctx.Uint64(BundlePoolMaxBundlesFlag.Name)
+	}
+	if ctx.IsSet(BundlePoolAccountSlotsFlag.Name) {
+		cfg.AccountSlots = ctx.Uint64(BundlePoolAccountSlotsFlag.Name)
+	}
+	if ctx.IsSet(BundlePoolMaxBlocksAheadFlag.Name) {
+		cfg.MaxBlocksAhead = ctx.Uint64(BundlePoolMaxBlocksAheadFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *minerconfig.Config) {
 	if ctx.IsSet(MinerExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.String(MinerExtraDataFlag.Name))
@@ -1879,6 +2050,16 @@ func setMiner(ctx *cli.Context, cfg *minerconfig.Config) {
 	if ctx.Bool(DisableVoteAttestationFlag.Name) {
 		cfg.DisableVoteAttestation = true
 	}
+	if ctx.Bool(BuilderEnabledFlag.Name) {
+		cfg.Bidder.Enabled = true
+	}
+	if ctx.IsSet(BuilderAccountFlag.Name) {
+		account := ctx.String(BuilderAccountFlag.Name)
+		if !common.IsHexAddress(account) {
+			Fatalf("Invalid builder account %q", account)
+		}
+		cfg.Bidder.Account = common.HexToAddress(account)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1961,6 +2142,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
 	setBlobPool(ctx, &cfg.BlobPool)
+	setBundlePool(ctx, &cfg.BundlePool)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -2314,12 +2496,59 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 	return backend.APIBackend, backend
 }
 
-// RegisterQuantService
-// The second return value is the full node instance.
-func RegisterQuantService(apibackend ethapi.Backend, eth *eth.Ethereum) {
-	quant := quant.NewQuant(apibackend, eth)
-	go quant.Loop()
-	return
+// RegisterQuantService starts the quant service watching the pools, routers
+// and senders listed in cfg, and adds its RPC API to the stack. The reload
+// function is used by quant_reload to re-read the config at runtime.
+func RegisterQuantService(stack *node.Node, apibackend ethapi.Backend, eth *eth.Ethereum, cfg *quant.Config, reload func() (quant.Config, error)) {
+	if !cfg.Enabled {
+		return
+	}
+	resolve := func(config quant.Config) quant.Config {
+		switch config.Sink.Type {
+		case quant.SinkFile:
+			config.Sink.FileDir = stack.ResolvePath(config.Sink.FileDir)
+		case quant.SinkSQLite:
+			config.Sink.SQLitePath = stack.ResolvePath(config.Sink.SQLitePath)
+		}
+		return config
+	}
+	if reload != nil {
+		// Resolve the reloaded config as the startup one, so that they compare equal
+		// when the settings read at startup are not changed.
+		readConfig := reload
+		reload = func() (quant.Config, error) {
+			config, err := readConfig()
+			if err != nil {
+				return quant.Config{}, err
+			}
+			return resolve(config), nil
+		}
+	}
+	q := quant.NewQuant(apibackend, eth, resolve(*cfg), reload)
+	if q == nil {
+		Fatalf("Failed to register the quant service")
+	}
+	stack.RegisterAPIs(q.APIs())
+	stack.RegisterLifecycle(q)
+}
+
+// SetQuantConfig applies quant-related command line flags to the config.
+func SetQuantConfig(ctx *cli.Context, cfg *quant.Config) {
+	if ctx.IsSet(QuantSinkFlag.Name) {
+		cfg.Sink.Type = ctx.String(QuantSinkFlag.Name)
+	}
+	if ctx.IsSet(QuantSinkElasticURLFlag.Name) {
+		cfg.Sink.ElasticURL = ctx.String(QuantSinkElasticURLFlag.Name)
+	}
+	if ctx.IsSet(QuantSinkDirFlag.Name) {
+		cfg.Sink.FileDir = ctx.String(QuantSinkDirFlag.Name)
+	}
+	if ctx.IsSet(QuantSinkMaxSizeFlag.Name) {
+		cfg.Sink.FileMaxSize = ctx.Uint64(QuantSinkMaxSizeFlag.Name)
+	}
+	if ctx.IsSet(QuantSinkSQLitePathFlag.Name) {
+		cfg.Sink.SQLitePath = ctx.String(QuantSinkSQLitePathFlag.Name)
+	}
 }
 
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to the node.
@@ -17,12 +17,22 @@
 package parlia
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// maxSlashEvidenceRange is the maximum block range of GetSlashEvidence.
+const maxSlashEvidenceRange = 100000
+
+var errInvalidOffender = errors.New("offender must be a validator address or a BLS public key")
+
 // API is a user facing RPC API to allow query snapshot and validators
 type API struct {
 	chain  consensus.ChainHeaderReader
@@ -114,6 +124,54 @@ func (api *API) GetFinalizedNumber(number *rpc.BlockNumber) (uint64, error) {
 	return snap.Attestation.SourceNumber, nil
 }
 
+// GetSlashEvidence retrieves the evidences of the double signs and malicious
+// votes found by the monitors in the block range, both ends included.
+func (api *API) GetSlashEvidence(fromBlock, toBlock rpc.BlockNumber) ([]*types.SlashEvidence, error) {
+	from, to := api.getHeader(&fromBlock), api.getHeader(&toBlock)
+	if from == nil || to == nil {
+		return nil, errUnknownBlock
+	}
+	if from.Number.Cmp(to.Number) > 0 {
+		return nil, fmt.Errorf("invalid block range %d-%d", from.Number, to.Number)
+	}
+	if to.Number.Uint64()-from.Number.Uint64() >= maxSlashEvidenceRange {
+		return nil, fmt.Errorf("block range exceeds %d blocks", maxSlashEvidenceRange)
+	}
+	evidences := rawdb.ReadSlashEvidences(api.parlia.db, from.Number.Uint64(), to.Number.Uint64())
+	if evidences == nil {
+		evidences = []*types.SlashEvidence{}
+	}
+	return evidences, nil
+}
+
+// GetEvidenceByValidator retrieves the evidences of the offences of the
+// validator with the given address or BLS public key. The malicious votes of
+// a validator given by address are looked up with its vote address in the
+// latest snapshot.
+func (api *API) GetEvidenceByValidator(offender hexutil.Bytes) ([]*types.SlashEvidence, error) {
+	var evidences []*types.SlashEvidence
+	switch len(offender) {
+	case common.AddressLength:
+		evidences = rawdb.ReadSlashEvidencesByOffender(api.parlia.db, offender)
+		header := api.chain.CurrentHeader()
+		snap, err := api.parlia.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if info, ok := snap.Validators[common.BytesToAddress(offender)]; ok && info != nil && info.VoteAddress != (types.BLSPublicKey{}) {
+			evidences = append(evidences, rawdb.ReadSlashEvidencesByOffender(api.parlia.db, info.VoteAddress[:])...)
+		}
+	case types.BLSPublicKeyLength:
+		evidences = rawdb.ReadSlashEvidencesByOffender(api.parlia.db, offender)
+	default:
+		return nil, errInvalidOffender
+	}
+	if evidences == nil {
+		evidences = []*types.SlashEvidence{}
+	}
+	return evidences, nil
+}
+
 func (api *API) getHeader(number *rpc.BlockNumber) (header *types.Header) {
 	currentHeader := api.chain.CurrentHeader()
 
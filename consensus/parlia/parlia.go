@@ -1848,6 +1848,60 @@ func (p *Parlia) distributeIncoming(val common.Address, state vm.StateDB, header
 	return p.distributeToValidator(balance.ToBig(), val, state, header, chain, txs, receipts, receivedTxs, usedGas, mining, vmConfig)
 }
 
+// PackDoubleSignEvidence packs the call to SlashIndicator.submitDoubleSignEvidence
+// reporting the two headers signed by the same validator at the same height.
+func (p *Parlia) PackDoubleSignEvidence(h1, h2 *types.Header) ([]byte, error) {
+	rlp1, err := rlp.EncodeToBytes(h1)
+	if err != nil {
+		return nil, err
+	}
+	rlp2, err := rlp.EncodeToBytes(h2)
+	if err != nil {
+		return nil, err
+	}
+	return p.slashABI.Pack("submitDoubleSignEvidence", rlp1, rlp2)
+}
+
+// finalityVoteData is the SlashIndicator.VoteData struct.
+type finalityVoteData struct {
+	SrcNum  *big.Int
+	SrcHash [32]byte
+	TarNum  *big.Int
+	TarHash [32]byte
+	Sig     []byte
+}
+
+// finalityEvidence is the SlashIndicator.FinalityEvidence struct.
+type finalityEvidence struct {
+	VoteA    finalityVoteData
+	VoteB    finalityVoteData
+	VoteAddr []byte
+}
+
+func newFinalityVoteData(vote *types.VoteEnvelope) finalityVoteData {
+	return finalityVoteData{
+		SrcNum:  new(big.Int).SetUint64(vote.Data.SourceNumber),
+		SrcHash: vote.Data.SourceHash,
+		TarNum:  new(big.Int).SetUint64(vote.Data.TargetNumber),
+		TarHash: vote.Data.TargetHash,
+		Sig:     vote.Signature[:],
+	}
+}
+
+// PackFinalityViolationEvidence packs the call to SlashIndicator.submitFinalityViolationEvidence
+// reporting the two conflicting votes of the same validator.
+func (p *Parlia) PackFinalityViolationEvidence(vote1, vote2 *types.VoteEnvelope) ([]byte, error) {
+	if vote1.VoteAddress != vote2.VoteAddress || vote1.Data == nil || vote2.Data == nil {
+		return nil, errors.New("invalid malicious votes")
+	}
+	evidence := finalityEvidence{
+		VoteA:    newFinalityVoteData(vote1),
+		VoteB:    newFinalityVoteData(vote2),
+		VoteAddr: vote1.VoteAddress[:],
+	}
+	return p.slashABI.Pack("submitFinalityViolationEvidence", evidence)
+}
+
 // slash spoiled validators
 func (p *Parlia) slash(spoiledVal common.Address, state vm.StateDB, header *types.Header, chain core.ChainContext,
 	txs *[]*types.Transaction, receipts *[]*types.Receipt, receivedTxs *[]*types.Transaction, usedGas *uint64, mining bool, vmConfig vm.Config) error {
@@ -0,0 +1,87 @@
+package parlia
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func newTestSlashParlia(t *testing.T) *Parlia {
+	sABI, err := abi.JSON(strings.NewReader(slashABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Parlia{slashABI: sABI}
+}
+
+func TestPackDoubleSignEvidence(t *testing.T) {
+	p := newTestSlashParlia(t)
+	h1 := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2), Extra: []byte{1}}
+	h2 := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2), Extra: []byte{2}}
+
+	data, err := p.PackDoubleSignEvidence(h1, h2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := p.slashABI.Methods["submitDoubleSignEvidence"]
+	if !bytes.Equal(data[:4], method.ID) {
+		t.Fatalf("wrong method id %x", data[:4])
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, h := range []*types.Header{h1, h2} {
+		enc, _ := rlp.EncodeToBytes(h)
+		if !bytes.Equal(args[i].([]byte), enc) {
+			t.Errorf("header %d mismatch", i+1)
+		}
+	}
+}
+
+func TestPackFinalityViolationEvidence(t *testing.T) {
+	p := newTestSlashParlia(t)
+	newVote := func(targetHash common.Hash, sig byte) *types.VoteEnvelope {
+		return &types.VoteEnvelope{
+			VoteAddress: types.BLSPublicKey{1},
+			Signature:   types.BLSSignature{sig},
+			Data:        &types.VoteData{SourceNumber: 8, SourceHash: common.HexToHash("0x08"), TargetNumber: 9, TargetHash: targetHash},
+		}
+	}
+	vote1, vote2 := newVote(common.HexToHash("0x01"), 1), newVote(common.HexToHash("0x02"), 2)
+
+	data, err := p.PackFinalityViolationEvidence(vote1, vote2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := p.slashABI.Methods["submitFinalityViolationEvidence"]
+	if !bytes.Equal(data[:4], method.ID) {
+		t.Fatalf("wrong method id %x", data[:4])
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evidence finalityEvidence
+	abi.ConvertType(args[0], &evidence)
+	want := finalityEvidence{
+		VoteA:    newFinalityVoteData(vote1),
+		VoteB:    newFinalityVoteData(vote2),
+		VoteAddr: vote1.VoteAddress[:],
+	}
+	if !reflect.DeepEqual(evidence, want) {
+		t.Fatalf("evidence mismatch: have %+v, want %+v", evidence, want)
+	}
+	other := newVote(common.HexToHash("0x02"), 2)
+	other.VoteAddress = types.BLSPublicKey{2}
+	if _, err := p.PackFinalityViolationEvidence(vote1, other); err == nil {
+		t.Fatal("votes of different validators packed")
+	}
+}
@@ -266,6 +266,7 @@ type BlockChain struct {
 	blockProcFeed            event.Feed
 	finalizedHeaderFeed      event.Feed
 	highestVerifiedBlockFeed event.Feed
+	doubleSignFeed           event.Feed
 	scope                    event.SubscriptionScope
 	genesisBlock             *types.Block
 
@@ -3040,7 +3041,9 @@ func (bc *BlockChain) startDoubleSignMonitor() {
 		select {
 		case event := <-eventChan:
 			if bc.doubleSignMonitor != nil {
-				bc.doubleSignMonitor.Verify(event.Header)
+				if h2 := bc.doubleSignMonitor.Verify(event.Header); h2 != nil {
+					bc.doubleSignFeed.Send(DoubleSignEvent{Header1: event.Header, Header2: h2})
+				}
 			}
 		case <-bc.quit:
 			return
@@ -3180,7 +3183,7 @@ func EnableBlockValidator(chainConfig *params.ChainConfig, mode VerifyMode, peer
 }
 
 func EnableDoubleSignChecker(bc *BlockChain) (*BlockChain, error) {
-	bc.doubleSignMonitor = monitor.NewDoubleSignMonitor()
+	bc.doubleSignMonitor = monitor.NewDoubleSignMonitor(bc.db)
 	return bc, nil
 }
 
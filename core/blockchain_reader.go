@@ -530,6 +530,11 @@ func (bc *BlockChain) SubscribeFinalizedHeaderEvent(ch chan<- FinalizedHeaderEve
 	return bc.scope.Track(bc.finalizedHeaderFeed.Subscribe(ch))
 }
 
+// SubscribeDoubleSignEvent registers a subscription of DoubleSignEvent.
+func (bc *BlockChain) SubscribeDoubleSignEvent(ch chan<- DoubleSignEvent) event.Subscription {
+	return bc.scope.Track(bc.doubleSignFeed.Subscribe(ch))
+}
+
 // AncientTail retrieves the tail the ancients blocks
 func (bc *BlockChain) AncientTail() (uint64, error) {
 	tail, err := bc.db.BlockStore().Tail()
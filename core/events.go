@@ -35,6 +35,10 @@ type RemovedLogsEvent struct{ Logs []*types.Log }
 // NewVoteEvent is posted when a batch of votes enters the vote pool.
 type NewVoteEvent struct{ Vote *types.VoteEnvelope }
 
+// DoubleSignEvent is posted when the double sign monitor finds two headers of
+// the same height signed by the same validator.
+type DoubleSignEvent struct{ Header1, Header2 *types.Header }
+
 // FinalizedHeaderEvent is posted when a finalized header is reached.
 type FinalizedHeaderEvent struct{ Header *types.Header }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -14,14 +15,18 @@ const (
 	MaxCacheHeader = 100
 )
 
-func NewDoubleSignMonitor() *DoubleSignMonitor {
+// NewDoubleSignMonitor creates a double sign monitor storing the evidences in
+// db, or only logging them if db is nil.
+func NewDoubleSignMonitor(db ethdb.KeyValueStore) *DoubleSignMonitor {
 	return &DoubleSignMonitor{
+		db:            db,
 		headerNumbers: prque.New[int64, *types.Header](nil),
 		headers:       make(map[uint64]*types.Header, MaxCacheHeader),
 	}
 }
 
 type DoubleSignMonitor struct {
+	db            ethdb.KeyValueStore
 	headerNumbers *prque.Prque[int64, *types.Header]
 	headers       map[uint64]*types.Header
 }
@@ -76,11 +81,13 @@ func (m *DoubleSignMonitor) checkHeader(h *types.Header) (bool, *types.Header, e
 	return false, nil, nil
 }
 
-func (m *DoubleSignMonitor) Verify(h *types.Header) {
+// Verify checks the header against the recent headers, and returns the header
+// of the same height signed by the same validator if there is a double sign.
+func (m *DoubleSignMonitor) Verify(h *types.Header) *types.Header {
 	isDoubleSign, h2, err := m.checkHeader(h)
 	if err != nil {
 		log.Error("check double sign header error", "err", err)
-		return
+		return nil
 	}
 	if isDoubleSign {
 		// found a double sign header
@@ -98,5 +105,8 @@ func (m *DoubleSignMonitor) Verify(h *types.Header) {
 		log.Warn("double sign header content",
 			"header1", hexutil.Encode(h1Bytes),
 			"header2", hexutil.Encode(h2Bytes))
+		recordEvidence(m.db, newDoubleSignEvidence(h, h2))
+		return h2
 	}
+	return nil
 }
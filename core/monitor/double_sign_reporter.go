@@ -0,0 +1,95 @@
+package monitor
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// DoubleSignEvidenceGas is the gas limit of the evidence submission tx.
+const DoubleSignEvidenceGas = 1_000_000
+
+var (
+	doubleSignReportedCounter = metrics.NewRegisteredCounter("monitor/doubleSign/reported", nil)
+	doubleSignFailedCounter   = metrics.NewRegisteredCounter("monitor/doubleSign/failed", nil)
+
+	errNotDoubleSign      = errors.New("headers are not a double sign")
+	ErrDoubleSignReported = errors.New("double sign already reported")
+)
+
+// EvidencePackFn packs the call to SlashIndicator.submitDoubleSignEvidence.
+type EvidencePackFn func(h1, h2 *types.Header) ([]byte, error)
+
+type doubleSignKey struct {
+	validator common.Address
+	number    uint64
+}
+
+// DoubleSignReporter submits the double signs found by the DoubleSignMonitor
+// to the slash contract. Each double sign, identified by the validator and the
+// block number, is submitted once. The evidences submitted before a restart
+// are known from the evidence store.
+type DoubleSignReporter struct {
+	submitter *SlashSubmitter
+	db        ethdb.KeyValueStore // Store of the evidences, updated with the submission status
+	pack      EvidencePackFn
+
+	mu       sync.Mutex
+	reported map[doubleSignKey]common.Hash
+}
+
+// NewDoubleSignReporter creates a reporter submitting the evidences with the
+// submitter.
+func NewDoubleSignReporter(submitter *SlashSubmitter, db ethdb.KeyValueStore, pack EvidencePackFn) *DoubleSignReporter {
+	return &DoubleSignReporter{
+		submitter: submitter,
+		db:        db,
+		pack:      pack,
+		reported:  make(map[doubleSignKey]common.Hash),
+	}
+}
+
+// Report submits the evidence of the double sign to the slash contract through
+// the txpool, and returns the hash of the submission tx.
+func (r *DoubleSignReporter) Report(h1, h2 *types.Header) (common.Hash, error) {
+	if h1.Number.Cmp(h2.Number) != 0 || h1.Coinbase != h2.Coinbase || h1.Hash() == h2.Hash() {
+		return common.Hash{}, errNotDoubleSign
+	}
+	key := doubleSignKey{h1.Coinbase, h1.Number.Uint64()}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if hash, ok := r.reported[key]; ok {
+		return hash, ErrDoubleSignReported
+	}
+	if r.db != nil {
+		stored := rawdb.ReadSlashEvidence(r.db, types.DoubleSignEvidence, key.number, key.validator.Bytes())
+		if stored != nil && stored.Status == types.EvidenceSubmitted && stored.TxHash != nil {
+			r.reported[key] = *stored.TxHash
+			return *stored.TxHash, ErrDoubleSignReported
+		}
+	}
+	data, err := r.pack(h1, h2)
+	var hash common.Hash
+	if err == nil {
+		hash, err = r.submitter.submit(data, DoubleSignEvidenceGas)
+	}
+	if err != nil {
+		doubleSignFailedCounter.Inc(1)
+		updateEvidence(r.db, newDoubleSignEvidence(h1, h2), types.EvidenceFailed, common.Hash{}, err)
+		return common.Hash{}, err
+	}
+	doubleSignReportedCounter.Inc(1)
+	r.reported[key] = hash
+	updateEvidence(r.db, newDoubleSignEvidence(h1, h2), types.EvidenceSubmitted, hash, nil)
+
+	log.Warn("Reported double sign", "validator", key.validator, "number", key.number, "tx", hash)
+	return hash, nil
+}
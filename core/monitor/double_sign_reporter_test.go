@@ -0,0 +1,156 @@
+package monitor
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTxPool struct {
+	txs   []*types.Transaction
+	err   error
+	stale bool // The nonce isn't updated, as if the txs weren't promoted yet
+}
+
+func (p *testTxPool) Nonce(addr common.Address) uint64 {
+	if p.stale {
+		return 0
+	}
+	return uint64(len(p.txs))
+}
+
+func (p *testTxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	if p.err != nil {
+		return []error{p.err}
+	}
+	p.txs = append(p.txs, txs...)
+	return []error{nil}
+}
+
+func newTestSlashSubmitter(pool *testTxPool) *SlashSubmitter {
+	key, _ := crypto.GenerateKey()
+	return &SlashSubmitter{
+		Account: crypto.PubkeyToAddress(key.PublicKey),
+		ChainID: big.NewInt(56),
+		SignTx: func(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+			return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+		},
+		TxPool:   pool,
+		GasPrice: func() *big.Int { return big.NewInt(1) },
+	}
+}
+
+func newTestDoubleSignReporter(db ethdb.KeyValueStore, pool *testTxPool) *DoubleSignReporter {
+	pack := func(h1, h2 *types.Header) ([]byte, error) {
+		return append(h1.Hash().Bytes(), h2.Hash().Bytes()...), nil
+	}
+	return NewDoubleSignReporter(newTestSlashSubmitter(pool), db, pack)
+}
+
+func TestDoubleSignReporter(t *testing.T) {
+	var (
+		validator = common.HexToAddress("0x01")
+		h1        = &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2), Coinbase: validator, Extra: []byte{1}}
+		h2        = &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2), Coinbase: validator, Extra: []byte{2}}
+		pool      = new(testTxPool)
+		db        = rawdb.NewMemoryDatabase()
+	)
+	// The monitor finds the double sign
+	m := NewDoubleSignMonitor(db)
+	assert.Nil(t, m.Verify(h1))
+	assert.Equal(t, h1, m.Verify(h2))
+	evidence := rawdb.ReadSlashEvidence(db, types.DoubleSignEvidence, 10, validator.Bytes())
+	assert.NotNil(t, evidence)
+	assert.Equal(t, types.EvidenceDetected, evidence.Status)
+	assert.Equal(t, h2.Hash(), evidence.Header1.Hash())
+	assert.Equal(t, h1.Hash(), evidence.Header2.Hash())
+
+	r := newTestDoubleSignReporter(db, pool)
+
+	_, err := r.Report(h1, &types.Header{Number: big.NewInt(10), Coinbase: common.HexToAddress("0x02")})
+	assert.Equal(t, errNotDoubleSign, err)
+	_, err = r.Report(h1, h1)
+	assert.Equal(t, errNotDoubleSign, err)
+
+	// A failed submission is retried on the next report
+	pool.err = errors.New("txpool full")
+	_, err = r.Report(h2, h1)
+	assert.Equal(t, pool.err, err)
+	evidence = rawdb.ReadSlashEvidence(db, types.DoubleSignEvidence, 10, validator.Bytes())
+	assert.Equal(t, types.EvidenceFailed, evidence.Status)
+	assert.Equal(t, "txpool full", evidence.Error)
+	pool.err = nil
+
+	hash, err := r.Report(h2, h1)
+	assert.NoError(t, err)
+	assert.Len(t, pool.txs, 1)
+	tx := pool.txs[0]
+	assert.Equal(t, hash, tx.Hash())
+	assert.Equal(t, common.HexToAddress(systemcontracts.SlashContract), *tx.To())
+	assert.Equal(t, append(h2.Hash().Bytes(), h1.Hash().Bytes()...), tx.Data())
+	assert.Equal(t, uint64(DoubleSignEvidenceGas), tx.Gas())
+	evidence = rawdb.ReadSlashEvidence(db, types.DoubleSignEvidence, 10, validator.Bytes())
+	assert.Equal(t, types.EvidenceSubmitted, evidence.Status)
+	assert.Equal(t, hash, *evidence.TxHash)
+	assert.Empty(t, evidence.Error)
+
+	// The double sign is reported once
+	dup, err := r.Report(h1, h2)
+	assert.Equal(t, ErrDoubleSignReported, err)
+	assert.Equal(t, hash, dup)
+
+	// Even after a restart
+	r = newTestDoubleSignReporter(db, pool)
+	dup, err = r.Report(h1, h2)
+	assert.Equal(t, ErrDoubleSignReported, err)
+	assert.Equal(t, hash, dup)
+
+	h3 := &types.Header{Number: big.NewInt(11), Difficulty: big.NewInt(2), Coinbase: validator}
+	h4 := &types.Header{Number: big.NewInt(11), Difficulty: big.NewInt(2), Coinbase: validator, Extra: []byte{1}}
+	_, err = r.Report(h3, h4)
+	assert.NoError(t, err)
+	assert.Len(t, pool.txs, 2)
+	assert.Equal(t, uint64(1), pool.txs[1].Nonce())
+}
+
+func TestSharedSlashSubmitter(t *testing.T) {
+	var (
+		pool         = &testTxPool{stale: true}
+		submitter    = newTestSlashSubmitter(pool)
+		vote1, vote2 = newTestMaliciousVotes()
+		validator    = common.HexToAddress("0x01")
+		h1           = &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2), Coinbase: validator, Extra: []byte{1}}
+		h2           = &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2), Coinbase: validator, Extra: []byte{2}}
+	)
+	doubleSign := NewDoubleSignReporter(submitter, nil, func(h1, h2 *types.Header) ([]byte, error) { return nil, nil })
+	maliciousVote, err := NewMaliciousVoteReporter(submitter, nil, "", testVoteEvidencePack)
+	assert.NoError(t, err)
+
+	// The reporters sharing the account send their txs concurrently
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := doubleSign.Report(h1, h2)
+		assert.NoError(t, err)
+	}()
+	go func() {
+		defer wg.Done()
+		_, err := maliciousVote.Report(vote1, vote2)
+		assert.NoError(t, err)
+	}()
+	wg.Wait()
+
+	assert.Len(t, pool.txs, 2)
+	assert.NotEqual(t, pool.txs[0].Nonce(), pool.txs[1].Nonce())
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func newDoubleSignEvidence(h1, h2 *types.Header) *types.SlashEvidence {
+	validator := h1.Coinbase
+	return &types.SlashEvidence{
+		Type:      types.DoubleSignEvidence,
+		Number:    h1.Number.Uint64(),
+		Validator: &validator,
+		Header1:   h1,
+		Header2:   h2,
+		FirstSeen: uint64(time.Now().Unix()),
+		Status:    types.EvidenceDetected,
+	}
+}
+
+func newMaliciousVoteEvidence(vote1, vote2 *types.VoteEnvelope) *types.SlashEvidence {
+	return &types.SlashEvidence{
+		Type:        types.MaliciousVoteEvidence,
+		Number:      vote2.Data.TargetNumber,
+		VoteAddress: common.CopyBytes(vote2.VoteAddress[:]),
+		Vote1:       types.NewSlashVote(vote1),
+		Vote2:       types.NewSlashVote(vote2),
+		FirstSeen:   uint64(time.Now().Unix()),
+		Status:      types.EvidenceDetected,
+	}
+}
+
+// recordEvidence stores the evidence unless the offence is already stored, so
+// that the time it was first seen is kept.
+func recordEvidence(db ethdb.KeyValueStore, evidence *types.SlashEvidence) {
+	if db == nil {
+		return
+	}
+	if rawdb.ReadSlashEvidence(db, evidence.Type, evidence.Number, evidence.Offender()) != nil {
+		return
+	}
+	rawdb.WriteSlashEvidence(db, evidence)
+}
+
+// updateEvidence sets the submission status of the evidence, storing it first
+// if needed.
+func updateEvidence(db ethdb.KeyValueStore, evidence *types.SlashEvidence, status types.SlashEvidenceStatus, txHash common.Hash, err error) {
+	if db == nil {
+		return
+	}
+	if stored := rawdb.ReadSlashEvidence(db, evidence.Type, evidence.Number, evidence.Offender()); stored != nil {
+		evidence = stored
+	}
+	evidence.Status, evidence.TxHash, evidence.Error = status, nil, ""
+	if txHash != (common.Hash{}) {
+		evidence.TxHash = &txHash
+	}
+	if err != nil {
+		evidence.Error = err.Error()
+	}
+	rawdb.WriteSlashEvidence(db, evidence)
+}
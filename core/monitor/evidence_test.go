@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaliciousVoteEvidence(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	m := NewMaliciousVoteMonitor(db)
+	pendingBlockNumber := uint64(1000)
+	voteAddress := types.BLSPublicKey{1}
+	vote1 := &types.VoteEnvelope{
+		VoteAddress: voteAddress,
+		Signature:   types.BLSSignature{1},
+		Data: &types.VoteData{
+			SourceNumber: pendingBlockNumber - 2,
+			TargetNumber: pendingBlockNumber - 1,
+			TargetHash:   common.HexToHash("0x01"),
+		},
+	}
+	vote2 := &types.VoteEnvelope{
+		VoteAddress: voteAddress,
+		Signature:   types.BLSSignature{2},
+		Data: &types.VoteData{
+			SourceNumber: pendingBlockNumber - 2,
+			TargetNumber: pendingBlockNumber - 1,
+			TargetHash:   common.HexToHash("0x02"),
+		},
+	}
+	assert.False(t, m.ConflictDetect(vote1, pendingBlockNumber))
+	assert.Empty(t, rawdb.ReadSlashEvidencesByOffender(db, voteAddress[:]))
+	assert.True(t, m.ConflictDetect(vote2, pendingBlockNumber))
+
+	evidences := rawdb.ReadSlashEvidencesByOffender(db, voteAddress[:])
+	assert.Len(t, evidences, 1)
+	evidence := evidences[0]
+	assert.Equal(t, types.MaliciousVoteEvidence, evidence.Type)
+	assert.Equal(t, pendingBlockNumber-1, evidence.Number)
+	assert.Equal(t, types.NewSlashVote(vote1), evidence.Vote1)
+	assert.Equal(t, types.NewSlashVote(vote2), evidence.Vote2)
+	assert.Equal(t, types.EvidenceDetected, evidence.Status)
+
+	// The offence is recorded once, keeping the time it was first seen
+	assert.NotZero(t, evidence.FirstSeen)
+	evidence.FirstSeen = 1
+	rawdb.WriteSlashEvidence(db, evidence)
+	assert.True(t, m.ConflictDetect(vote2, pendingBlockNumber))
+	evidences = rawdb.ReadSlashEvidences(db, 0, pendingBlockNumber)
+	assert.Len(t, evidences, 1)
+	assert.Equal(t, uint64(1), evidences[0].FirstSeen)
+}
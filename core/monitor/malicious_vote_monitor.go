@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	lru "github.com/hashicorp/golang-lru"
@@ -25,22 +26,30 @@ var (
 // 1. monitor whether there are bugs in the voting mechanism, so add metrics to observe it.
 // 2. do malicious vote slashing. TODO
 type MaliciousVoteMonitor struct {
+	db       ethdb.KeyValueStore // Store of the evidences, nil to only log them
 	curVotes map[types.BLSPublicKey]*lru.Cache
 }
 
-func NewMaliciousVoteMonitor() *MaliciousVoteMonitor {
+func NewMaliciousVoteMonitor(db ethdb.KeyValueStore) *MaliciousVoteMonitor {
 	return &MaliciousVoteMonitor{
+		db:       db,
 		curVotes: make(map[types.BLSPublicKey]*lru.Cache, 21), // mainnet config
 	}
 }
 
 func (m *MaliciousVoteMonitor) ConflictDetect(newVote *types.VoteEnvelope, pendingBlockNumber uint64) bool {
+	return m.ConflictingVote(newVote, pendingBlockNumber) != nil
+}
+
+// ConflictingVote returns the recent vote of the same validator the new vote
+// conflicts with, nil if there is none.
+func (m *MaliciousVoteMonitor) ConflictingVote(newVote *types.VoteEnvelope, pendingBlockNumber uint64) *types.VoteEnvelope {
 	// get votes for specified VoteAddress
 	if _, ok := m.curVotes[newVote.VoteAddress]; !ok {
 		voteDataBuffer, err := lru.New(maxSizeOfRecentEntry)
 		if err != nil {
 			log.Error("MaliciousVoteMonitor new lru failed", "err", err)
-			return false
+			return nil
 		}
 		m.curVotes[newVote.VoteAddress] = voteDataBuffer
 	}
@@ -50,7 +59,7 @@ func (m *MaliciousVoteMonitor) ConflictDetect(newVote *types.VoteEnvelope, pendi
 	//Basic check
 	// refer to https://github.com/bnb-chain/bsc-genesis-contract/blob/master/contracts/SlashIndicator.sol#LL207C4-L207C4
 	if !(targetNumber+maliciousVoteSlashScope > pendingBlockNumber) {
-		return false
+		return nil
 	}
 
 	// UnderRules check
@@ -86,12 +95,13 @@ func (m *MaliciousVoteMonitor) ConflictDetect(newVote *types.VoteEnvelope, pendi
 				} else {
 					log.Warn("MaliciousVote, construct evidence failed")
 				}
-				return true
+				recordEvidence(m.db, newMaliciousVoteEvidence(voteEnvelope.(*types.VoteEnvelope), newVote))
+				return voteEnvelope.(*types.VoteEnvelope)
 			}
 		}
 	}
 
 	// for simplicity, Just override even if the targetNumber has existed.
 	voteDataBuffer.Add(newVote.Data.TargetNumber, newVote)
-	return false
+	return nil
 }
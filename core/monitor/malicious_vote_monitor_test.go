@@ -12,7 +12,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 	//log.Root().SetHandler(log.StdoutHandler)
 	// case 1, different voteAddress
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(nil)
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -44,7 +44,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 2, target number not in maliciousVoteSlashScope
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(nil)
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -75,7 +75,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 3, violate rule1
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(nil)
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -106,7 +106,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 4,  violate rule2, vote with smaller range first
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(nil)
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -137,7 +137,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 5,  violate rule2, vote with larger range first
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(nil)
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -168,7 +168,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 6, normal case
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(nil)
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
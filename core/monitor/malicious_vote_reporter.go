@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// MaliciousVoteEvidenceGas is the gas limit of the evidence submission tx.
+const MaliciousVoteEvidenceGas = 1_000_000
+
+var (
+	maliciousVoteReportedCounter = metrics.NewRegisteredCounter("monitor/maliciousVote/reported", nil)
+	maliciousVoteFailedCounter   = metrics.NewRegisteredCounter("monitor/maliciousVote/failed", nil)
+
+	errNotMaliciousVote      = errors.New("votes are not of the same validator")
+	ErrMaliciousVoteReported = errors.New("malicious vote already reported")
+)
+
+// VoteEvidencePackFn packs the call to SlashIndicator.submitFinalityViolationEvidence.
+type VoteEvidencePackFn func(vote1, vote2 *types.VoteEnvelope) ([]byte, error)
+
+type maliciousVoteKey struct {
+	voteAddress types.BLSPublicKey
+	number      uint64
+}
+
+// MaliciousVoteReporter submits the malicious votes found by the
+// MaliciousVoteMonitor to the slash contract. The evidence of each malicious
+// vote, identified by the vote address and the target number of the last vote,
+// is written to a file in the format of the maliciousvote-submit tool, and is
+// submitted once unless in dry-run mode. The evidences submitted before a
+// restart are known from the evidence store.
+type MaliciousVoteReporter struct {
+	submitter *SlashSubmitter // nil in dry-run mode
+	db        ethdb.KeyValueStore
+	dir       string // Directory of the evidence files, none if empty
+	pack      VoteEvidencePackFn
+
+	mu       sync.Mutex
+	reported map[maliciousVoteKey]common.Hash
+}
+
+// NewMaliciousVoteReporter creates a reporter submitting the evidences with the
+// submitter, or only writing the evidence files if submitter is nil.
+func NewMaliciousVoteReporter(submitter *SlashSubmitter, db ethdb.KeyValueStore, dir string, pack VoteEvidencePackFn) (*MaliciousVoteReporter, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &MaliciousVoteReporter{
+		submitter: submitter,
+		db:        db,
+		dir:       dir,
+		pack:      pack,
+		reported:  make(map[maliciousVoteKey]common.Hash),
+	}, nil
+}
+
+// DryRun returns whether the evidences are only written to files.
+func (r *MaliciousVoteReporter) DryRun() bool {
+	return r.submitter == nil
+}
+
+// Report writes the evidence of the malicious votes, vote2 being the last one,
+// and submits it to the slash contract through the txpool. It returns the hash
+// of the submission tx, none in dry-run mode.
+func (r *MaliciousVoteReporter) Report(vote1, vote2 *types.VoteEnvelope) (common.Hash, error) {
+	if vote1.VoteAddress != vote2.VoteAddress || vote1.Data == nil || vote2.Data == nil {
+		return common.Hash{}, errNotMaliciousVote
+	}
+	key := maliciousVoteKey{vote2.VoteAddress, vote2.Data.TargetNumber}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if hash, ok := r.reported[key]; ok {
+		return hash, ErrMaliciousVoteReported
+	}
+	if r.db != nil {
+		stored := rawdb.ReadSlashEvidence(r.db, types.MaliciousVoteEvidence, key.number, key.voteAddress[:])
+		if stored != nil && stored.Status == types.EvidenceSubmitted && stored.TxHash != nil {
+			r.reported[key] = *stored.TxHash
+			return *stored.TxHash, ErrMaliciousVoteReported
+		}
+	}
+	if r.dir != "" {
+		if err := r.writeEvidence(key, vote1, vote2); err != nil {
+			log.Error("Failed to write malicious vote evidence", "voteAddress", hexutil.Encode(key.voteAddress[:]), "number", key.number, "err", err)
+		}
+	}
+	if r.submitter == nil {
+		r.reported[key] = common.Hash{}
+		log.Warn("Found malicious vote, dry run", "voteAddress", hexutil.Encode(key.voteAddress[:]), "number", key.number)
+		return common.Hash{}, nil
+	}
+	data, err := r.pack(vote1, vote2)
+	var hash common.Hash
+	if err == nil {
+		hash, err = r.submitter.submit(data, MaliciousVoteEvidenceGas)
+	}
+	if err != nil {
+		maliciousVoteFailedCounter.Inc(1)
+		updateEvidence(r.db, newMaliciousVoteEvidence(vote1, vote2), types.EvidenceFailed, common.Hash{}, err)
+		return common.Hash{}, err
+	}
+	maliciousVoteReportedCounter.Inc(1)
+	r.reported[key] = hash
+	updateEvidence(r.db, newMaliciousVoteEvidence(vote1, vote2), types.EvidenceSubmitted, hash, nil)
+
+	log.Warn("Reported malicious vote", "voteAddress", hexutil.Encode(key.voteAddress[:]), "number", key.number, "tx", hash)
+	return hash, nil
+}
+
+// writeEvidence writes the evidence in the JSON format of the --evidence flag
+// of the maliciousvote-submit tool.
+func (r *MaliciousVoteReporter) writeEvidence(key maliciousVoteKey, vote1, vote2 *types.VoteEnvelope) error {
+	data, err := json.MarshalIndent(types.NewSlashIndicatorFinalityEvidenceWrapper(vote1, vote2), "", "  ")
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(r.dir, fmt.Sprintf("%d-%x.json", key.number, key.voteAddress[:]))
+	return os.WriteFile(file, data, 0600)
+}
@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMaliciousVotes() (*types.VoteEnvelope, *types.VoteEnvelope) {
+	newVote := func(targetHash common.Hash, sig byte) *types.VoteEnvelope {
+		return &types.VoteEnvelope{
+			VoteAddress: types.BLSPublicKey{1},
+			Signature:   types.BLSSignature{sig},
+			Data: &types.VoteData{
+				SourceNumber: 998,
+				TargetNumber: 999,
+				TargetHash:   targetHash,
+			},
+		}
+	}
+	return newVote(common.HexToHash("0x01"), 1), newVote(common.HexToHash("0x02"), 2)
+}
+
+func testVoteEvidencePack(vote1, vote2 *types.VoteEnvelope) ([]byte, error) {
+	return append(vote1.Data.Hash().Bytes(), vote2.Data.Hash().Bytes()...), nil
+}
+
+func TestMaliciousVoteReporter(t *testing.T) {
+	var (
+		dir          = t.TempDir()
+		db           = rawdb.NewMemoryDatabase()
+		pool         = new(testTxPool)
+		vote1, vote2 = newTestMaliciousVotes()
+	)
+	m := NewMaliciousVoteMonitor(db)
+	assert.Nil(t, m.ConflictingVote(vote1, 1000))
+	assert.Equal(t, vote1, m.ConflictingVote(vote2, 1000))
+
+	r, err := NewMaliciousVoteReporter(newTestSlashSubmitter(pool), db, dir, testVoteEvidencePack)
+	assert.NoError(t, err)
+	assert.False(t, r.DryRun())
+
+	other := *vote2
+	other.VoteAddress = types.BLSPublicKey{2}
+	_, err = r.Report(vote1, &other)
+	assert.Equal(t, errNotMaliciousVote, err)
+
+	// A failed submission is retried on the next report
+	pool.err = errors.New("txpool full")
+	_, err = r.Report(vote1, vote2)
+	assert.Equal(t, pool.err, err)
+	evidence := rawdb.ReadSlashEvidence(db, types.MaliciousVoteEvidence, 999, vote1.VoteAddress[:])
+	assert.Equal(t, types.EvidenceFailed, evidence.Status)
+	pool.err = nil
+
+	hash, err := r.Report(vote1, vote2)
+	assert.NoError(t, err)
+	assert.Len(t, pool.txs, 1)
+	assert.Equal(t, hash, pool.txs[0].Hash())
+	assert.Equal(t, uint64(MaliciousVoteEvidenceGas), pool.txs[0].Gas())
+	evidence = rawdb.ReadSlashEvidence(db, types.MaliciousVoteEvidence, 999, vote1.VoteAddress[:])
+	assert.Equal(t, types.EvidenceSubmitted, evidence.Status)
+	assert.Equal(t, hash, *evidence.TxHash)
+
+	// The evidence file is in the format of maliciousvote-submit
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 1)
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+	var wrapper types.SlashIndicatorFinalityEvidenceWrapper
+	assert.NoError(t, json.Unmarshal(data, &wrapper))
+	assert.Equal(t, types.NewSlashIndicatorFinalityEvidenceWrapper(vote1, vote2), &wrapper)
+
+	// The malicious vote is reported once, even after a restart
+	dup, err := r.Report(vote1, vote2)
+	assert.Equal(t, ErrMaliciousVoteReported, err)
+	assert.Equal(t, hash, dup)
+	r, _ = NewMaliciousVoteReporter(newTestSlashSubmitter(pool), db, dir, testVoteEvidencePack)
+	dup, err = r.Report(vote1, vote2)
+	assert.Equal(t, ErrMaliciousVoteReported, err)
+	assert.Equal(t, hash, dup)
+	assert.Len(t, pool.txs, 1)
+}
+
+func TestMaliciousVoteReporterDryRun(t *testing.T) {
+	var (
+		dir          = t.TempDir()
+		db           = rawdb.NewMemoryDatabase()
+		vote1, vote2 = newTestMaliciousVotes()
+	)
+	r, err := NewMaliciousVoteReporter(nil, db, dir, testVoteEvidencePack)
+	assert.NoError(t, err)
+	assert.True(t, r.DryRun())
+
+	hash, err := r.Report(vote1, vote2)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}, hash)
+	_, err = r.Report(vote1, vote2)
+	assert.Equal(t, ErrMaliciousVoteReported, err)
+
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 1)
+	assert.Nil(t, rawdb.ReadSlashEvidence(db, types.MaliciousVoteEvidence, 999, vote1.VoteAddress[:]))
+}
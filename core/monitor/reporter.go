@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SignerTxFn signs a tx with the reporter account.
+type SignerTxFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error)
+
+// TxPool is the part of the txpool the evidences are submitted to.
+type TxPool interface {
+	Nonce(addr common.Address) uint64
+	Add(txs []*types.Transaction, local bool, sync bool) []error
+}
+
+// SlashSubmitter submits the slashing evidences to the slash contract through
+// the txpool, with txs signed by the reporter account. The reporters sending
+// from the same account must share the submitter, so that the nonces of their
+// txs don't collide.
+type SlashSubmitter struct {
+	Account  common.Address
+	ChainID  *big.Int
+	SignTx   SignerTxFn
+	TxPool   TxPool
+	GasPrice func() *big.Int
+
+	mu    sync.Mutex
+	nonce uint64 // Nonce of the next tx, unless the txpool is ahead
+}
+
+// submit sends the call to the slash contract and returns the tx hash.
+func (s *SlashSubmitter) submit(data []byte, gas uint64) (common.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The txpool may not have promoted the last tx yet
+	nonce := s.TxPool.Nonce(s.Account)
+	if nonce < s.nonce {
+		nonce = s.nonce
+	}
+	to := common.HexToAddress(systemcontracts.SlashContract)
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Gas:      gas,
+		GasPrice: s.GasPrice(),
+		Data:     data,
+	})
+	signed, err := s.SignTx(accounts.Account{Address: s.Account}, tx, s.ChainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.TxPool.Add([]*types.Transaction{signed}, true, false)[0]; err != nil {
+		return common.Hash{}, err
+	}
+	s.nonce = nonce + 1
+	return signed.Hash(), nil
+}
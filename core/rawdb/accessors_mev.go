@@ -0,0 +1,159 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadBidHistory retrieves the bids received for the given block number.
+func ReadBidHistory(db ethdb.KeyValueReader, number uint64) []*types.BidRecord {
+	data, _ := db.Get(bidHistoryKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var records []*types.BidRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		log.Error("Invalid bid history JSON", "number", number, "err", err)
+		return nil
+	}
+	return records
+}
+
+// WriteBidHistory stores the bids received for the given block number.
+func WriteBidHistory(db ethdb.KeyValueWriter, number uint64, records []*types.BidRecord) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		log.Crit("Failed to JSON encode bid history", "err", err)
+	}
+	if err := db.Put(bidHistoryKey(number), data); err != nil {
+		log.Crit("Failed to store bid history", "err", err)
+	}
+}
+
+// DeleteBidHistory removes the bids received for the given block number.
+func DeleteBidHistory(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(bidHistoryKey(number)); err != nil {
+		log.Crit("Failed to delete bid history", "err", err)
+	}
+}
+
+// DeleteBidHistoryBelow removes the bids received for the block numbers below
+// the given one.
+func DeleteBidHistoryBelow(db ethdb.Iteratee, w ethdb.KeyValueWriter, number uint64) {
+	deleteNumberedBelow(db, w, bidHistoryPrefix, number)
+}
+
+// deleteNumberedBelow removes the prefix + num (uint64 big endian) keys whose
+// number is below the given one.
+func deleteNumberedBelow(db ethdb.Iteratee, w ethdb.KeyValueWriter, prefix []byte, number uint64) {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(prefix):]) >= number {
+			break
+		}
+		if err := w.Delete(common.CopyBytes(key)); err != nil {
+			log.Crit("Failed to delete numbered record", "key", key, "err", err)
+		}
+	}
+}
+
+// ReadBuilderRecord retrieves the registry record of the given builder.
+func ReadBuilderRecord(db ethdb.KeyValueReader, address common.Address) *types.BuilderRecord {
+	data, _ := db.Get(builderRegistryKey(address))
+	if len(data) == 0 {
+		return nil
+	}
+	var record types.BuilderRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		log.Error("Invalid builder record JSON", "address", address, "err", err)
+		return nil
+	}
+	return &record
+}
+
+// ReadAllBuilderRecords retrieves the records of all the builders in the registry.
+func ReadAllBuilderRecords(db ethdb.Iteratee) []*types.BuilderRecord {
+	var records []*types.BuilderRecord
+	it := db.NewIterator(builderRegistryPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(builderRegistryPrefix)+common.AddressLength {
+			continue
+		}
+		var record types.BuilderRecord
+		if err := json.Unmarshal(it.Value(), &record); err != nil {
+			log.Error("Invalid builder record JSON", "key", it.Key(), "err", err)
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records
+}
+
+// WriteBuilderRecord stores the registry record of a builder.
+func WriteBuilderRecord(db ethdb.KeyValueWriter, record *types.BuilderRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Crit("Failed to JSON encode builder record", "err", err)
+	}
+	if err := db.Put(builderRegistryKey(record.Address), data); err != nil {
+		log.Crit("Failed to store builder record", "err", err)
+	}
+}
+
+// DeleteBuilderRecord removes the registry record of the given builder.
+func DeleteBuilderRecord(db ethdb.KeyValueWriter, address common.Address) {
+	if err := db.Delete(builderRegistryKey(address)); err != nil {
+		log.Crit("Failed to delete builder record", "err", err)
+	}
+}
+
+// ReadBlockSourceReport retrieves the source report of the given sealed block.
+func ReadBlockSourceReport(db ethdb.KeyValueReader, number uint64) *types.BlockSourceReport {
+	data, _ := db.Get(blockSourceKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var report types.BlockSourceReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		log.Error("Invalid block source report JSON", "number", number, "err", err)
+		return nil
+	}
+	return &report
+}
+
+// WriteBlockSourceReport stores the source report of a sealed block.
+func WriteBlockSourceReport(db ethdb.KeyValueWriter, report *types.BlockSourceReport) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Crit("Failed to JSON encode block source report", "err", err)
+	}
+	if err := db.Put(blockSourceKey(report.BlockNumber), data); err != nil {
+		log.Crit("Failed to store block source report", "err", err)
+	}
+}
+
+// DeleteBlockSourceReport removes the source report of the given block.
+func DeleteBlockSourceReport(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(blockSourceKey(number)); err != nil {
+		log.Crit("Failed to delete block source report", "err", err)
+	}
+}
+
+// DeleteBlockSourceReportsBelow removes the source reports of the blocks below
+// the given number.
+func DeleteBlockSourceReportsBelow(db ethdb.Iteratee, w ethdb.KeyValueWriter, number uint64) {
+	deleteNumberedBelow(db, w, blockSourcePrefix, number)
+}
@@ -0,0 +1,90 @@
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadSlashEvidence retrieves the evidence of the given offence.
+func ReadSlashEvidence(db ethdb.KeyValueReader, typ types.SlashEvidenceType, number uint64, offender []byte) *types.SlashEvidence {
+	data, _ := db.Get(slashEvidenceKey(number, typ, offender))
+	if len(data) == 0 {
+		return nil
+	}
+	var evidence types.SlashEvidence
+	if err := json.Unmarshal(data, &evidence); err != nil {
+		log.Error("Invalid slash evidence JSON", "type", typ, "number", number, "err", err)
+		return nil
+	}
+	return &evidence
+}
+
+// ReadSlashEvidences retrieves the evidences of the offences in the given
+// block range, both ends included.
+func ReadSlashEvidences(db ethdb.Iteratee, from, to uint64) []*types.SlashEvidence {
+	var evidences []*types.SlashEvidence
+	it := db.NewIterator(slashEvidencePrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) <= len(slashEvidencePrefix)+9 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(slashEvidencePrefix):]) > to {
+			break
+		}
+		var evidence types.SlashEvidence
+		if err := json.Unmarshal(it.Value(), &evidence); err != nil {
+			log.Error("Invalid slash evidence JSON", "key", key, "err", err)
+			continue
+		}
+		evidences = append(evidences, &evidence)
+	}
+	return evidences
+}
+
+// ReadSlashEvidencesByOffender retrieves the evidences of the offences of the
+// validator with the given address or BLS public key.
+func ReadSlashEvidencesByOffender(db ethdb.Iteratee, offender []byte) []*types.SlashEvidence {
+	var evidences []*types.SlashEvidence
+	it := db.NewIterator(slashEvidencePrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) <= len(slashEvidencePrefix)+9 || !bytes.Equal(key[len(slashEvidencePrefix)+9:], offender) {
+			continue
+		}
+		var evidence types.SlashEvidence
+		if err := json.Unmarshal(it.Value(), &evidence); err != nil {
+			log.Error("Invalid slash evidence JSON", "key", key, "err", err)
+			continue
+		}
+		evidences = append(evidences, &evidence)
+	}
+	return evidences
+}
+
+// WriteSlashEvidence stores the evidence of an offence.
+func WriteSlashEvidence(db ethdb.KeyValueWriter, evidence *types.SlashEvidence) {
+	data, err := json.Marshal(evidence)
+	if err != nil {
+		log.Crit("Failed to JSON encode slash evidence", "err", err)
+	}
+	if err := db.Put(slashEvidenceKey(evidence.Number, evidence.Type, evidence.Offender()), data); err != nil {
+		log.Crit("Failed to store slash evidence", "err", err)
+	}
+}
+
+// DeleteSlashEvidence removes the evidence of the given offence.
+func DeleteSlashEvidence(db ethdb.KeyValueWriter, typ types.SlashEvidenceType, number uint64, offender []byte) {
+	if err := db.Delete(slashEvidenceKey(number, typ, offender)); err != nil {
+		log.Crit("Failed to delete slash evidence", "err", err)
+	}
+}
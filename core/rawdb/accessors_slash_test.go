@@ -0,0 +1,59 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSlashEvidenceStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		validator   = common.HexToAddress("0x01")
+		voteAddress = types.BLSPublicKey{1}
+		evidences   = []*types.SlashEvidence{
+			{Type: types.DoubleSignEvidence, Number: 10, Validator: &validator, Header1: &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2)}},
+			{Type: types.MaliciousVoteEvidence, Number: 10, VoteAddress: voteAddress[:], Vote1: &types.SlashVote{TargetNumber: 10}},
+			{Type: types.DoubleSignEvidence, Number: 20, Validator: &validator, Status: types.EvidenceSubmitted},
+			{Type: types.MaliciousVoteEvidence, Number: 256, VoteAddress: voteAddress[:]},
+		}
+	)
+	if ReadSlashEvidence(db, types.DoubleSignEvidence, 10, validator.Bytes()) != nil {
+		t.Fatal("non-existent evidence returned")
+	}
+	for _, evidence := range evidences {
+		WriteSlashEvidence(db, evidence)
+	}
+	stored := ReadSlashEvidence(db, types.DoubleSignEvidence, 10, validator.Bytes())
+	if stored == nil || stored.Header1.Hash() != evidences[0].Header1.Hash() {
+		t.Fatalf("evidence mismatch: have %+v, want %+v", stored, evidences[0])
+	}
+	tests := []struct {
+		from, to uint64
+		want     int
+	}{
+		{0, 9, 0},
+		{10, 10, 2},
+		{10, 20, 3},
+		{11, 1000, 2},
+		{257, 1000, 0},
+	}
+	for i, tt := range tests {
+		if have := ReadSlashEvidences(db, tt.from, tt.to); len(have) != tt.want {
+			t.Errorf("test %d: have %d evidences, want %d", i, len(have), tt.want)
+		}
+	}
+	if have := ReadSlashEvidencesByOffender(db, validator.Bytes()); len(have) != 2 || have[1].Status != types.EvidenceSubmitted {
+		t.Fatalf("unexpected evidences of the validator: %+v", have)
+	}
+	if have := ReadSlashEvidencesByOffender(db, voteAddress[:]); len(have) != 2 || have[0].Type != types.MaliciousVoteEvidence {
+		t.Fatalf("unexpected evidences of the vote address: %+v", have)
+	}
+	DeleteSlashEvidence(db, types.DoubleSignEvidence, 20, validator.Bytes())
+	if have := ReadSlashEvidencesByOffender(db, validator.Bytes()); len(have) != 1 {
+		t.Fatalf("evidence not deleted: %+v", have)
+	}
+}
@@ -0,0 +1,58 @@
+package rawdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadVoteHistory retrieves the slashing protection history of the BLS key, nil
+// if there is none. An undecodable history is returned as an error rather than
+// as no history, the key must not vote until it is fixed.
+func ReadVoteHistory(db ethdb.KeyValueReader, pubKey types.BLSPublicKey) (*types.VoteHistory, error) {
+	data, _ := db.Get(voteHistoryKey(pubKey))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var history types.VoteHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("invalid vote history of %x: %w", pubKey, err)
+	}
+	return &history, nil
+}
+
+// ReadAllVoteHistories retrieves the slashing protection histories of all the
+// BLS keys.
+func ReadAllVoteHistories(db ethdb.Iteratee) map[types.BLSPublicKey]*types.VoteHistory {
+	histories := make(map[types.BLSPublicKey]*types.VoteHistory)
+	it := db.NewIterator(voteHistoryPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(voteHistoryPrefix)+types.BLSPublicKeyLength {
+			continue
+		}
+		var history types.VoteHistory
+		if err := json.Unmarshal(it.Value(), &history); err != nil {
+			log.Error("Invalid vote history JSON", "key", key, "err", err)
+			continue
+		}
+		histories[types.BLSPublicKey(key[len(voteHistoryPrefix):])] = &history
+	}
+	return histories
+}
+
+// WriteVoteHistory stores the slashing protection history of the BLS key.
+func WriteVoteHistory(db ethdb.KeyValueWriter, pubKey types.BLSPublicKey, history *types.VoteHistory) {
+	data, err := json.Marshal(history)
+	if err != nil {
+		log.Crit("Failed to JSON encode vote history", "err", err)
+	}
+	if err := db.Put(voteHistoryKey(pubKey), data); err != nil {
+		log.Crit("Failed to store vote history", "err", err)
+	}
+}
@@ -0,0 +1,42 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestVoteHistoryStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		key1    = types.BLSPublicKey{1}
+		key2    = types.BLSPublicKey{2}
+		history = &types.VoteHistory{SourceNumber: 10, TargetNumber: 12, SigningRoot: common.Hash{1}}
+	)
+	if history, err := ReadVoteHistory(db, key1); history != nil || err != nil {
+		t.Fatalf("non-existent vote history returned: %+v %v", history, err)
+	}
+	WriteVoteHistory(db, key1, history)
+	WriteVoteHistory(db, key2, &types.VoteHistory{TargetNumber: 1})
+
+	if stored, _ := ReadVoteHistory(db, key1); stored == nil || *stored != *history {
+		t.Fatalf("vote history mismatch: have %+v, want %+v", stored, history)
+	}
+	histories := ReadAllVoteHistories(db)
+	if len(histories) != 2 || *histories[key1] != *history || histories[key2].TargetNumber != 1 {
+		t.Fatalf("unexpected vote histories: %+v", histories)
+	}
+}
+
+func TestCorruptVoteHistory(t *testing.T) {
+	db := NewMemoryDatabase()
+	key := types.BLSPublicKey{1}
+	if err := db.Put(voteHistoryKey(key), []byte("{")); err != nil {
+		t.Fatal(err)
+	}
+	if history, err := ReadVoteHistory(db, key); history != nil || err == nil {
+		t.Fatalf("corrupt vote history read as %+v", history)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/log"
@@ -823,6 +824,11 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		bloomBits       stat
 		cliqueSnaps     stat
 		parliaSnaps     stat
+		bidHistory      stat
+		builderRegistry stat
+		blockSources    stat
+		slashEvidences  stat
+		voteHistories   stat
 
 		// Verkle statistics
 		verkleTries        stat
@@ -891,6 +897,16 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			cliqueSnaps.Add(size)
 		case bytes.HasPrefix(key, ParliaSnapshotPrefix) && len(key) == 7+common.HashLength:
 			parliaSnaps.Add(size)
+		case bytes.HasPrefix(key, bidHistoryPrefix) && len(key) == len(bidHistoryPrefix)+8:
+			bidHistory.Add(size)
+		case bytes.HasPrefix(key, builderRegistryPrefix) && len(key) == len(builderRegistryPrefix)+common.AddressLength:
+			builderRegistry.Add(size)
+		case bytes.HasPrefix(key, blockSourcePrefix) && len(key) == len(blockSourcePrefix)+8:
+			blockSources.Add(size)
+		case bytes.HasPrefix(key, slashEvidencePrefix) && len(key) > len(slashEvidencePrefix)+9:
+			slashEvidences.Add(size)
+		case bytes.HasPrefix(key, voteHistoryPrefix) && len(key) == len(voteHistoryPrefix)+types.BLSPublicKeyLength:
+			voteHistories.Add(size)
 		case bytes.HasPrefix(key, ChtTablePrefix) ||
 			bytes.HasPrefix(key, ChtIndexTablePrefix) ||
 			bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
@@ -1057,6 +1073,11 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
 		{"Key-Value store", "Parlia snapshots", parliaSnaps.Size(), parliaSnaps.Count()},
+		{"Key-Value store", "Bid history", bidHistory.Size(), bidHistory.Count()},
+		{"Key-Value store", "Builder registry", builderRegistry.Size(), builderRegistry.Count()},
+		{"Key-Value store", "Block source reports", blockSources.Size(), blockSources.Count()},
+		{"Key-Value store", "Slash evidences", slashEvidences.Size(), slashEvidences.Count()},
+		{"Key-Value store", "Vote histories", voteHistories.Size(), voteHistories.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
 		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
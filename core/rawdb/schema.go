@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -161,6 +162,13 @@ var (
 
 	BlockBlobSidecarsPrefix = []byte("blobs")
 
+	bidHistoryPrefix      = []byte("mev-bids-")    // bidHistoryPrefix + num (uint64 big endian) -> bids received for the block
+	builderRegistryPrefix = []byte("mev-builder-") // builderRegistryPrefix + address -> builder registry record
+	blockSourcePrefix     = []byte("mev-source-")  // blockSourcePrefix + num (uint64 big endian) -> block source report
+
+	slashEvidencePrefix = []byte("slash-evidence-") // slashEvidencePrefix + num (uint64 big endian) + type + offender -> slash evidence
+	voteHistoryPrefix   = []byte("vote-history-")   // voteHistoryPrefix + BLS public key -> vote slashing protection history
+
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 )
@@ -220,6 +228,32 @@ func blockBlobSidecarsKey(number uint64, hash common.Hash) []byte {
 	return append(append(BlockBlobSidecarsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// bidHistoryKey = bidHistoryPrefix + num (uint64 big endian)
+func bidHistoryKey(number uint64) []byte {
+	return append(bidHistoryPrefix, encodeBlockNumber(number)...)
+}
+
+// builderRegistryKey = builderRegistryPrefix + address
+func builderRegistryKey(address common.Address) []byte {
+	return append(builderRegistryPrefix, address.Bytes()...)
+}
+
+// blockSourceKey = blockSourcePrefix + num (uint64 big endian)
+func blockSourceKey(number uint64) []byte {
+	return append(blockSourcePrefix, encodeBlockNumber(number)...)
+}
+
+// slashEvidenceKey = slashEvidencePrefix + num (uint64 big endian) + type + offender
+func slashEvidenceKey(number uint64, typ types.SlashEvidenceType, offender []byte) []byte {
+	key := append(append(slashEvidencePrefix, encodeBlockNumber(number)...), byte(typ))
+	return append(key, offender...)
+}
+
+// voteHistoryKey = voteHistoryPrefix + BLS public key
+func voteHistoryKey(pubKey types.BLSPublicKey) []byte {
+	return append(voteHistoryPrefix, pubKey[:]...)
+}
+
 // diffLayerKey = diffLayerKeyPrefix + hash
 func diffLayerKey(hash common.Hash) []byte {
 	return append(diffLayerPrefix, hash.Bytes()...)
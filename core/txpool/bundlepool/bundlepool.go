@@ -0,0 +1,381 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundlepool implements the pool of transaction bundles, lists of
+// transactions included in a block atomically and in order.
+package bundlepool
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrBundleExpired is returned if a bundle can't be included in any block
+	// after the current head.
+	ErrBundleExpired = errors.New("bundle expired")
+
+	// ErrBundleTooFar is returned if a bundle targets a block too far ahead of
+	// the current head.
+	ErrBundleTooFar = errors.New("bundle targets a block too far in the future")
+
+	// ErrBundlePoolFull is returned if the pool reached its bundle limit.
+	ErrBundlePoolFull = errors.New("bundle pool is full")
+
+	// ErrBundleSenderLimit is returned if the sender of the first bundle
+	// transaction reached its bundle limit.
+	ErrBundleSenderLimit = errors.New("sender exceeds bundle limit")
+
+	// ErrBundleGasLimit is returned if the bundle transactions need more gas
+	// than a block provides.
+	ErrBundleGasLimit = errors.New("bundle exceeds block gas limit")
+
+	// ErrBundleNotSender is returned if a bundle cancellation isn't signed by
+	// the sender of one of the bundle transactions.
+	ErrBundleNotSender = errors.New("bundle cancellation not signed by a bundle sender")
+)
+
+var (
+	bundleGauge        = metrics.NewRegisteredGauge("bundlepool/bundles", nil)
+	bundleAddMeter     = metrics.NewRegisteredMeter("bundlepool/add", nil)
+	bundleLandedMeter  = metrics.NewRegisteredMeter("bundlepool/landed", nil)
+	bundleExpiredMeter = metrics.NewRegisteredMeter("bundlepool/expired", nil)
+)
+
+// BlockChain defines the minimal set of methods needed to back a bundle pool
+// with a chain. Exists to allow mocking the live chain out of tests.
+type BlockChain interface {
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// CurrentBlock returns the current head of the chain.
+	CurrentBlock() *types.Header
+
+	// StateAt returns a state database for a given root hash (generally the head).
+	StateAt(root common.Hash) (*state.StateDB, error)
+}
+
+// BundlePool is the subpool keeping the bundles sent through eth_sendBundle.
+// It doesn't accept plain transactions, the bundle transactions are neither
+// pending in the txpool nor announced to the network. Bundles are dropped once
+// they expire or one of their transactions lands on chain.
+type BundlePool struct {
+	config Config
+	chain  BlockChain
+	signer types.Signer
+
+	head    *types.Header
+	gasTip  *big.Int // Minimum gas tip the bundles must offer
+	maxGas  atomic.Uint64
+	bundles map[common.Hash]*types.Bundle
+	senders map[common.Address]uint64 // Number of bundles by sender of their first transaction
+	lock    sync.RWMutex
+
+	txFeed     event.Feed
+	reannoFeed event.Feed
+}
+
+// New creates a new bundle pool, the pool is only usable after Init.
+func New(config Config, chain BlockChain) *BundlePool {
+	config = config.sanitize()
+
+	return &BundlePool{
+		config:  config,
+		chain:   chain,
+		signer:  types.LatestSigner(chain.Config()),
+		gasTip:  new(big.Int),
+		bundles: make(map[common.Hash]*types.Bundle),
+		senders: make(map[common.Address]uint64),
+	}
+}
+
+// Filter returns false, bundle transactions only enter the pool through
+// AddBundle.
+func (p *BundlePool) Filter(tx *types.Transaction) bool {
+	return false
+}
+
+// Init sets the head and the minimum gas tip the bundles are validated against.
+func (p *BundlePool) Init(gasTip uint64, head *types.Header, reserve txpool.AddressReserver) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.head = head
+	p.gasTip = new(big.Int).SetUint64(gasTip)
+	return nil
+}
+
+// Close terminates the bundle pool.
+func (p *BundlePool) Close() error {
+	return nil
+}
+
+// Reset drops the bundles that can't be included after the new head, either
+// because they expired or because one of their transactions landed.
+func (p *BundlePool) Reset(oldHead, newHead *types.Header) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.head = newHead
+	if len(p.bundles) == 0 {
+		return
+	}
+	statedb, err := p.chain.StateAt(newHead.Root)
+	if err != nil {
+		log.Error("Failed to reset bundlepool state", "err", err)
+		statedb = nil
+	}
+	for hash, bundle := range p.bundles {
+		if bundle.Expired(newHead) {
+			p.remove(hash, bundle)
+			bundleExpiredMeter.Mark(1)
+			continue
+		}
+		if statedb != nil && p.landed(statedb, bundle) {
+			p.remove(hash, bundle)
+			bundleLandedMeter.Mark(1)
+		}
+	}
+	bundleGauge.Update(int64(len(p.bundles)))
+}
+
+// landed returns whether the nonce of one of the bundle transactions is already
+// used on chain, which makes the bundle impossible to include.
+func (p *BundlePool) landed(statedb *state.StateDB, bundle *types.Bundle) bool {
+	for _, tx := range bundle.Txs {
+		from, err := types.Sender(p.signer, tx)
+		if err != nil {
+			return true
+		}
+		if statedb.GetNonce(from) > tx.Nonce() {
+			return true
+		}
+	}
+	return false
+}
+
+// remove drops a bundle from the pool. The lock must be held.
+func (p *BundlePool) remove(hash common.Hash, bundle *types.Bundle) {
+	delete(p.bundles, hash)
+
+	from, _ := types.Sender(p.signer, bundle.Txs[0])
+	if p.senders[from] <= 1 {
+		delete(p.senders, from)
+	} else {
+		p.senders[from]--
+	}
+}
+
+// SetGasTip sets the minimum gas tip new bundles must offer. The bundles
+// already pooled are priced again when the block is built.
+func (p *BundlePool) SetGasTip(tip *big.Int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.gasTip = new(big.Int).Set(tip)
+}
+
+// SetMaxGas sets the maximum gas new bundles can use, on top of the gas limit
+// of the head.
+func (p *BundlePool) SetMaxGas(maxGas uint64) {
+	p.maxGas.Store(maxGas)
+}
+
+// Has returns false, bundle transactions aren't pool transactions.
+func (p *BundlePool) Has(hash common.Hash) bool {
+	return false
+}
+
+// Get returns nil, bundle transactions aren't pool transactions.
+func (p *BundlePool) Get(hash common.Hash) *types.Transaction {
+	return nil
+}
+
+// GetBlobs returns nil, bundles don't contain blob transactions.
+func (p *BundlePool) GetBlobs(vhashes []common.Hash) ([]*kzg4844.Blob, []*kzg4844.Proof) {
+	return nil, nil
+}
+
+// Add rejects all transactions, bundles are added through AddBundle.
+func (p *BundlePool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i := range txs {
+		errs[i] = core.ErrTxTypeNotSupported
+	}
+	return errs
+}
+
+// Pending returns nil, bundle transactions are retrieved with PendingBundles.
+func (p *BundlePool) Pending(filter txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction {
+	return nil
+}
+
+// SubscribeTransactions subscribes to new transaction events, none are sent
+// since bundle transactions are not propagated.
+func (p *BundlePool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
+	return p.txFeed.Subscribe(ch)
+}
+
+// SubscribeReannoTxsEvent subscribes to reannounced transaction events, none
+// are sent since bundle transactions are not propagated.
+func (p *BundlePool) SubscribeReannoTxsEvent(ch chan<- core.ReannoTxsEvent) event.Subscription {
+	return p.reannoFeed.Subscribe(ch)
+}
+
+// Nonce returns 0, bundles don't affect the next nonce of the accounts.
+func (p *BundlePool) Nonce(addr common.Address) uint64 {
+	return 0
+}
+
+// Stats returns no transactions, bundles are not counted as pool transactions.
+func (p *BundlePool) Stats() (int, int) {
+	return 0, 0
+}
+
+// Content returns no transactions, bundles are not counted as pool transactions.
+func (p *BundlePool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return make(map[common.Address][]*types.Transaction), make(map[common.Address][]*types.Transaction)
+}
+
+// ContentFrom returns no transactions, bundles are not counted as pool
+// transactions.
+func (p *BundlePool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
+	return []*types.Transaction{}, []*types.Transaction{}
+}
+
+// Locals returns nil, the bundle pool has no local accounts.
+func (p *BundlePool) Locals() []common.Address {
+	return nil
+}
+
+// Status returns TxStatusUnknown, bundle transactions aren't pool transactions.
+func (p *BundlePool) Status(hash common.Hash) txpool.TxStatus {
+	return txpool.TxStatusUnknown
+}
+
+// Clear drops all bundles.
+func (p *BundlePool) Clear() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.bundles = make(map[common.Hash]*types.Bundle)
+	p.senders = make(map[common.Address]uint64)
+	bundleGauge.Update(0)
+}
+
+// AddBundle adds a bundle to the pool. A bundle without first block is valid
+// from the next block on. The bundles are anonymous, so each sender of a first
+// bundle transaction can only pool AccountSlots of them, and they must fit in
+// a block and pay the minimum gas tip of the pool.
+func (p *BundlePool) AddBundle(bundle *types.Bundle) error {
+	if len(bundle.Txs) == 0 {
+		return types.ErrEmptyBundle
+	}
+	for _, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType {
+			return types.ErrBundleBlobTx
+		}
+	}
+	from, err := types.Sender(p.signer, bundle.Txs[0])
+	if err != nil {
+		return err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	next := p.head.Number.Uint64() + 1
+	if bundle.BlockNumber == 0 {
+		bundle.BlockNumber = next
+		bundle.MaxBlockNumber = max(bundle.MaxBlockNumber, next)
+	}
+	if bundle.Expired(p.head) {
+		return ErrBundleExpired
+	}
+	if bundle.MaxBlockNumber > next+p.config.MaxBlocksAhead {
+		return ErrBundleTooFar
+	}
+	gasLimit := p.head.GasLimit
+	if maxGas := p.maxGas.Load(); maxGas != 0 {
+		gasLimit = min(gasLimit, maxGas)
+	}
+	if bundle.Gas() > gasLimit {
+		return ErrBundleGasLimit
+	}
+	if bundle.GasTip(p.head.BaseFee).Cmp(p.gasTip) < 0 {
+		return txpool.ErrUnderpriced
+	}
+	hash := bundle.Hash()
+	if _, ok := p.bundles[hash]; ok {
+		return txpool.ErrAlreadyKnown
+	}
+	if uint64(len(p.bundles)) >= p.config.MaxBundles {
+		return ErrBundlePoolFull
+	}
+	if p.senders[from] >= p.config.AccountSlots {
+		return ErrBundleSenderLimit
+	}
+	p.bundles[hash] = bundle
+	p.senders[from]++
+	bundleAddMeter.Mark(1)
+	bundleGauge.Update(int64(len(p.bundles)))
+	return nil
+}
+
+// PendingBundles returns the bundles that can be included in the block with
+// the given number and time.
+func (p *BundlePool) PendingBundles(number uint64, time uint64) []*types.Bundle {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	bundles := make([]*types.Bundle, 0, len(p.bundles))
+	for _, bundle := range p.bundles {
+		if bundle.ValidAt(number, time) {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles
+}
+
+// CancelBundle drops a bundle on behalf of sender, who must have sent one of
+// its transactions. It returns whether the bundle was known.
+func (p *BundlePool) CancelBundle(hash common.Hash, sender common.Address) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	bundle, ok := p.bundles[hash]
+	if !ok {
+		return false, nil
+	}
+	if !bundle.SentBy(p.signer, sender) {
+		return false, ErrBundleNotSender
+	}
+	p.remove(hash, bundle)
+	bundleGauge.Update(int64(len(p.bundles)))
+	return true, nil
+}
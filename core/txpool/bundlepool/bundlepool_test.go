@@ -0,0 +1,207 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundlepool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+type testBlockChain struct {
+	statedb *state.StateDB
+	head    *types.Header
+}
+
+func (bc *testBlockChain) Config() *params.ChainConfig { return params.TestChainConfig }
+func (bc *testBlockChain) CurrentBlock() *types.Header { return bc.head }
+
+func (bc *testBlockChain) StateAt(common.Hash) (*state.StateDB, error) {
+	return bc.statedb, nil
+}
+
+func newTestHeader(number uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number), Time: 10 * number, GasLimit: 30_000_000}
+}
+
+func newTestPool(t *testing.T, config Config) (*BundlePool, *testBlockChain) {
+	t.Helper()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	chain := &testBlockChain{statedb: statedb, head: newTestHeader(1)}
+	pool := New(config, chain)
+	if err := pool.Init(0, chain.head, nil); err != nil {
+		t.Fatal(err)
+	}
+	return pool, chain
+}
+
+func newTestBundle(nonce uint64, blockNumber, maxBlockNumber uint64) *types.Bundle {
+	tx := types.MustSignNewTx(testKey, types.LatestSigner(params.TestChainConfig), &types.LegacyTx{
+		Nonce:    nonce,
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(params.GWei),
+	})
+	return &types.Bundle{Txs: types.Transactions{tx}, BlockNumber: blockNumber, MaxBlockNumber: maxBlockNumber}
+}
+
+func TestAddBundle(t *testing.T) {
+	pool, _ := newTestPool(t, Config{MaxBundles: 2, MaxBlocksAhead: 10})
+
+	next := newTestBundle(0, 0, 0)
+	if err := pool.AddBundle(next); err != nil {
+		t.Fatalf("bundle rejected: %v", err)
+	}
+	if next.BlockNumber != 2 || next.MaxBlockNumber != 2 {
+		t.Fatalf("bundle without target not set to the next block: %d-%d", next.BlockNumber, next.MaxBlockNumber)
+	}
+	if err := pool.AddBundle(next); !errors.Is(err, txpool.ErrAlreadyKnown) {
+		t.Fatalf("duplicate bundle: have %v, want %v", err, txpool.ErrAlreadyKnown)
+	}
+	tests := []struct {
+		bundle *types.Bundle
+		err    error
+	}{
+		{newTestBundle(1, 1, 1), ErrBundleExpired},
+		{newTestBundle(1, 2, 13), ErrBundleTooFar},
+		{&types.Bundle{Txs: newTestBundle(1, 2, 2).Txs, BlockNumber: 2, MaxBlockNumber: 2, MaxTimestamp: 10}, ErrBundleExpired},
+		{newTestBundle(1, 3, 12), nil},
+		{newTestBundle(2, 3, 12), ErrBundlePoolFull},
+	}
+	for i, tt := range tests {
+		if err := pool.AddBundle(tt.bundle); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if have := pool.PendingBundles(2, 20); len(have) != 1 || have[0] != next {
+		t.Fatalf("unexpected bundles for block 2: %v", have)
+	}
+	// Only the bundle senders can cancel it
+	sender := crypto.PubkeyToAddress(testKey.PublicKey)
+	if ok, err := pool.CancelBundle(next.Hash(), common.Address{0x1}); ok || !errors.Is(err, ErrBundleNotSender) {
+		t.Fatalf("cancellation by a stranger: have %v %v, want %v", ok, err, ErrBundleNotSender)
+	}
+	if ok, err := pool.CancelBundle(next.Hash(), sender); !ok || err != nil {
+		t.Fatalf("cancellation by the sender failed: %v %v", ok, err)
+	}
+	if ok, _ := pool.CancelBundle(next.Hash(), sender); ok {
+		t.Fatal("unknown bundle cancelled")
+	}
+	if have := pool.PendingBundles(2, 20); len(have) != 0 {
+		t.Fatalf("cancelled bundle pending: %v", have)
+	}
+}
+
+func TestAddBundleLimits(t *testing.T) {
+	pool, _ := newTestPool(t, Config{MaxBundles: 10, AccountSlots: 2, MaxBlocksAhead: 10})
+
+	// The sender of the first bundle transaction is limited
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.AddBundle(newTestBundle(nonce, 0, 0)); err != nil {
+			t.Fatalf("bundle %d rejected: %v", nonce, err)
+		}
+	}
+	if err := pool.AddBundle(newTestBundle(2, 0, 0)); !errors.Is(err, ErrBundleSenderLimit) {
+		t.Fatalf("bundle over the sender limit: have %v, want %v", err, ErrBundleSenderLimit)
+	}
+	// Cancelling a bundle frees a slot of its sender
+	bundle := pool.PendingBundles(2, 20)[0]
+	if ok, err := pool.CancelBundle(bundle.Hash(), crypto.PubkeyToAddress(testKey.PublicKey)); !ok || err != nil {
+		t.Fatalf("cancellation failed: %v %v", ok, err)
+	}
+	if err := pool.AddBundle(newTestBundle(2, 0, 0)); err != nil {
+		t.Fatalf("bundle rejected after a cancellation: %v", err)
+	}
+	pool.Clear()
+
+	// The bundles must fit in a block and pay the minimum tip
+	key, _ := crypto.GenerateKey()
+	newBundle := func(gas uint64, price int64) *types.Bundle {
+		tx := types.MustSignNewTx(key, types.LatestSigner(params.TestChainConfig), &types.LegacyTx{
+			Gas:      gas,
+			GasPrice: big.NewInt(price),
+		})
+		return &types.Bundle{Txs: types.Transactions{tx}}
+	}
+	pool.SetMaxGas(20_000_000)
+	if err := pool.AddBundle(newBundle(20_000_001, params.GWei)); !errors.Is(err, ErrBundleGasLimit) {
+		t.Fatalf("bundle over the gas limit: have %v, want %v", err, ErrBundleGasLimit)
+	}
+	pool.SetGasTip(big.NewInt(params.GWei))
+	if err := pool.AddBundle(newBundle(params.TxGas, params.GWei-1)); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Fatalf("underpriced bundle: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	if err := pool.AddBundle(newBundle(20_000_000, params.GWei)); err != nil {
+		t.Fatalf("bundle within the limits rejected: %v", err)
+	}
+}
+
+func TestPendingBundlesTimestamp(t *testing.T) {
+	pool, _ := newTestPool(t, DefaultConfig)
+
+	bundle := newTestBundle(0, 2, 5)
+	bundle.MinTimestamp, bundle.MaxTimestamp = 30, 40
+	if err := pool.AddBundle(bundle); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		number, time uint64
+		pending      bool
+	}{
+		{2, 20, false},
+		{3, 30, true},
+		{4, 40, true},
+		{4, 41, false},
+		{6, 35, false},
+	} {
+		if have := len(pool.PendingBundles(tt.number, tt.time)) == 1; have != tt.pending {
+			t.Errorf("block %d time %d: pending %v, want %v", tt.number, tt.time, have, tt.pending)
+		}
+	}
+}
+
+func TestResetBundles(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultConfig)
+
+	var (
+		expiring = newTestBundle(3, 2, 2)
+		landing  = newTestBundle(1, 2, 5)
+		pending  = newTestBundle(2, 2, 5)
+	)
+	for _, bundle := range []*types.Bundle{expiring, landing, pending} {
+		if err := pool.AddBundle(bundle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chain.statedb.SetNonce(crypto.PubkeyToAddress(testKey.PublicKey), 2)
+	head := newTestHeader(2)
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if have := pool.PendingBundles(3, 30); len(have) != 1 || have[0] != pending {
+		t.Fatalf("unexpected bundles after reset: %v", have)
+	}
+}
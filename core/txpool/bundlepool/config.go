@@ -0,0 +1,55 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundlepool
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Config are the configuration parameters of the bundle pool.
+type Config struct {
+	Enabled        bool   // Whether to accept bundles, always on in builder mode
+	MaxBundles     uint64 // Maximum number of bundles kept by the pool
+	AccountSlots   uint64 // Maximum number of bundles kept per sender of their first transaction
+	MaxBlocksAhead uint64 // Maximum number of blocks ahead of the head a bundle can target
+}
+
+// DefaultConfig contains the default configurations for the bundle pool.
+var DefaultConfig = Config{
+	MaxBundles:     10000,
+	AccountSlots:   16,
+	MaxBlocksAhead: 100,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *Config) sanitize() Config {
+	conf := *config
+	if conf.MaxBundles < 1 {
+		log.Warn("Sanitizing invalid bundlepool max bundles", "provided", conf.MaxBundles, "updated", DefaultConfig.MaxBundles)
+		conf.MaxBundles = DefaultConfig.MaxBundles
+	}
+	if conf.AccountSlots < 1 {
+		log.Warn("Sanitizing invalid bundlepool account slots", "provided", conf.AccountSlots, "updated", DefaultConfig.AccountSlots)
+		conf.AccountSlots = DefaultConfig.AccountSlots
+	}
+	if conf.MaxBlocksAhead < 1 {
+		log.Warn("Sanitizing invalid bundlepool max blocks ahead", "provided", conf.MaxBlocksAhead, "updated", DefaultConfig.MaxBlocksAhead)
+		conf.MaxBlocksAhead = DefaultConfig.MaxBlocksAhead
+	}
+	return conf
+}
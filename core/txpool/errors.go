@@ -63,4 +63,8 @@ var (
 
 	// ErrInBlackList is returned if the transaction send by banned address
 	ErrInBlackList = errors.New("sender or to in black list")
+
+	// ErrBundlePoolDisabled is returned if a bundle is sent to a pool without
+	// bundle subpool.
+	ErrBundlePoolDisabled = errors.New("bundle pool not enabled")
 )
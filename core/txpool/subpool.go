@@ -179,3 +179,18 @@ type SubPool interface {
 	// Clear removes all tracked transactions from the pool
 	Clear()
 }
+
+// BundleSubPool is a subpool keeping bundles, lists of transactions included
+// in a block atomically and in order.
+type BundleSubPool interface {
+	// AddBundle adds a bundle to the pool.
+	AddBundle(bundle *types.Bundle) error
+
+	// PendingBundles retrieves the bundles that can be included in the block
+	// with the given number and time.
+	PendingBundles(number uint64, time uint64) []*types.Bundle
+
+	// CancelBundle drops a bundle from the pool on behalf of the sender of one
+	// of its transactions, returning whether it was known.
+	CancelBundle(hash common.Hash, sender common.Address) (bool, error)
+}
@@ -526,3 +526,35 @@ func (p *TxPool) Clear() {
 		subpool.Clear()
 	}
 }
+
+// AddBundle adds a bundle to the bundle subpool.
+func (p *TxPool) AddBundle(bundle *types.Bundle) error {
+	for _, subpool := range p.subpools {
+		if pool, ok := subpool.(BundleSubPool); ok {
+			return pool.AddBundle(bundle)
+		}
+	}
+	return ErrBundlePoolDisabled
+}
+
+// PendingBundles retrieves the bundles that can be included in the block with
+// the given number and time.
+func (p *TxPool) PendingBundles(number uint64, time uint64) []*types.Bundle {
+	for _, subpool := range p.subpools {
+		if pool, ok := subpool.(BundleSubPool); ok {
+			return pool.PendingBundles(number, time)
+		}
+	}
+	return nil
+}
+
+// CancelBundle drops a bundle from the bundle subpool on behalf of one of its
+// senders, returning whether it was known.
+func (p *TxPool) CancelBundle(hash common.Hash, sender common.Address) (bool, error) {
+	for _, subpool := range p.subpools {
+		if pool, ok := subpool.(BundleSubPool); ok {
+			return pool.CancelBundle(hash, sender)
+		}
+	}
+	return false, nil
+}
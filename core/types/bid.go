@@ -197,3 +197,96 @@ type MevParams struct {
 	BuilderFeeCeil        *big.Int
 	Version               string
 }
+
+// BidRecord is the outcome of a bid received for a block. The records of past
+// blocks are kept so that validators can audit builders.
+type BidRecord struct {
+	Builder     common.Address `json:"builder"`
+	BidHash     common.Hash    `json:"bidHash"`
+	BlockNumber uint64         `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	Txs         int            `json:"txs"`
+	GasUsed     uint64         `json:"gasUsed"`
+	GasFee      *big.Int       `json:"gasFee"`
+	BuilderFee  *big.Int       `json:"builderFee"`
+	ReceivedAt  uint64         `json:"receivedAt"` // Unix time in milliseconds
+
+	ExpectedBlockReward     *big.Int `json:"expectedBlockReward,omitempty"`
+	ExpectedValidatorReward *big.Int `json:"expectedValidatorReward,omitempty"`
+
+	Accepted              bool     `json:"accepted"`              // Whether the bid was admitted for simulation
+	Simulated             bool     `json:"simulated"`             // Whether the simulation ran to completion
+	SimDuration           uint64   `json:"simDuration,omitempty"` // In milliseconds
+	PackedBlockReward     *big.Int `json:"packedBlockReward,omitempty"`
+	PackedValidatorReward *big.Int `json:"packedValidatorReward,omitempty"`
+	Error                 string   `json:"error,omitempty"` // Why the bid was rejected or its simulation failed
+
+	Best bool `json:"best"` // Whether it was the best bid when the next block arrived
+	Won  bool `json:"won"`  // Whether the local block was built from the bid
+}
+
+// Sources of a sealed block.
+const (
+	BlockSourceLocal = "local" // Built by the validator
+	BlockSourceBid   = "bid"   // Built from the best bid of a builder
+)
+
+//go:generate go run github.com/fjl/gencodec -type BlockSourceReport -field-override blockSourceReportMarshaling -out gen_block_source_report_json.go
+
+// BlockSourceReport compares the reward of the block built locally with the
+// reward of the best bid, for a block sealed by the validator.
+type BlockSourceReport struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Source      string      `json:"source"` // BlockSourceLocal or BlockSourceBid
+
+	LocalBlockReward     *big.Int `json:"localBlockReward"`
+	LocalValidatorReward *big.Int `json:"localValidatorReward"`
+
+	Builder            *common.Address `json:"builder,omitempty"` // Builder of the best bid, if any
+	BidHash            *common.Hash    `json:"bidHash,omitempty"`
+	BidBlockReward     *big.Int        `json:"bidBlockReward,omitempty"`
+	BidValidatorReward *big.Int        `json:"bidValidatorReward,omitempty"`
+
+	// Delta is the block reward of the sealed block minus the reward of the
+	// local block, zero when the local block was sealed.
+	Delta *big.Int `json:"delta"`
+}
+
+type blockSourceReportMarshaling struct {
+	BlockNumber          hexutil.Uint64
+	LocalBlockReward     *hexutil.Big
+	LocalValidatorReward *hexutil.Big
+	BidBlockReward       *hexutil.Big
+	BidValidatorReward   *hexutil.Big
+	Delta                *hexutil.Big
+}
+
+// Statuses of a bid posted in a BidEvent.
+const (
+	BidAccepted = "accepted" // Admitted for simulation
+	BidRejected = "rejected" // Rejected on arrival, by its simulation or by the final comparison with the local block
+	BidPromoted = "promoted" // Became the best bid of its block
+)
+
+// BidEvent is posted when a bid received by the validator is accepted,
+// rejected or promoted.
+type BidEvent struct {
+	Status      string         `json:"status"`
+	Builder     common.Address `json:"builder"`
+	BidHash     common.Hash    `json:"bidHash"`
+	BlockNumber uint64         `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	Txs         int            `json:"txs"`
+	GasUsed     uint64         `json:"gasUsed"`
+	GasFee      *big.Int       `json:"gasFee"`
+	BuilderFee  *big.Int       `json:"builderFee"`
+	Time        uint64         `json:"time"` // Unix time in milliseconds
+
+	ExpectedBlockReward     *big.Int `json:"expectedBlockReward,omitempty"`
+	ExpectedValidatorReward *big.Int `json:"expectedValidatorReward,omitempty"`
+	PackedBlockReward       *big.Int `json:"packedBlockReward,omitempty"` // Set once simulated
+	PackedValidatorReward   *big.Int `json:"packedValidatorReward,omitempty"`
+
+	Error string `json:"error,omitempty"` // Why the bid was rejected
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// BuilderStatus is the status of a builder in the registry of a validator.
+type BuilderStatus uint8
+
+const (
+	BuilderActive    BuilderStatus = iota // Bids of the builder are accepted
+	BuilderSuspended                      // Builder failed health checks, reactivated once healthy again
+	BuilderBanned                         // Builder banned by the operator
+)
+
+var errInvalidBuilderStatus = errors.New("invalid builder status")
+
+func (s BuilderStatus) String() string {
+	switch s {
+	case BuilderActive:
+		return "active"
+	case BuilderSuspended:
+		return "suspended"
+	case BuilderBanned:
+		return "banned"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s BuilderStatus) MarshalText() ([]byte, error) {
+	if s > BuilderBanned {
+		return nil, errInvalidBuilderStatus
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *BuilderStatus) UnmarshalText(input []byte) error {
+	switch string(input) {
+	case "active":
+		*s = BuilderActive
+	case "suspended":
+		*s = BuilderSuspended
+	case "banned":
+		*s = BuilderBanned
+	default:
+		return fmt.Errorf("%w: %q", errInvalidBuilderStatus, input)
+	}
+	return nil
+}
+
+// BuilderRecord is the entry of a builder in the registry of a validator.
+type BuilderRecord struct {
+	Address      common.Address `json:"address"`
+	URL          string         `json:"url"`
+	Status       BuilderStatus  `json:"status"`
+	Failures     uint64         `json:"failures"`               // Consecutive failed health checks and issue reports
+	RegisteredAt uint64         `json:"registeredAt"`           // Unix time in seconds
+	UpdatedAt    uint64         `json:"updatedAt"`              // Unix time in seconds of the last status change
+	Reason       string         `json:"reason,omitempty"`       // Why the builder is not active
+	SelfRegister bool           `json:"selfRegister,omitempty"` // Whether the builder registered itself
+	Configured   bool           `json:"configured,omitempty"`   // Whether the builder is listed in the config
+
+	// LastRegistration is the timestamp of the last signed registration
+	// accepted from the builder, older ones are rejected as replays.
+	LastRegistration uint64 `json:"lastRegistration,omitempty"`
+}
+
+// BuilderRegistration is the message a builder signs to register itself to a
+// validator.
+type BuilderRegistration struct {
+	Validator common.Address `json:"validator"`
+	URL       string         `json:"url"`
+	Timestamp uint64         `json:"timestamp"` // Unix time in seconds
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// Hash returns the hash signed by the builder, the keccak256 hash of the RLP
+// encoding of the registration without signature.
+func (r *BuilderRegistration) Hash() common.Hash {
+	data, _ := rlp.EncodeToBytes([]interface{}{r.Validator, r.URL, r.Timestamp})
+	return crypto.Keccak256Hash(data)
+}
+
+// EcrecoverSender returns the address of the builder that signed the
+// registration.
+func (r *BuilderRegistration) EcrecoverSender() (common.Address, error) {
+	if len(r.Signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid signature length")
+	}
+	pk, err := crypto.SigToPub(r.Hash().Bytes(), r.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pk), nil
+}
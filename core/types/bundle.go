@@ -0,0 +1,217 @@
+package types
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxBundleTxs is the maximum number of transactions in a bundle.
+const MaxBundleTxs = 50
+
+var (
+	ErrEmptyBundle       = errors.New("bundle has no transactions")
+	ErrBundleTooLarge    = errors.New("too many transactions in bundle")
+	ErrInvalidBlockRange = errors.New("max block number lower than block number")
+	ErrInvalidTimeRange  = errors.New("max timestamp lower than min timestamp")
+	ErrBundleBlobTx      = errors.New("blob transactions are not supported in bundles")
+)
+
+// cancelBundlePrefix is prepended to the bundle hash signed to cancel it, so
+// that the signature can't be replayed as a signature of the bundle hash.
+var cancelBundlePrefix = []byte("eth_cancelBundle:")
+
+// SendBundleArgs represents the arguments of eth_sendBundle.
+type SendBundleArgs struct {
+	Txs []hexutil.Bytes `json:"txs"`
+	// BlockNumber is the first block the bundle can be included in, zero
+	// meaning the next block.
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// MaxBlockNumber is the last block the bundle can be included in, zero
+	// meaning BlockNumber.
+	MaxBlockNumber    hexutil.Uint64 `json:"maxBlockNumber"`
+	RevertingTxHashes []common.Hash  `json:"revertingTxHashes"`
+	// MinTimestamp and MaxTimestamp bound the time of the blocks the bundle
+	// can be included in, zero meaning no bound.
+	MinTimestamp *uint64 `json:"minTimestamp,omitempty"`
+	MaxTimestamp *uint64 `json:"maxTimestamp,omitempty"`
+}
+
+// ToBundle decodes the bundle transactions and checks their senders.
+func (args *SendBundleArgs) ToBundle(signer Signer) (*Bundle, error) {
+	if len(args.Txs) == 0 {
+		return nil, ErrEmptyBundle
+	}
+	if len(args.Txs) > MaxBundleTxs {
+		return nil, ErrBundleTooLarge
+	}
+	maxBlockNumber := uint64(args.MaxBlockNumber)
+	if maxBlockNumber == 0 {
+		maxBlockNumber = uint64(args.BlockNumber)
+	}
+	if maxBlockNumber < uint64(args.BlockNumber) {
+		return nil, ErrInvalidBlockRange
+	}
+	var minTimestamp, maxTimestamp uint64
+	if args.MinTimestamp != nil {
+		minTimestamp = *args.MinTimestamp
+	}
+	if args.MaxTimestamp != nil {
+		maxTimestamp = *args.MaxTimestamp
+	}
+	if maxTimestamp != 0 && maxTimestamp < minTimestamp {
+		return nil, ErrInvalidTimeRange
+	}
+	txs := make(Transactions, len(args.Txs))
+	for i, data := range args.Txs {
+		tx := new(Transaction)
+		if err := tx.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+		if tx.Type() == BlobTxType {
+			return nil, ErrBundleBlobTx
+		}
+		if _, err := Sender(signer, tx); err != nil {
+			return nil, err
+		}
+		txs[i] = tx
+	}
+	return &Bundle{
+		Txs:               txs,
+		BlockNumber:       uint64(args.BlockNumber),
+		MaxBlockNumber:    maxBlockNumber,
+		RevertingTxHashes: args.RevertingTxHashes,
+		MinTimestamp:      minTimestamp,
+		MaxTimestamp:      maxTimestamp,
+	}, nil
+}
+
+// Bundle is a list of transactions included in a block atomically and in
+// order.
+type Bundle struct {
+	Txs               Transactions
+	BlockNumber       uint64 // First block the bundle is valid for, zero if not set yet
+	MaxBlockNumber    uint64 // Last block the bundle is valid for, zero if not set yet
+	RevertingTxHashes []common.Hash
+	MinTimestamp      uint64 // Earliest block time the bundle is valid for, zero if unbounded
+	MaxTimestamp      uint64 // Latest block time the bundle is valid for, zero if unbounded
+
+	hash atomic.Value
+}
+
+// Hash returns the hash of the bundle, the RLP hash of its transaction hashes
+// and of all its parameters. The hash is only cached once the first block of
+// the bundle is set, since the pool sets it when adding the bundle.
+func (b *Bundle) Hash() common.Hash {
+	if hash := b.hash.Load(); hash != nil {
+		return hash.(common.Hash)
+	}
+	hashes := make([]common.Hash, len(b.Txs))
+	for i, tx := range b.Txs {
+		hashes[i] = tx.Hash()
+	}
+	h := rlpHash([]interface{}{
+		hashes,
+		b.BlockNumber,
+		b.MaxBlockNumber,
+		b.RevertingTxHashes,
+		b.MinTimestamp,
+		b.MaxTimestamp,
+	})
+	if b.BlockNumber != 0 {
+		b.hash.Store(h)
+	}
+	return h
+}
+
+// SentBy returns whether addr sent one of the bundle transactions.
+func (b *Bundle) SentBy(signer Signer, addr common.Address) bool {
+	for _, tx := range b.Txs {
+		if from, err := Sender(signer, tx); err == nil && from == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAt returns whether the bundle can be included in the block with the
+// given number and time.
+func (b *Bundle) ValidAt(number uint64, time uint64) bool {
+	if number < b.BlockNumber || number > b.MaxBlockNumber {
+		return false
+	}
+	if time < b.MinTimestamp || (b.MaxTimestamp != 0 && time > b.MaxTimestamp) {
+		return false
+	}
+	return true
+}
+
+// Expired returns whether the bundle can't be included in any block after the
+// given head any more.
+func (b *Bundle) Expired(head *Header) bool {
+	return b.MaxBlockNumber <= head.Number.Uint64() || (b.MaxTimestamp != 0 && b.MaxTimestamp <= head.Time)
+}
+
+// Gas returns the gas limit of all the bundle transactions.
+func (b *Bundle) Gas() uint64 {
+	var gas uint64
+	for _, tx := range b.Txs {
+		gas += tx.Gas()
+	}
+	return gas
+}
+
+// GasTip returns the gas tip offered by the bundle transactions on top of the
+// given base fee, weighted by their gas limits. It is zero if one of them
+// doesn't cover the base fee.
+func (b *Bundle) GasTip(baseFee *big.Int) *big.Int {
+	var (
+		fees = new(big.Int)
+		gas  uint64
+	)
+	for _, tx := range b.Txs {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			return new(big.Int)
+		}
+		fees.Add(fees, tip.Mul(tip, new(big.Int).SetUint64(tx.Gas())))
+		gas += tx.Gas()
+	}
+	if gas == 0 {
+		return fees
+	}
+	return fees.Div(fees, new(big.Int).SetUint64(gas))
+}
+
+// CanRevert returns whether the given bundle transaction is allowed to fail.
+func (b *Bundle) CanRevert(hash common.Hash) bool {
+	return slices.Contains(b.RevertingTxHashes, hash)
+}
+
+// CancelBundleArgs represents the arguments of eth_cancelBundle. The bundle
+// can only be cancelled by the sender of one of its transactions, who signs
+// CancelBundleHash(BundleHash).
+type CancelBundleArgs struct {
+	BundleHash common.Hash   `json:"bundleHash"`
+	Signature  hexutil.Bytes `json:"signature"`
+}
+
+// CancelBundleHash returns the hash signed to cancel the bundle with the given
+// hash.
+func CancelBundleHash(hash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(cancelBundlePrefix, hash[:])
+}
+
+// EcrecoverSender returns the address that signed the cancellation.
+func (args *CancelBundleArgs) EcrecoverSender() (common.Address, error) {
+	pk, err := crypto.SigToPub(CancelBundleHash(args.BundleHash).Bytes(), args.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pk), nil
+}
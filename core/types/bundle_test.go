@@ -0,0 +1,80 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBundleHash(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx := MustSignNewTx(key, HomesteadSigner{}, &LegacyTx{Gas: 21000, GasPrice: big.NewInt(1)})
+	newBundle := func() *Bundle {
+		return &Bundle{Txs: Transactions{tx}, BlockNumber: 1, MaxBlockNumber: 2}
+	}
+	base := newBundle().Hash()
+
+	// Every bundle parameter changes the hash
+	for i, update := range []func(*Bundle){
+		func(b *Bundle) { b.BlockNumber = 2 },
+		func(b *Bundle) { b.MaxBlockNumber = 3 },
+		func(b *Bundle) { b.RevertingTxHashes = []common.Hash{tx.Hash()} },
+		func(b *Bundle) { b.MinTimestamp = 1 },
+		func(b *Bundle) { b.MaxTimestamp = 1 },
+	} {
+		bundle := newBundle()
+		update(bundle)
+		if bundle.Hash() == base {
+			t.Errorf("update %d: bundle hash unchanged", i)
+		}
+	}
+	// The hash isn't cached until the pool sets the first block
+	bundle := &Bundle{Txs: Transactions{tx}}
+	bundle.Hash()
+	bundle.BlockNumber, bundle.MaxBlockNumber = 1, 2
+	if bundle.Hash() != base {
+		t.Fatal("bundle hash cached before the first block was set")
+	}
+}
+
+func TestCancelBundleSender(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	hash := common.Hash{0x1}
+
+	sig, err := crypto.Sign(CancelBundleHash(hash).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &CancelBundleArgs{BundleHash: hash, Signature: sig}
+	if sender, err := args.EcrecoverSender(); err != nil || sender != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("sender mismatch: have %v %v", sender, err)
+	}
+	// A signature of the bundle hash itself doesn't cancel it
+	if sig, err = crypto.Sign(hash.Bytes(), key); err != nil {
+		t.Fatal(err)
+	}
+	args.Signature = sig
+	if sender, _ := args.EcrecoverSender(); sender == crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("bundle hash signature accepted")
+	}
+}
+
+func TestBundleGasTip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	bundle := &Bundle{Txs: Transactions{
+		MustSignNewTx(key, HomesteadSigner{}, &LegacyTx{Gas: 30000, GasPrice: big.NewInt(5)}),
+		MustSignNewTx(key, HomesteadSigner{}, &LegacyTx{Nonce: 1, Gas: 10000, GasPrice: big.NewInt(9)}),
+	}}
+	if gas := bundle.Gas(); gas != 40000 {
+		t.Fatalf("bundle gas: have %d, want 40000", gas)
+	}
+	// (30000*4 + 10000*8) / 40000
+	if tip := bundle.GasTip(big.NewInt(1)); tip.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("bundle tip: have %v, want 5", tip)
+	}
+	if tip := bundle.GasTip(big.NewInt(6)); tip.Sign() != 0 {
+		t.Fatalf("bundle tip below the base fee: have %v, want 0", tip)
+	}
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SlashEvidenceType is the offence of a slashing evidence.
+type SlashEvidenceType uint8
+
+const (
+	DoubleSignEvidence    SlashEvidenceType = iota // Two headers of the same height signed by a validator
+	MaliciousVoteEvidence                          // Two conflicting votes of a validator
+)
+
+var errInvalidSlashEvidenceType = errors.New("invalid slash evidence type")
+
+func (t SlashEvidenceType) String() string {
+	switch t {
+	case DoubleSignEvidence:
+		return "doubleSign"
+	case MaliciousVoteEvidence:
+		return "maliciousVote"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t SlashEvidenceType) MarshalText() ([]byte, error) {
+	if t > MaliciousVoteEvidence {
+		return nil, errInvalidSlashEvidenceType
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *SlashEvidenceType) UnmarshalText(input []byte) error {
+	switch string(input) {
+	case "doubleSign":
+		*t = DoubleSignEvidence
+	case "maliciousVote":
+		*t = MaliciousVoteEvidence
+	default:
+		return fmt.Errorf("%w: %q", errInvalidSlashEvidenceType, input)
+	}
+	return nil
+}
+
+// SlashEvidenceStatus is the submission status of a slashing evidence.
+type SlashEvidenceStatus uint8
+
+const (
+	EvidenceDetected  SlashEvidenceStatus = iota // Evidence not submitted
+	EvidenceSubmitted                            // Evidence submitted to the slash contract
+	EvidenceFailed                               // Submission of the evidence failed
+)
+
+var errInvalidSlashEvidenceStatus = errors.New("invalid slash evidence status")
+
+func (s SlashEvidenceStatus) String() string {
+	switch s {
+	case EvidenceDetected:
+		return "detected"
+	case EvidenceSubmitted:
+		return "submitted"
+	case EvidenceFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SlashEvidenceStatus) MarshalText() ([]byte, error) {
+	if s > EvidenceFailed {
+		return nil, errInvalidSlashEvidenceStatus
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *SlashEvidenceStatus) UnmarshalText(input []byte) error {
+	switch string(input) {
+	case "detected":
+		*s = EvidenceDetected
+	case "submitted":
+		*s = EvidenceSubmitted
+	case "failed":
+		*s = EvidenceFailed
+	default:
+		return fmt.Errorf("%w: %q", errInvalidSlashEvidenceStatus, input)
+	}
+	return nil
+}
+
+// SlashVote is one of the conflicting votes of a malicious vote evidence.
+type SlashVote struct {
+	SourceNumber uint64        `json:"sourceNumber"`
+	SourceHash   common.Hash   `json:"sourceHash"`
+	TargetNumber uint64        `json:"targetNumber"`
+	TargetHash   common.Hash   `json:"targetHash"`
+	Signature    hexutil.Bytes `json:"signature"`
+}
+
+// NewSlashVote creates the slash vote of the vote.
+func NewSlashVote(vote *VoteEnvelope) *SlashVote {
+	return &SlashVote{
+		SourceNumber: vote.Data.SourceNumber,
+		SourceHash:   vote.Data.SourceHash,
+		TargetNumber: vote.Data.TargetNumber,
+		TargetHash:   vote.Data.TargetHash,
+		Signature:    common.CopyBytes(vote.Signature[:]),
+	}
+}
+
+// SlashEvidence is the evidence of an offence found by the monitors.
+type SlashEvidence struct {
+	Type        SlashEvidenceType   `json:"type"`
+	Number      uint64              `json:"number"`                // Number of the double signed headers, or target number of the last malicious vote
+	Validator   *common.Address     `json:"validator,omitempty"`   // Signer of the double signed headers
+	VoteAddress hexutil.Bytes       `json:"voteAddress,omitempty"` // BLS public key of the malicious votes
+	Header1     *Header             `json:"header1,omitempty"`
+	Header2     *Header             `json:"header2,omitempty"`
+	Vote1       *SlashVote          `json:"vote1,omitempty"`
+	Vote2       *SlashVote          `json:"vote2,omitempty"`
+	FirstSeen   uint64              `json:"firstSeen"` // Unix time in seconds the offence was first seen
+	Status      SlashEvidenceStatus `json:"status"`
+	TxHash      *common.Hash        `json:"txHash,omitempty"` // Hash of the evidence submission tx
+	Error       string              `json:"error,omitempty"`  // Reason of the submission failure
+}
+
+// Offender returns the address of the validator of a double sign evidence, or
+// the BLS public key of the validator of a malicious vote evidence.
+func (e *SlashEvidence) Offender() []byte {
+	if e.Type == DoubleSignEvidence {
+		if e.Validator == nil {
+			return nil
+		}
+		return e.Validator.Bytes()
+	}
+	return e.VoteAddress
+}
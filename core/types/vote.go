@@ -129,3 +129,11 @@ func NewSlashIndicatorFinalityEvidenceWrapper(vote1, vote2 *VoteEnvelope) *Slash
 		VoteAddr: common.Bytes2Hex(vote1.VoteAddress[:]),
 	}
 }
+
+// VoteHistory is the slashing protection record of a BLS key: the highest
+// source and target numbers of the votes signed with it.
+type VoteHistory struct {
+	SourceNumber uint64      `json:"sourceNumber"`
+	TargetNumber uint64      `json:"targetNumber"`
+	SigningRoot  common.Hash `json:"signingRoot"` // Hash of the vote data of the highest target, zero if unknown
+}
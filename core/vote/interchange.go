@@ -0,0 +1,123 @@
+package vote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// InterchangeFormatVersion is the version of the slashing protection
+// interchange format, following EIP-3076.
+const InterchangeFormatVersion = "5"
+
+var (
+	errInterchangeVersion = errors.New("unsupported interchange format version")
+	errInterchangeGenesis = errors.New("interchange of another chain")
+)
+
+// Interchange is the slashing protection history of the BLS keys in the JSON
+// format of EIP-3076, with the votes in place of the attestations, numbered by
+// blocks instead of epochs. The chain is identified by its genesis hash.
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []*InterchangeData  `json:"data"`
+}
+
+// InterchangeMetadata is the metadata of an interchange.
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string      `json:"interchange_format_version"`
+	GenesisHash              common.Hash `json:"genesis_hash"`
+}
+
+// InterchangeData is the vote history of a BLS key.
+type InterchangeData struct {
+	PubKey      hexutil.Bytes      `json:"pubkey"`
+	SignedVotes []*InterchangeVote `json:"signed_votes"`
+}
+
+// InterchangeVote is a vote signed with the key.
+type InterchangeVote struct {
+	SourceNumber uint64       `json:"source_number,string"`
+	TargetNumber uint64       `json:"target_number,string"`
+	SigningRoot  *common.Hash `json:"signing_root,omitempty"`
+}
+
+// ExportSlashingProtection exports the vote histories in the database, one
+// vote of the highest source and target per key.
+func ExportSlashingProtection(db ethdb.Iteratee, genesis common.Hash) *Interchange {
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeFormatVersion,
+			GenesisHash:              genesis,
+		},
+		Data: []*InterchangeData{},
+	}
+	for pubKey, history := range rawdb.ReadAllVoteHistories(db) {
+		vote := &InterchangeVote{
+			SourceNumber: history.SourceNumber,
+			TargetNumber: history.TargetNumber,
+		}
+		if history.SigningRoot != (common.Hash{}) {
+			root := history.SigningRoot
+			vote.SigningRoot = &root
+		}
+		interchange.Data = append(interchange.Data, &InterchangeData{
+			PubKey:      common.CopyBytes(pubKey[:]),
+			SignedVotes: []*InterchangeVote{vote},
+		})
+	}
+	sort.Slice(interchange.Data, func(i, j int) bool {
+		return bytes.Compare(interchange.Data[i].PubKey, interchange.Data[j].PubKey) < 0
+	})
+	return interchange
+}
+
+// ImportSlashingProtection merges the vote histories of the interchange into
+// the ones in the database, and returns the number of the imported keys. The
+// interchange is imported only if it is entirely valid.
+func ImportSlashingProtection(db ethdb.KeyValueStore, genesis common.Hash, interchange *Interchange) (int, error) {
+	if version := interchange.Metadata.InterchangeFormatVersion; version != InterchangeFormatVersion {
+		return 0, fmt.Errorf("%w: %q", errInterchangeVersion, version)
+	}
+	if interchange.Metadata.GenesisHash != genesis {
+		return 0, fmt.Errorf("%w: genesis %x, want %x", errInterchangeGenesis, interchange.Metadata.GenesisHash, genesis)
+	}
+	histories := make(map[types.BLSPublicKey]*types.VoteHistory)
+	for _, data := range interchange.Data {
+		if len(data.PubKey) != types.BLSPublicKeyLength {
+			return 0, fmt.Errorf("invalid BLS public key %s", data.PubKey)
+		}
+		pubKey := types.BLSPublicKey(data.PubKey)
+		for _, vote := range data.SignedVotes {
+			if vote.SourceNumber > vote.TargetNumber {
+				return 0, fmt.Errorf("invalid vote %d-->%d of %s", vote.SourceNumber, vote.TargetNumber, data.PubKey)
+			}
+			history := &types.VoteHistory{SourceNumber: vote.SourceNumber, TargetNumber: vote.TargetNumber}
+			if vote.SigningRoot != nil {
+				history.SigningRoot = *vote.SigningRoot
+			}
+			histories[pubKey] = mergeVoteHistory(histories[pubKey], history)
+		}
+	}
+	batch := db.NewBatch()
+	for pubKey, history := range histories {
+		// An invalid history is replaced, importing is the way to fix it
+		stored, err := rawdb.ReadVoteHistory(db, pubKey)
+		if err != nil {
+			log.Warn("Replacing invalid vote history", "err", err)
+		}
+		rawdb.WriteVoteHistory(batch, pubKey, mergeVoteHistory(stored, history))
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return len(histories), nil
+}
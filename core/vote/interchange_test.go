@@ -0,0 +1,101 @@
+package vote
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestVoteHistory(t *testing.T) {
+	signed := newTestVote(10, 12).Data
+	history := mergeVoteHistory(nil, voteHistoryOf(signed))
+
+	tests := []struct {
+		source, target uint64
+		err            error
+	}{
+		{13, 14, nil},
+		{10, 13, nil},
+		{9, 14, errVoteBelowHistory},  // Lower source
+		{11, 11, errVoteBelowHistory}, // Lower target
+		{11, 12, errVoteBelowHistory}, // Another vote of the highest target
+	}
+	for i, tt := range tests {
+		if err := checkVoteHistory(history, newTestVote(tt.source, tt.target).Data); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: vote %d-->%d: have %v, want %v", i, tt.source, tt.target, err, tt.err)
+		}
+	}
+	// The vote of the highest target is signed again
+	if err := checkVoteHistory(history, signed); err != nil {
+		t.Fatal(err)
+	}
+	// Unless the histories merged have different votes of the highest target
+	merged := mergeVoteHistory(history, voteHistoryOf(newTestVote(11, 12).Data))
+	if merged.SourceNumber != 11 || merged.TargetNumber != 12 || merged.SigningRoot != (common.Hash{}) {
+		t.Fatalf("unexpected merged history: %+v", merged)
+	}
+	if err := checkVoteHistory(merged, signed); !errors.Is(err, errVoteBelowHistory) {
+		t.Fatalf("have %v, want %v", err, errVoteBelowHistory)
+	}
+}
+
+func TestSlashingProtectionInterchange(t *testing.T) {
+	var (
+		genesis = common.Hash{0xbb}
+		key1    = types.BLSPublicKey{1}
+		key2    = types.BLSPublicKey{2}
+		vote    = newTestVote(10, 12).Data
+		oldDB   = rawdb.NewMemoryDatabase()
+		newDB   = rawdb.NewMemoryDatabase()
+	)
+	rawdb.WriteVoteHistory(oldDB, key1, voteHistoryOf(vote))
+	rawdb.WriteVoteHistory(oldDB, key2, &types.VoteHistory{SourceNumber: 1, TargetNumber: 2})
+	rawdb.WriteVoteHistory(newDB, key2, &types.VoteHistory{SourceNumber: 5, TargetNumber: 1})
+
+	// Export and import through the JSON format
+	data, err := json.Marshal(ExportSlashingProtection(oldDB, genesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	interchange := new(Interchange)
+	if err := json.Unmarshal(data, interchange); err != nil {
+		t.Fatal(err)
+	}
+	if len(interchange.Data) != 2 || interchange.Data[1].SignedVotes[0].SigningRoot != nil {
+		t.Fatalf("unexpected interchange: %s", data)
+	}
+	imported, err := ImportSlashingProtection(newDB, genesis, interchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 {
+		t.Fatalf("imported %d keys, want 2", imported)
+	}
+	if have, _ := rawdb.ReadVoteHistory(newDB, key1); *have != *voteHistoryOf(vote) {
+		t.Fatalf("history mismatch: have %+v, want %+v", have, voteHistoryOf(vote))
+	}
+	// The imported histories are merged into the existing ones
+	if have, _ := rawdb.ReadVoteHistory(newDB, key2); have.SourceNumber != 5 || have.TargetNumber != 2 {
+		t.Fatalf("history not merged: %+v", have)
+	}
+	// Invalid interchanges are not imported
+	if _, err := ImportSlashingProtection(newDB, common.Hash{0xcc}, interchange); !errors.Is(err, errInterchangeGenesis) {
+		t.Fatalf("have %v, want %v", err, errInterchangeGenesis)
+	}
+	interchange.Metadata.InterchangeFormatVersion = "4"
+	if _, err := ImportSlashingProtection(newDB, genesis, interchange); !errors.Is(err, errInterchangeVersion) {
+		t.Fatalf("have %v, want %v", err, errInterchangeVersion)
+	}
+	invalid := `{"metadata":{"interchange_format_version":"5","genesis_hash":"` + genesis.Hex() + `"},
+		"data":[{"pubkey":"0x01","signed_votes":[{"source_number":"1","target_number":"2"}]}]}`
+	if err := json.Unmarshal([]byte(invalid), interchange); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportSlashingProtection(newDB, genesis, interchange); err == nil {
+		t.Fatal("invalid public key imported")
+	}
+}
@@ -0,0 +1,296 @@
+package vote
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	remoteSignerPublicKeysPath = "/api/v1/eth2/publicKeys"
+	remoteSignerSignPath       = "/api/v1/eth2/sign/"
+
+	// remoteSignerVoteType is the type of the sign requests of the votes. The
+	// votes have no signing domain, so the remote signer is expected to sign
+	// the signing root of the request as is.
+	remoteSignerVoteType = "VOTE"
+
+	maxRemoteSignerResponseSize = 64 * 1024
+)
+
+var (
+	errRemoteSignerNoKey              = errors.New("remote signer has no BLS key")
+	errRemoteSignerKeyNotFound        = errors.New("BLS key not found in the remote signer")
+	errRemoteSignerSlashingProtection = errors.New("remote signer refused to sign: slashing protection")
+	errRemoteSignerInvalidSignature   = errors.New("invalid signature from the remote signer")
+)
+
+// RemoteSignerConfig is the configuration of a RemoteSigner.
+type RemoteSignerConfig struct {
+	URL        string        // Base URL of the signer, e.g. https://signer:9000
+	PubKey     string        // Hex BLS public key to sign with, the first key of the signer if empty
+	CACert     string        // PEM file of the CA certificates verifying the signer, the system ones if empty
+	ClientCert string        // PEM file of the client certificate, no client authentication if empty
+	ClientKey  string        // PEM file of the client private key
+	Timeout    time.Duration // Timeout of the requests, voteSignerTimeout if zero
+}
+
+// RemoteSigner is a VoteSigner with the key in a remote signing service
+// speaking the Web3Signer eth2 API. The returned signatures are verified, and
+// the votes breaking the slashing rules are refused before being sent to the
+// signer.
+type RemoteSigner struct {
+	url     string
+	client  *http.Client
+	timeout time.Duration
+	pubKey  types.BLSPublicKey
+	blsKey  bls.PublicKey
+
+	protection *slashingProtection
+}
+
+// remoteSignRequest is the body of the eth2 sign request of a vote.
+type remoteSignRequest struct {
+	Type        string          `json:"type"`
+	SigningRoot common.Hash     `json:"signingRoot"`
+	Vote        *remoteVoteData `json:"vote"`
+}
+
+type remoteVoteData struct {
+	SourceNumber uint64      `json:"source_number,string"`
+	SourceHash   common.Hash `json:"source_hash"`
+	TargetNumber uint64      `json:"target_number,string"`
+	TargetHash   common.Hash `json:"target_hash"`
+}
+
+type remoteSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// NewRemoteSigner creates a signer with the key in the remote signer. The key
+// is checked to be known by the signer.
+func NewRemoteSigner(config *RemoteSignerConfig) (*RemoteSigner, error) {
+	if config.URL == "" {
+		return nil, errors.New("remote signer URL not specified")
+	}
+	tlsConfig, err := remoteSignerTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = voteSignerTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	signer := &RemoteSigner{
+		url:        strings.TrimRight(config.URL, "/"),
+		client:     &http.Client{Transport: transport, Timeout: timeout},
+		timeout:    timeout,
+		protection: newSlashingProtection(),
+	}
+	keys, err := signer.publicKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the keys of the remote signer: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, errRemoteSignerNoKey
+	}
+	pubKey := keys[0]
+	if config.PubKey != "" {
+		want, err := hexutil.Decode(config.PubKey)
+		if err != nil || len(want) != types.BLSPublicKeyLength {
+			return nil, fmt.Errorf("invalid BLS public key %q", config.PubKey)
+		}
+		copy(pubKey[:], want)
+		found := false
+		for _, key := range keys {
+			if key == pubKey {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", errRemoteSignerKeyNotFound, config.PubKey)
+		}
+	}
+	if signer.blsKey, err = bls.PublicKeyFromBytes(pubKey[:]); err != nil {
+		return nil, fmt.Errorf("invalid BLS public key of the remote signer: %w", err)
+	}
+	signer.pubKey = pubKey
+
+	log.Info("Connected to remote BLS signer", "url", signer.url, "pubkey", hexutil.Encode(pubKey[:]))
+	return signer, nil
+}
+
+func remoteSignerTLSConfig(config *RemoteSignerConfig) (*tls.Config, error) {
+	if config.CACert == "" && config.ClientCert == "" && config.ClientKey == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CACert != "" {
+		pem, err := os.ReadFile(config.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read remote signer CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in %s", config.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.ClientCert != "" || config.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load remote signer client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// publicKeys returns the keys of the signer.
+func (signer *RemoteSigner) publicKeys() ([]types.BLSPublicKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), signer.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signer.url+remoteSignerPublicKeysPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := signer.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	var keys []hexutil.Bytes
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxRemoteSignerResponseSize)).Decode(&keys); err != nil {
+		return nil, err
+	}
+	pubKeys := make([]types.BLSPublicKey, 0, len(keys))
+	for _, key := range keys {
+		if len(key) != types.BLSPublicKeyLength {
+			return nil, fmt.Errorf("invalid BLS public key %s", key)
+		}
+		pubKeys = append(pubKeys, types.BLSPublicKey(key))
+	}
+	return pubKeys, nil
+}
+
+// PubKey implements VoteSigner.
+func (signer *RemoteSigner) PubKey() types.BLSPublicKey {
+	return signer.pubKey
+}
+
+// SignVote implements VoteSigner.
+func (signer *RemoteSigner) SignVote(vote *types.VoteEnvelope) error {
+	signer.protection.mu.Lock()
+	defer signer.protection.mu.Unlock()
+
+	if err := signer.protection.check(vote.Data); err != nil {
+		return err
+	}
+	// Record the vote before the request, the signer may sign it even if the
+	// response is lost or invalid.
+	signer.protection.insert(vote.Data)
+
+	root := vote.Data.Hash()
+	signature, err := signer.sign(&remoteSignRequest{
+		Type:        remoteSignerVoteType,
+		SigningRoot: root,
+		Vote: &remoteVoteData{
+			SourceNumber: vote.Data.SourceNumber,
+			SourceHash:   vote.Data.SourceHash,
+			TargetNumber: vote.Data.TargetNumber,
+			TargetHash:   vote.Data.TargetHash,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	sig, err := bls.SignatureFromBytes(signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRemoteSignerInvalidSignature, err)
+	}
+	if !sig.Verify(signer.blsKey, root[:]) {
+		return errRemoteSignerInvalidSignature
+	}
+
+	vote.VoteAddress = signer.pubKey
+	copy(vote.Signature[:], signature)
+	return nil
+}
+
+// sign sends the sign request, and returns the signature in the response,
+// which is either JSON or plain hex text.
+func (signer *RemoteSigner) sign(request *remoteSignRequest) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signer.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, signer.url+remoteSignerSignPath+hexutil.Encode(signer.pubKey[:]), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	res, err := signer.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxRemoteSignerResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errRemoteSignerKeyNotFound
+	case http.StatusPreconditionFailed:
+		return nil, errRemoteSignerSlashingProtection
+	default:
+		return nil, fmt.Errorf("remote signer returned %s: %s", res.Status, bytes.TrimSpace(data))
+	}
+	var signature hexutil.Bytes
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == "application/json" {
+		var response remoteSignResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("%w: %v", errRemoteSignerInvalidSignature, err)
+		}
+		signature = response.Signature
+	} else if err := signature.UnmarshalText(bytes.TrimSpace(data)); err != nil {
+		return nil, fmt.Errorf("%w: %v", errRemoteSignerInvalidSignature, err)
+	}
+	if len(signature) != types.BLSSignatureLength {
+		return nil, fmt.Errorf("%w: length %d", errRemoteSignerInvalidSignature, len(signature))
+	}
+	return signature, nil
+}
@@ -0,0 +1,238 @@
+package vote
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeRemoteSigner is a signer speaking the Web3Signer eth2 API with a random
+// BLS key.
+type fakeRemoteSigner struct {
+	key      bls.SecretKey
+	requests atomic.Int32
+
+	plainText atomic.Bool  // Respond the signatures in plain text
+	badSig    atomic.Bool  // Respond the signatures of another root
+	refuse    atomic.Bool  // Refuse to sign with slashing protection
+	delay     atomic.Int64 // Delay of the sign responses
+}
+
+func (s *fakeRemoteSigner) pubKey() string {
+	return hexutil.Encode(s.key.PublicKey().Marshal())
+}
+
+func (s *fakeRemoteSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == remoteSignerPublicKeysPath:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]string{s.pubKey()})
+
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, remoteSignerSignPath):
+		s.requests.Add(1)
+		time.Sleep(time.Duration(s.delay.Load()))
+		if strings.TrimPrefix(r.URL.Path, remoteSignerSignPath) != s.pubKey() {
+			http.Error(w, "key not found", http.StatusNotFound)
+			return
+		}
+		if s.refuse.Load() {
+			http.Error(w, "signing operation failed due to slashing protection rules", http.StatusPreconditionFailed)
+			return
+		}
+		var req remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type != remoteSignerVoteType {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		root := req.SigningRoot
+		if s.badSig.Load() {
+			root = common.Hash{1}
+		}
+		signature := hexutil.Encode(s.key.Sign(root[:]).Marshal())
+		if s.plainText.Load() {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(signature))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"signature": signature})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeRemoteSigner(t *testing.T) (*fakeRemoteSigner, *RemoteSignerConfig) {
+	t.Helper()
+
+	key, err := bls.RandKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &fakeRemoteSigner{key: key}
+	server := httptest.NewTLSServer(signer)
+	t.Cleanup(server.Close)
+
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCert, cert, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return signer, &RemoteSignerConfig{URL: server.URL, CACert: caCert, Timeout: time.Second}
+}
+
+func newTestVote(source, target uint64) *types.VoteEnvelope {
+	return &types.VoteEnvelope{
+		Data: &types.VoteData{
+			SourceNumber: source,
+			SourceHash:   common.Hash{byte(source)},
+			TargetNumber: target,
+			TargetHash:   common.Hash{byte(target)},
+		},
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	fake, config := newFakeRemoteSigner(t)
+
+	signer, err := NewRemoteSigner(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := signer.PubKey(); hexutil.Encode(have[:]) != fake.pubKey() {
+		t.Fatalf("pubkey mismatch: have %x, want %s", have, fake.pubKey())
+	}
+	for i, plainText := range []bool{false, true} {
+		fake.plainText.Store(plainText)
+
+		vote := newTestVote(uint64(i), uint64(i+1))
+		if err := signer.SignVote(vote); err != nil {
+			t.Fatalf("plain text %v: %v", plainText, err)
+		}
+		if vote.VoteAddress != signer.PubKey() {
+			t.Fatalf("vote address not set: %x", vote.VoteAddress)
+		}
+		if err := vote.Verify(); err != nil {
+			t.Fatalf("plain text %v: invalid vote: %v", plainText, err)
+		}
+	}
+	// A configured key must be known by the signer
+	config.PubKey = fake.pubKey()
+	if _, err := NewRemoteSigner(config); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := bls.RandKey()
+	config.PubKey = hexutil.Encode(other.PublicKey().Marshal())
+	if _, err := NewRemoteSigner(config); !errors.Is(err, errRemoteSignerKeyNotFound) {
+		t.Fatalf("have %v, want %v", err, errRemoteSignerKeyNotFound)
+	}
+}
+
+func TestRemoteSignerTLS(t *testing.T) {
+	_, config := newFakeRemoteSigner(t)
+
+	// The signer is not trusted without its CA certificate
+	config.CACert = ""
+	if _, err := NewRemoteSigner(config); err == nil {
+		t.Fatal("untrusted signer accepted")
+	}
+	config.CACert = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := NewRemoteSigner(config); err == nil {
+		t.Fatal("missing CA certificate accepted")
+	}
+}
+
+func TestRemoteSignerErrors(t *testing.T) {
+	fake, config := newFakeRemoteSigner(t)
+
+	signer, err := NewRemoteSigner(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.badSig.Store(true)
+	vote := newTestVote(1, 2)
+	if err := signer.SignVote(vote); !errors.Is(err, errRemoteSignerInvalidSignature) {
+		t.Fatalf("have %v, want %v", err, errRemoteSignerInvalidSignature)
+	}
+	if vote.VoteAddress != (types.BLSPublicKey{}) {
+		t.Fatal("vote with invalid signature set")
+	}
+	fake.badSig.Store(false)
+
+	fake.refuse.Store(true)
+	if err := signer.SignVote(vote); !errors.Is(err, errRemoteSignerSlashingProtection) {
+		t.Fatalf("have %v, want %v", err, errRemoteSignerSlashingProtection)
+	}
+	fake.refuse.Store(false)
+
+	fake.delay.Store(int64(2 * time.Second))
+	if err := signer.SignVote(vote); err == nil {
+		t.Fatal("sign request not timed out")
+	}
+	fake.delay.Store(0)
+
+	// The votes sent to the signer are recorded by the slashing protection,
+	// even if the request failed
+	if err := signer.SignVote(newTestVote(0, 2)); !errors.Is(err, errDoubleVote) {
+		t.Fatalf("have %v, want %v", err, errDoubleVote)
+	}
+	if err := signer.SignVote(vote); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoteSignerSlashingProtection(t *testing.T) {
+	fake, config := newFakeRemoteSigner(t)
+
+	signer, err := NewRemoteSigner(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.SignVote(newTestVote(10, 12)); err != nil {
+		t.Fatal(err)
+	}
+	// The same vote is signed again
+	if err := signer.SignVote(newTestVote(10, 12)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		source, target uint64
+		err            error
+	}{
+		{11, 12, errDoubleVote},   // Rule 1
+		{9, 13, errSurroundVote},  // Rule 2, surrounding 10-->12
+		{13, 14, nil},             // Allowed
+		{11, 15, errSurroundVote}, // Rule 2, surrounding 13-->14
+	}
+	requests := fake.requests.Load()
+	for i, tt := range tests {
+		if err := signer.SignVote(newTestVote(tt.source, tt.target)); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: vote %d-->%d: have %v, want %v", i, tt.source, tt.target, err, tt.err)
+		}
+	}
+	// Only the allowed vote is sent to the signer
+	if have := fake.requests.Load() - requests; have != 1 {
+		t.Fatalf("unexpected sign requests: have %d, want 1", have)
+	}
+	// The votes out of the slash scope are dropped
+	if err := signer.SignVote(newTestVote(20+maliciousVoteSlashScope, 21+maliciousVoteSlashScope)); err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.SignVote(newTestVote(9, 12)); err != nil {
+		t.Fatalf("vote out of the slash scope refused: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package vote
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	errDoubleVote   = errors.New("slashing protection: another vote signed for the same target")
+	errSurroundVote = errors.New("slashing protection: vote surrounding or surrounded by a signed vote")
+)
+
+// slashingProtection keeps the votes signed in the slash scope, and refuses to
+// sign the votes that would be slashed together with them:
+// two distinct votes for the same target (Rule 1), or a vote within the span of
+// another vote (Rule 2).
+type slashingProtection struct {
+	mu    sync.Mutex
+	votes map[uint64]*types.VoteData // Signed votes by target number
+}
+
+func newSlashingProtection() *slashingProtection {
+	return &slashingProtection{votes: make(map[uint64]*types.VoteData)}
+}
+
+// check returns an error if signing the vote breaks the slashing rules. A vote
+// already signed is allowed to be signed again. The caller must hold the lock.
+func (p *slashingProtection) check(data *types.VoteData) error {
+	if signed, ok := p.votes[data.TargetNumber]; ok {
+		if signed.Hash() != data.Hash() {
+			return fmt.Errorf("%w: target %d", errDoubleVote, data.TargetNumber)
+		}
+		return nil
+	}
+	for _, signed := range p.votes {
+		if (signed.SourceNumber < data.SourceNumber && data.TargetNumber < signed.TargetNumber) ||
+			(data.SourceNumber < signed.SourceNumber && signed.TargetNumber < data.TargetNumber) {
+			return fmt.Errorf("%w: %d-->%d, signed %d-->%d", errSurroundVote,
+				data.SourceNumber, data.TargetNumber, signed.SourceNumber, signed.TargetNumber)
+		}
+	}
+	return nil
+}
+
+// insert records the signed vote, and drops the votes out of the slash scope.
+// The caller must hold the lock.
+func (p *slashingProtection) insert(data *types.VoteData) {
+	p.votes[data.TargetNumber] = data
+	if data.TargetNumber <= maliciousVoteSlashScope {
+		return
+	}
+	for number := range p.votes {
+		if number < data.TargetNumber-maliciousVoteSlashScope {
+			delete(p.votes, number)
+		}
+	}
+}
+
+var errVoteBelowHistory = errors.New("slashing protection: vote not above the vote history")
+
+// checkVoteHistory returns an error if the vote is not above the vote history
+// of the key: the votes of lower sources or targets than the signed ones could
+// be slashed, and the vote of the highest target may only be signed again.
+func checkVoteHistory(history *types.VoteHistory, data *types.VoteData) error {
+	if history == nil {
+		return nil
+	}
+	if data.SourceNumber < history.SourceNumber || data.TargetNumber < history.TargetNumber ||
+		(data.TargetNumber == history.TargetNumber && data.Hash() != history.SigningRoot) {
+		return fmt.Errorf("%w: %d-->%d, history %d-->%d", errVoteBelowHistory,
+			data.SourceNumber, data.TargetNumber, history.SourceNumber, history.TargetNumber)
+	}
+	return nil
+}
+
+// mergeVoteHistory returns the history covering both histories. The signing
+// root of the highest target is unknown if they have different ones.
+func mergeVoteHistory(history, other *types.VoteHistory) *types.VoteHistory {
+	if history == nil {
+		merged := *other
+		return &merged
+	}
+	merged := *history
+	if other.SourceNumber > merged.SourceNumber {
+		merged.SourceNumber = other.SourceNumber
+	}
+	switch {
+	case other.TargetNumber > merged.TargetNumber:
+		merged.TargetNumber = other.TargetNumber
+		merged.SigningRoot = other.SigningRoot
+	case other.TargetNumber == merged.TargetNumber && other.SigningRoot != merged.SigningRoot:
+		merged.SigningRoot = common.Hash{}
+	}
+	return &merged
+}
+
+// voteHistoryOf returns the history of a single vote.
+func voteHistoryOf(data *types.VoteData) *types.VoteHistory {
+	return &types.VoteHistory{
+		SourceNumber: data.SourceNumber,
+		TargetNumber: data.TargetNumber,
+		SigningRoot:  data.Hash(),
+	}
+}
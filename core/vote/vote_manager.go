@@ -1,7 +1,6 @@
 package vote
 
 import (
-	"bytes"
 	"fmt"
 	"math/big"
 	"time"
@@ -10,8 +9,10 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/parlia"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -26,6 +27,7 @@ var inTurnJustified = metrics.NewRegisteredCounter("votesManager/inTurnJustified
 var notInTurnJustified = metrics.NewRegisteredCounter("votesManager/notInTurnJustified", nil)
 var continuousJustified = metrics.NewRegisteredCounter("votesManager/continuousJustified", nil)
 var notContinuousJustified = metrics.NewRegisteredCounter("votesManager/notContinuousJustified", nil)
+var votesRefusedCounter = metrics.NewRegisteredCounter("votesManager/slashingProtection", nil)
 
 // Backend wraps all methods required for voting.
 type Backend interface {
@@ -47,30 +49,26 @@ type VoteManager struct {
 	syncVoteSub event.Subscription
 
 	pool    *VotePool
-	signer  *VoteSigner
+	signer  VoteSigner
 	journal *VoteJournal
+	db      ethdb.KeyValueStore // Store of the vote histories for slashing protection
 
 	engine consensus.PoSA
 }
 
-func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journalPath, blsPasswordPath, blsWalletPath string, engine consensus.PoSA) (*VoteManager, error) {
+func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journalPath string, signer VoteSigner, db ethdb.KeyValueStore, engine consensus.PoSA) (*VoteManager, error) {
 	voteManager := &VoteManager{
 		eth:                    eth,
 		chain:                  chain,
 		highestVerifiedBlockCh: make(chan core.HighestVerifiedBlockEvent, highestVerifiedBlockChanSize),
 		syncVoteCh:             make(chan core.NewVoteEvent, voteBufferForPut),
 		pool:                   pool,
+		signer:                 signer,
+		db:                     db,
 		engine:                 engine,
 	}
-
-	// Create voteSigner.
-	voteSigner, err := NewVoteSigner(blsPasswordPath, blsWalletPath)
-	if err != nil {
-		return nil, err
-	}
-	log.Info("Create voteSigner successfully")
-	voteManager.signer = voteSigner
-	metrics.GetOrRegisterLabel("miner-info", nil).Mark(map[string]interface{}{"VoteKey": common.Bytes2Hex(voteManager.signer.PubKey[:])})
+	pubKey := signer.PubKey()
+	metrics.GetOrRegisterLabel("miner-info", nil).Mark(map[string]interface{}{"VoteKey": common.Bytes2Hex(pubKey[:])})
 
 	// Create voteJournal
 	voteJournal, err := NewVoteJournal(journalPath)
@@ -79,6 +77,7 @@ func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journal
 	}
 	log.Info("Create voteJournal successfully")
 	voteManager.journal = voteJournal
+	voteManager.loadJournalHistory()
 
 	// Subscribe to chain head event.
 	voteManager.highestVerifiedBlockSub = voteManager.chain.SubscribeHighestVerifiedHeaderEvent(voteManager.highestVerifiedBlockCh)
@@ -159,7 +158,7 @@ func (voteManager *VoteManager) loop() {
 			// Check if cur validator is within the validatorSet at curHead
 			if !voteManager.engine.IsActiveValidatorAt(voteManager.chain, curHead,
 				func(bLSPublicKey *types.BLSPublicKey) bool {
-					return bytes.Equal(voteManager.signer.PubKey[:], bLSPublicKey[:])
+					return voteManager.signer.PubKey() == *bLSPublicKey
 				}) {
 				log.Debug("local validator with voteKey is not within the validatorSet at curHead")
 				continue
@@ -185,6 +184,20 @@ func (voteManager *VoteManager) loop() {
 				voteMessage.Data.SourceNumber = sourceNumber
 				voteMessage.Data.SourceHash = sourceHash
 
+				// Record the vote in the vote history before signing it, so that
+				// the history covers every vote that might have been signed.
+				pubKey := voteManager.signer.PubKey()
+				history, err := rawdb.ReadVoteHistory(voteManager.db, pubKey)
+				if err == nil {
+					err = checkVoteHistory(history, voteMessage.Data)
+				}
+				if err != nil {
+					log.Warn("Refused to sign vote", "err", err, "votedBlockNumber", voteMessage.Data.TargetNumber, "votedBlockHash", voteMessage.Data.TargetHash)
+					votesRefusedCounter.Inc(1)
+					continue
+				}
+				rawdb.WriteVoteHistory(voteManager.db, pubKey, mergeVoteHistory(history, voteHistoryOf(voteMessage.Data)))
+
 				if err := voteManager.signer.SignVote(voteMessage); err != nil {
 					log.Error("Failed to sign vote", "err", err, "votedBlockNumber", voteMessage.Data.TargetNumber, "votedBlockHash", voteMessage.Data.TargetHash, "voteMessageHash", voteMessage.Hash())
 					votesSigningErrorCounter.Inc(1)
@@ -232,7 +245,7 @@ func (voteManager *VoteManager) loop() {
 
 		case event := <-voteManager.syncVoteCh:
 			voteMessage := event.Vote
-			if voteManager.eth.IsMining() || !bytes.Equal(voteManager.signer.PubKey[:], voteMessage.VoteAddress[:]) {
+			if voteManager.eth.IsMining() || voteManager.signer.PubKey() != voteMessage.VoteAddress {
 				continue
 			}
 			if err := voteManager.journal.WriteVote(voteMessage); err != nil {
@@ -240,6 +253,7 @@ func (voteManager *VoteManager) loop() {
 				voteJournalErrorCounter.Inc(1)
 				continue
 			}
+			voteManager.recordVoteHistory(voteMessage.Data)
 			log.Debug("vote manager synced vote", "votedBlockNumber", voteMessage.Data.TargetNumber, "votedBlockHash", voteMessage.Data.TargetHash, "voteMessageHash", voteMessage.Hash())
 			votesManagerCounter.Inc(1)
 		case <-voteManager.syncVoteSub.Err():
@@ -252,6 +266,51 @@ func (voteManager *VoteManager) loop() {
 	}
 }
 
+// recordVoteHistory raises the vote history of the key to the vote. An invalid
+// history is left as is, so that the key keeps refusing to vote.
+func (voteManager *VoteManager) recordVoteHistory(data *types.VoteData) {
+	pubKey := voteManager.signer.PubKey()
+	history, err := rawdb.ReadVoteHistory(voteManager.db, pubKey)
+	if err != nil {
+		log.Error("Failed to record vote history", "err", err)
+		return
+	}
+	rawdb.WriteVoteHistory(voteManager.db, pubKey, mergeVoteHistory(history, voteHistoryOf(data)))
+}
+
+// loadJournalHistory raises the vote history of the key to the votes in the
+// journal, which may have been signed before the history was kept.
+func (voteManager *VoteManager) loadJournalHistory() {
+	pubKey := voteManager.signer.PubKey()
+	history, err := rawdb.ReadVoteHistory(voteManager.db, pubKey)
+	if err != nil {
+		log.Error("Vote history unreadable, votes are refused until it is re-imported", "err", err)
+		return
+	}
+	merged := history
+
+	walLog := voteManager.journal.walLog
+	firstIndex, err := walLog.FirstIndex()
+	if err != nil {
+		return
+	}
+	lastIndex, err := walLog.LastIndex()
+	if err != nil {
+		return
+	}
+	for index := firstIndex; index <= lastIndex && index > 0; index++ {
+		vote, err := voteManager.journal.ReadVote(index)
+		if err != nil || vote == nil || vote.Data == nil || vote.VoteAddress != pubKey {
+			continue
+		}
+		merged = mergeVoteHistory(merged, voteHistoryOf(vote.Data))
+	}
+	if merged != history {
+		rawdb.WriteVoteHistory(voteManager.db, pubKey, merged)
+		log.Info("Loaded vote history from journal", "source", merged.SourceNumber, "target", merged.TargetNumber)
+	}
+}
+
 // UnderRules checks if the produced header under the following rules:
 // A validator must not publish two distinct votes for the same height. (Rule 1)
 // A validator must not vote within the span of its other votes . (Rule 2)
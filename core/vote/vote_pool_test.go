@@ -176,7 +176,11 @@ func testVotePool(t *testing.T, isValidRules bool) {
 	file.Close()
 	os.Remove(journal)
 
-	voteManager, err := NewVoteManager(newTestBackend(), chain, votePool, journal, walletPasswordDir, walletDir, mockEngine)
+	signer, err := NewKeymanagerSigner(walletPasswordDir, walletDir)
+	if err != nil {
+		t.Fatalf("failed to create vote signer: %v", err)
+	}
+	voteManager, err := NewVoteManager(newTestBackend(), chain, votePool, journal, signer, db, mockEngine)
 	if err != nil {
 		t.Fatalf("failed to create vote managers")
 	}
@@ -230,6 +234,13 @@ func testVotePool(t *testing.T, isValidRules bool) {
 		t.Fatalf("journal failed")
 	}
 
+	// Verify the vote history of the slashing protection
+	lastIndex, _ := voteJournal.walLog.LastIndex()
+	lastVote, _ := voteJournal.ReadVote(lastIndex)
+	if history, _ := rawdb.ReadVoteHistory(db, signer.PubKey()); history == nil || *history != *voteHistoryOf(lastVote.Data) {
+		t.Fatalf("vote history not recorded: %+v", history)
+	}
+
 	bs, _ = core.GenerateChain(params.TestChainConfig, bs[len(bs)-1], ethash.NewFaker(), db, 1, nil)
 	if _, err := chain.InsertChain(bs); err != nil {
 		panic(err)
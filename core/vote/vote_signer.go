@@ -24,12 +24,24 @@ const (
 
 var votesSigningErrorCounter = metrics.NewRegisteredCounter("votesSigner/error", nil)
 
-type VoteSigner struct {
+// VoteSigner signs the votes of the validator with its BLS key.
+type VoteSigner interface {
+	// PubKey returns the BLS public key of the validator.
+	PubKey() types.BLSPublicKey
+
+	// SignVote signs the vote data, and sets the vote address and signature
+	// of the vote.
+	SignVote(vote *types.VoteEnvelope) error
+}
+
+// KeymanagerSigner is a VoteSigner with the key in a local prysm BLS wallet.
+type KeymanagerSigner struct {
 	km     *keymanager.IKeymanager
-	PubKey [48]byte
+	pubKey types.BLSPublicKey
 }
 
-func NewVoteSigner(blsPasswordPath, blsWalletPath string) (*VoteSigner, error) {
+// NewKeymanagerSigner opens the BLS wallet, and signs with its first key.
+func NewKeymanagerSigner(blsPasswordPath, blsWalletPath string) (*KeymanagerSigner, error) {
 	dirExists, err := wallet.Exists(blsWalletPath)
 	if err != nil {
 		log.Error("Check BLS wallet exists", "err", err)
@@ -72,15 +84,21 @@ func NewVoteSigner(blsPasswordPath, blsWalletPath string) (*VoteSigner, error) {
 		return nil, errors.Wrap(err, "could not fetch validating public keys")
 	}
 
-	return &VoteSigner{
+	return &KeymanagerSigner{
 		km:     &km,
-		PubKey: pubKeys[0],
+		pubKey: pubKeys[0],
 	}, nil
 }
 
-func (signer *VoteSigner) SignVote(vote *types.VoteEnvelope) error {
+// PubKey implements VoteSigner.
+func (signer *KeymanagerSigner) PubKey() types.BLSPublicKey {
+	return signer.pubKey
+}
+
+// SignVote implements VoteSigner.
+func (signer *KeymanagerSigner) SignVote(vote *types.VoteEnvelope) error {
 	// Sign the vote, fetch the first pubKey as validator's bls public key.
-	pubKey := signer.PubKey
+	pubKey := signer.pubKey
 	blsPubKey, err := bls.PublicKeyFromBytes(pubKey[:])
 	if err != nil {
 		return errors.Wrap(err, "convert public key from bytes to bls failed")
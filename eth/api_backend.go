@@ -498,10 +498,22 @@ func (b *EthAPIBackend) HasBuilder(builder common.Address) bool {
 	return b.Miner().HasBuilder(builder)
 }
 
+func (b *EthAPIBackend) RegisterBuilder(reg *types.BuilderRegistration) error {
+	return b.Miner().RegisterBuilder(reg)
+}
+
 func (b *EthAPIBackend) SendBid(ctx context.Context, bid *types.BidArgs) (common.Hash, error) {
 	return b.Miner().SendBid(ctx, bid)
 }
 
+func (b *EthAPIBackend) SubscribeBidEvent(ch chan<- types.BidEvent) event.Subscription {
+	return b.Miner().SubscribeBidEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeBestBidEvent(ch chan<- types.BidEvent) event.Subscription {
+	return b.Miner().SubscribeBestBidEvent(ch)
+}
+
 func (b *EthAPIBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 	return b.Miner().BestPackedBlockReward(parentHash)
 }
@@ -509,3 +521,15 @@ func (b *EthAPIBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 func (b *EthAPIBackend) MinerInTurn() bool {
 	return b.Miner().InTurn()
 }
+
+func (b *EthAPIBackend) SendBundle(ctx context.Context, bundle *types.Bundle) error {
+	return b.eth.txPool.AddBundle(bundle)
+}
+
+func (b *EthAPIBackend) CancelBundle(ctx context.Context, hash common.Hash, sender common.Address) (bool, error) {
+	return b.eth.txPool.CancelBundle(hash, sender)
+}
+
+func (b *EthAPIBackend) ReportIssue(ctx context.Context, issue *types.BidIssue) error {
+	return b.Miner().ReportIssue(issue)
+}
@@ -17,6 +17,8 @@
 package eth
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -24,6 +26,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
 )
 
 // MinerAPI provides an API to control the miner.
@@ -117,3 +122,75 @@ func (api *MinerAPI) AddBuilder(builder common.Address, url string) error {
 func (api *MinerAPI) RemoveBuilder(builder common.Address) error {
 	return api.e.APIBackend.RemoveBuilder(builder)
 }
+
+// GetBuilders returns the builders in the registry and their status.
+func (api *MinerAPI) GetBuilders() []*types.BuilderRecord {
+	return api.e.Miner().Builders()
+}
+
+// SetBuilderStatus activates, suspends or bans a registered builder. Banned
+// builders stay banned across restarts until activated again.
+func (api *MinerAPI) SetBuilderStatus(builder common.Address, status types.BuilderStatus, reason string) error {
+	return api.e.Miner().SetBuilderStatus(builder, status, reason)
+}
+
+// GetSentryStatus returns the status of the Mev sentries, and which one the
+// calls to the builders went through last.
+func (api *MinerAPI) GetSentryStatus() []miner.SentryStatus {
+	return api.e.Miner().SentryStatus()
+}
+
+// maxBlockSourceRange is the maximum number of blocks aggregated by a single
+// miner_getBlockSourceReport call.
+const maxBlockSourceRange = 10000
+
+// BlockSourceSummary aggregates the source reports of the blocks sealed by the
+// validator over a range of blocks.
+type BlockSourceSummary struct {
+	FromBlock    hexutil.Uint64             `json:"fromBlock"`
+	ToBlock      hexutil.Uint64             `json:"toBlock"`
+	Blocks       hexutil.Uint64             `json:"blocks"`       // Blocks sealed by the validator
+	BidBlocks    hexutil.Uint64             `json:"bidBlocks"`    // Blocks built from a bid
+	LocalReward  *hexutil.Big               `json:"localReward"`  // Total reward of the local blocks
+	SealedReward *hexutil.Big               `json:"sealedReward"` // Total reward of the sealed blocks
+	Delta        *hexutil.Big               `json:"delta"`        // Total reward gained by sealing the bids
+	Reports      []*types.BlockSourceReport `json:"reports"`
+}
+
+// GetBlockSourceReport returns, for the blocks sealed by the validator over
+// the blocks from..to inclusive, the reward of the local block and of the best
+// bid, along with the block sealed. Only the reports of recent blocks are kept.
+func (api *MinerAPI) GetBlockSourceReport(from, to hexutil.Uint64) (*BlockSourceSummary, error) {
+	if from > to {
+		return nil, errors.New("invalid block range")
+	}
+	if to-from >= maxBlockSourceRange {
+		return nil, fmt.Errorf("block range too large, max %d blocks", maxBlockSourceRange)
+	}
+	var (
+		local   = new(big.Int)
+		sealed  = new(big.Int)
+		delta   = new(big.Int)
+		summary = &BlockSourceSummary{FromBlock: from, ToBlock: to, Reports: []*types.BlockSourceReport{}}
+	)
+	for number := uint64(from); number <= uint64(to); number++ {
+		report := rawdb.ReadBlockSourceReport(api.e.ChainDb(), number)
+		if report == nil {
+			continue
+		}
+		summary.Blocks++
+		local.Add(local, report.LocalBlockReward)
+		if report.Source == types.BlockSourceBid {
+			summary.BidBlocks++
+			sealed.Add(sealed, report.BidBlockReward)
+		} else {
+			sealed.Add(sealed, report.LocalBlockReward)
+		}
+		delta.Add(delta, report.Delta)
+		summary.Reports = append(summary.Reports, report)
+	}
+	summary.LocalReward = (*hexutil.Big)(local)
+	summary.SealedReward = (*hexutil.Big)(sealed)
+	summary.Delta = (*hexutil.Big)(delta)
+	return summary, nil
+}
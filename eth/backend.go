@@ -18,6 +18,7 @@
 package eth
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -72,6 +74,8 @@ const (
 	ChainDBNamespace = "eth/db/chaindata/"
 	JournalFileName  = "trie.journal"
 	ChainData        = "chaindata"
+
+	maliciousVoteEvidenceDir = "maliciousvote-evidence" // Directory of the evidence files of the malicious votes
 )
 
 // Config contains the configuration options of the ETH protocol.
@@ -115,6 +119,10 @@ type Ethereum struct {
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
 
 	votePool *vote.VotePool
+
+	doubleSignReporter *monitor.DoubleSignReporter
+	slashSubmitters    map[common.Address]*monitor.SlashSubmitter // Submitters of the slashing evidences by account
+	doubleSignSub      event.Subscription
 }
 
 // New creates a new Ethereum object (including the initialisation of the common Ethereum object),
@@ -309,7 +317,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.PersistDiff {
 		bcOps = append(bcOps, core.EnablePersistDiff(config.DiffBlock))
 	}
-	if stack.Config().EnableDoubleSignMonitor {
+	if stack.Config().EnableDoubleSignMonitor || stack.Config().DoubleSignReporter != (common.Address{}) {
 		bcOps = append(bcOps, core.EnableDoubleSignChecker)
 	}
 
@@ -335,7 +343,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	subpools := []txpool.SubPool{legacyPool, blobPool}
+	if config.BundlePool.Enabled || config.Miner.Bidder.Enabled {
+		subpools = append(subpools, bundlepool.New(config.BundlePool, eth.blockchain))
+	}
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, subpools)
 	if err != nil {
 		return nil, err
 	}
@@ -358,8 +370,17 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
-	eth.miner = miner.New(eth, &config.Miner, eth.EventMux(), eth.engine)
+	if eth.miner, err = miner.New(eth, &config.Miner, eth.EventMux(), eth.engine); err != nil {
+		return nil, err
+	}
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
+	if config.Miner.Bidder.Enabled {
+		wallet, err := eth.accountManager.Find(accounts.Account{Address: config.Miner.Bidder.Account})
+		if err != nil {
+			return nil, fmt.Errorf("builder account %s unavailable: %v", config.Miner.Bidder.Account, err)
+		}
+		eth.miner.AuthorizeBidder(wallet.SignData, wallet.SignTx)
+	}
 
 	// Create voteManager instance
 	if posa, ok := eth.engine.(consensus.PoSA); ok {
@@ -376,17 +397,47 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 		log.Info("Create votePool successfully")
 		eth.handler.votepool = votePool
-		if stack.Config().EnableMaliciousVoteMonitor {
-			eth.handler.maliciousVoteMonitor = monitor.NewMaliciousVoteMonitor()
+		conf := stack.Config()
+		reportVotes := conf.MaliciousVoteReporter != (common.Address{}) || conf.MaliciousVoteDryRun
+		if conf.EnableMaliciousVoteMonitor || reportVotes {
+			eth.handler.maliciousVoteMonitor = monitor.NewMaliciousVoteMonitor(eth.chainDb)
 			log.Info("Create MaliciousVoteMonitor successfully")
 		}
+		if reportVotes {
+			var submitter *monitor.SlashSubmitter
+			if !conf.MaliciousVoteDryRun {
+				if submitter, err = eth.slashSubmitter(conf.MaliciousVoteReporter, chainConfig.ChainID); err != nil {
+					return nil, fmt.Errorf("malicious vote reporter account %s unavailable: %v", conf.MaliciousVoteReporter, err)
+				}
+			}
+			engine := eth.engine.(*parlia.Parlia)
+			eth.handler.maliciousVoteReporter, err = monitor.NewMaliciousVoteReporter(submitter, chainDb, stack.ResolvePath(maliciousVoteEvidenceDir), engine.PackFinalityViolationEvidence)
+			if err != nil {
+				return nil, err
+			}
+			log.Info("Create MaliciousVoteReporter successfully", "account", conf.MaliciousVoteReporter, "dryrun", conf.MaliciousVoteDryRun)
+		}
 
 		if config.Miner.VoteEnable {
-			conf := stack.Config()
-			blsPasswordPath := stack.ResolvePath(conf.BLSPasswordFile)
-			blsWalletPath := stack.ResolvePath(conf.BLSWalletDir)
+			var signer vote.VoteSigner
+			if conf.BLSRemoteSigner != "" {
+				signer, err = vote.NewRemoteSigner(&vote.RemoteSignerConfig{
+					URL:        conf.BLSRemoteSigner,
+					PubKey:     conf.BLSRemoteSignerPubKey,
+					CACert:     conf.BLSRemoteSignerCACert,
+					ClientCert: conf.BLSRemoteSignerClientCert,
+					ClientKey:  conf.BLSRemoteSignerClientKey,
+					Timeout:    conf.BLSRemoteSignerTimeout,
+				})
+			} else {
+				signer, err = vote.NewKeymanagerSigner(stack.ResolvePath(conf.BLSPasswordFile), stack.ResolvePath(conf.BLSWalletDir))
+			}
+			if err != nil {
+				log.Error("Failed to create vote signer", "err", err)
+				return nil, err
+			}
 			voteJournalPath := stack.ResolvePath(conf.VoteJournalDir)
-			if _, err := vote.NewVoteManager(eth, eth.blockchain, votePool, voteJournalPath, blsPasswordPath, blsWalletPath, posa); err != nil {
+			if _, err := vote.NewVoteManager(eth, eth.blockchain, votePool, voteJournalPath, signer, chainDb, posa); err != nil {
 				log.Error("Failed to Initialize voteManager", "err", err)
 				return nil, err
 			}
@@ -395,6 +446,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, config.GPO, config.Miner.GasPrice)
 
+	if reporter := stack.Config().DoubleSignReporter; reporter != (common.Address{}) {
+		posa, ok := eth.engine.(*parlia.Parlia)
+		if !ok {
+			return nil, errors.New("double sign reporter requires the parlia engine")
+		}
+		submitter, err := eth.slashSubmitter(reporter, chainConfig.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("double sign reporter account %s unavailable: %v", reporter, err)
+		}
+		eth.doubleSignReporter = monitor.NewDoubleSignReporter(submitter, chainDb, posa.PackDoubleSignEvidence)
+		log.Info("Create DoubleSignReporter successfully", "account", reporter)
+	}
+
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, networkID)
 
@@ -581,9 +645,64 @@ func (s *Ethereum) Start() error {
 
 	// Start the networking layer
 	s.handler.Start(s.p2pServer.MaxPeers, s.p2pServer.MaxPeersPerIP)
+
+	if s.doubleSignReporter != nil {
+		events := make(chan core.DoubleSignEvent, monitor.MaxCacheHeader)
+		s.doubleSignSub = s.blockchain.SubscribeDoubleSignEvent(events)
+		go s.doubleSignReportLoop(events)
+	}
 	return nil
 }
 
+// slashSubmitter returns the submitter of the slashing evidences signing the
+// txs with the given unlocked account. The reporters using the same account
+// share its submitter.
+func (s *Ethereum) slashSubmitter(account common.Address, chainID *big.Int) (*monitor.SlashSubmitter, error) {
+	if submitter := s.slashSubmitters[account]; submitter != nil {
+		return submitter, nil
+	}
+	wallet, err := s.accountManager.Find(accounts.Account{Address: account})
+	if err != nil {
+		return nil, err
+	}
+	submitter := &monitor.SlashSubmitter{
+		Account: account,
+		ChainID: chainID,
+		SignTx:  wallet.SignTx,
+		TxPool:  s.txPool,
+		GasPrice: func() *big.Int {
+			tip, err := s.APIBackend.SuggestGasTipCap(context.Background())
+			if err != nil {
+				return new(big.Int).Set(s.config.Miner.GasPrice)
+			}
+			return tip
+		},
+	}
+	if s.slashSubmitters == nil {
+		s.slashSubmitters = make(map[common.Address]*monitor.SlashSubmitter)
+	}
+	s.slashSubmitters[account] = submitter
+	return submitter, nil
+}
+
+// doubleSignReportLoop submits the evidences of the double signs found by the
+// double sign monitor.
+func (s *Ethereum) doubleSignReportLoop(events chan core.DoubleSignEvent) {
+	for {
+		select {
+		case ev := <-events:
+			hash, err := s.doubleSignReporter.Report(ev.Header1, ev.Header2)
+			if errors.Is(err, monitor.ErrDoubleSignReported) {
+				log.Debug("Double sign already reported", "validator", ev.Header1.Coinbase, "number", ev.Header1.Number, "tx", hash)
+			} else if err != nil {
+				log.Error("Failed to report double sign", "validator", ev.Header1.Coinbase, "number", ev.Header1.Number, "err", err)
+			}
+		case <-s.doubleSignSub.Err():
+			return
+		}
+	}
+}
+
 func (s *Ethereum) setupDiscovery() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
@@ -647,6 +766,9 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.doubleSignSub != nil {
+		s.doubleSignSub.Unsubscribe()
+	}
 	s.txPool.Close()
 	s.miner.Close()
 	s.blockchain.Stop()
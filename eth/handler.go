@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
@@ -57,6 +58,10 @@ const (
 	// voteChanSize is the size of channel listening to NewVotesEvent.
 	voteChanSize = 256
 
+	// maliciousVoteReportChanSize is the size of the queue of the malicious
+	// votes waiting to be reported.
+	maliciousVoteReportChanSize = 64
+
 	// deltaTdThreshold is the threshold of TD difference for peers to broadcast votes.
 	deltaTdThreshold = 20
 
@@ -139,15 +144,17 @@ type handler struct {
 	acceptTxs       atomic.Bool
 	directBroadcast bool
 
-	database             ethdb.Database
-	txpool               txPool
-	votepool             votePool
-	maliciousVoteMonitor *monitor.MaliciousVoteMonitor
-	chain                *core.BlockChain
-	maxPeers             int
-	maxPeersPerIP        int
-	peersPerIP           map[string]int
-	peerPerIPLock        sync.Mutex
+	database              ethdb.Database
+	txpool                txPool
+	votepool              votePool
+	maliciousVoteMonitor  *monitor.MaliciousVoteMonitor
+	maliciousVoteReporter *monitor.MaliciousVoteReporter
+	maliciousVoteReportCh chan maliciousVoteReport
+	chain                 *core.BlockChain
+	maxPeers              int
+	maxPeersPerIP         int
+	peersPerIP            map[string]int
+	peerPerIPLock         sync.Mutex
 
 	downloader   *downloader.Downloader
 	blockFetcher *fetcher.BlockFetcher
@@ -669,6 +676,11 @@ func (h *handler) Start(maxPeers int, maxPeersPerIP int) {
 		go h.voteBroadcastLoop()
 
 		if h.maliciousVoteMonitor != nil {
+			if h.maliciousVoteReporter != nil {
+				h.wg.Add(1)
+				h.maliciousVoteReportCh = make(chan maliciousVoteReport, maliciousVoteReportChanSize)
+				go h.maliciousVoteReportLoop()
+			}
 			h.wg.Add(1)
 			go h.startMaliciousVoteMonitor()
 		}
@@ -702,7 +714,16 @@ func (h *handler) startMaliciousVoteMonitor() {
 		select {
 		case event := <-voteCh:
 			pendingBlockNumber := h.chain.CurrentHeader().Number.Uint64() + 1
-			h.maliciousVoteMonitor.ConflictDetect(event.Vote, pendingBlockNumber)
+			vote := h.maliciousVoteMonitor.ConflictingVote(event.Vote, pendingBlockNumber)
+			if vote == nil || h.maliciousVoteReportCh == nil {
+				continue
+			}
+			// The submission signs and sends a tx, don't hold up the votes
+			select {
+			case h.maliciousVoteReportCh <- maliciousVoteReport{vote, event.Vote}:
+			default:
+				log.Warn("Malicious vote report queue full, dropping", "voteAddress", hexutil.Encode(event.Vote.VoteAddress[:]), "number", event.Vote.Data.TargetNumber)
+			}
 		case <-h.voteMonitorSub.Err():
 			return
 		case <-h.stopCh:
@@ -711,6 +732,28 @@ func (h *handler) startMaliciousVoteMonitor() {
 	}
 }
 
+// maliciousVoteReport is a pair of conflicting votes, vote2 being the last one.
+type maliciousVoteReport struct {
+	vote1, vote2 *types.VoteEnvelope
+}
+
+// maliciousVoteReportLoop submits the evidences of the malicious votes found by
+// the malicious vote monitor.
+func (h *handler) maliciousVoteReportLoop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case report := <-h.maliciousVoteReportCh:
+			vote := report.vote2
+			if _, err := h.maliciousVoteReporter.Report(report.vote1, vote); err != nil && !errors.Is(err, monitor.ErrMaliciousVoteReported) {
+				log.Error("Failed to report malicious vote", "voteAddress", hexutil.Encode(vote.VoteAddress[:]), "number", vote.Data.TargetNumber, "err", err)
+			}
+		case <-h.stopCh:
+			return
+		}
+	}
+}
+
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.reannoTxsSub.Unsubscribe()  // quits txReannounceLoop
@@ -0,0 +1,210 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BundleAPI offers the methods to send, simulate and cancel bundles of
+// transactions included in a block atomically.
+type BundleAPI struct {
+	b Backend
+}
+
+// NewBundleAPI creates a new BundleAPI.
+func NewBundleAPI(b Backend) *BundleAPI {
+	return &BundleAPI{b}
+}
+
+// SendBundle adds a bundle to the bundle pool, and returns its hash.
+func (s *BundleAPI) SendBundle(ctx context.Context, args types.SendBundleArgs) (common.Hash, error) {
+	bundle, err := args.ToBundle(types.LatestSigner(s.b.ChainConfig()))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.b.SendBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
+
+// CancelBundle drops a bundle from the bundle pool, it returns whether the
+// bundle was pending. The cancellation must be signed by the sender of one of
+// the bundle transactions.
+func (s *BundleAPI) CancelBundle(ctx context.Context, args types.CancelBundleArgs) (bool, error) {
+	sender, err := args.EcrecoverSender()
+	if err != nil {
+		return false, err
+	}
+	return s.b.CancelBundle(ctx, args.BundleHash, sender)
+}
+
+// CallBundleArgs represents the arguments of eth_callBundle.
+type CallBundleArgs struct {
+	Txs []hexutil.Bytes `json:"txs"`
+	// BlockNumber is the number of the simulated block, zero meaning the
+	// block after the state block.
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// StateBlockNumber is the block the bundle is simulated on top of,
+	// latest if not set.
+	StateBlockNumber *rpc.BlockNumberOrHash `json:"stateBlockNumber"`
+	Coinbase         *common.Address        `json:"coinbase"`
+	Timestamp        *hexutil.Uint64        `json:"timestamp"`
+}
+
+// CallBundleTxResult is the result of a transaction of a simulated bundle.
+type CallBundleTxResult struct {
+	TxHash   common.Hash     `json:"txHash"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	GasUsed  hexutil.Uint64  `json:"gasUsed"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	GasFees  *hexutil.Big    `json:"gasFees"`
+	Value    hexutil.Bytes   `json:"value,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// CallBundleResult is the result of eth_callBundle.
+type CallBundleResult struct {
+	BundleHash       common.Hash          `json:"bundleHash"`
+	BundleGasPrice   *hexutil.Big         `json:"bundleGasPrice"`
+	GasFees          *hexutil.Big         `json:"gasFees"`
+	TotalGasUsed     hexutil.Uint64       `json:"totalGasUsed"`
+	StateBlockNumber hexutil.Uint64       `json:"stateBlockNumber"`
+	Results          []CallBundleTxResult `json:"results"`
+}
+
+// CallBundle simulates a bundle on top of the state block and returns the
+// result and the fees of each of its transactions. Failing transactions are
+// reported in their results, the simulation only fails if a transaction is
+// invalid.
+func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	bundle, err := (&types.SendBundleArgs{Txs: args.Txs}).ToBundle(types.LatestSigner(s.b.ChainConfig()))
+	if err != nil {
+		return nil, err
+	}
+	stateBlock := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if args.StateBlockNumber != nil {
+		stateBlock = *args.StateBlockNumber
+	}
+	state, parent, err := s.b.StateAndHeaderByNumberOrHash(ctx, stateBlock)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	header := s.bundleHeader(parent, &args)
+
+	timeout := s.b.RPCEVMTimeout()
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		config   = s.b.ChainConfig()
+		signer   = types.MakeSigner(config, header.Number, header.Time)
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, s.b), nil)
+		evm      = s.b.GetEVM(ctx, state, header, &vm.Config{}, &blockCtx)
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+		result   = &CallBundleResult{
+			BundleHash:       bundle.Hash(),
+			StateBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+			Results:          make([]CallBundleTxResult, 0, len(bundle.Txs)),
+		}
+		fees = new(big.Int)
+	)
+	for i, tx := range bundle.Txs {
+		msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, err
+		}
+		state.SetTxContext(tx.Hash(), i)
+		res, err := applyMessageWithEVM(ctx, evm, msg, timeout, gp)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.Error(); err != nil {
+			return nil, err
+		}
+		state.Finalise(true)
+
+		price, err := tx.EffectiveGasTip(header.BaseFee)
+		if err != nil {
+			return nil, err
+		}
+		txFees := new(big.Int).Mul(price, new(big.Int).SetUint64(res.UsedGas))
+		txResult := CallBundleTxResult{
+			TxHash:   tx.Hash(),
+			From:     msg.From,
+			To:       tx.To(),
+			GasUsed:  hexutil.Uint64(res.UsedGas),
+			GasPrice: (*hexutil.Big)(price),
+			GasFees:  (*hexutil.Big)(txFees),
+		}
+		if res.Err != nil {
+			txResult.Error = res.Err.Error()
+			if errors.Is(res.Err, vm.ErrExecutionReverted) && len(res.Revert()) > 0 {
+				txResult.Error = newRevertError(res.Revert()).Error()
+			}
+		} else {
+			txResult.Value = res.Return()
+		}
+		result.Results = append(result.Results, txResult)
+		result.TotalGasUsed += hexutil.Uint64(res.UsedGas)
+		fees.Add(fees, txFees)
+	}
+	result.GasFees = (*hexutil.Big)(fees)
+	result.BundleGasPrice = (*hexutil.Big)(new(big.Int))
+	if result.TotalGasUsed > 0 {
+		result.BundleGasPrice = (*hexutil.Big)(new(big.Int).Div(fees, new(big.Int).SetUint64(uint64(result.TotalGasUsed))))
+	}
+	return result, nil
+}
+
+// bundleHeader returns the header of the block a bundle is simulated in on top
+// of parent.
+func (s *BundleAPI) bundleHeader(parent *types.Header, args *CallBundleArgs) *types.Header {
+	config := s.b.ChainConfig()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Coinbase:   parent.Coinbase,
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 1,
+		Difficulty: parent.Difficulty,
+	}
+	if args.BlockNumber != 0 {
+		header.Number = new(big.Int).SetUint64(uint64(args.BlockNumber))
+	}
+	if args.Coinbase != nil {
+		header.Coinbase = *args.Coinbase
+	}
+	if args.Timestamp != nil {
+		header.Time = uint64(*args.Timestamp)
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if config.IsCancun(header.Number, header.Time) {
+		var excess uint64
+		if config.IsCancun(parent.Number, parent.Time) && parent.ExcessBlobGas != nil && parent.BlobGasUsed != nil {
+			excess = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		} else {
+			excess = eip4844.CalcExcessBlobGas(0, 0)
+		}
+		header.ExcessBlobGas = &excess
+	}
+	return header
+}
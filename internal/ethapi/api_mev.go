@@ -2,12 +2,19 @@ package ethapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/gopool"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // MevAPI implements the interfaces that defined in the BEP-322.
@@ -91,7 +98,157 @@ func (m *MevAPI) HasBuilder(builder common.Address) bool {
 	return m.b.HasBuilder(builder)
 }
 
+// RegisterBuilder registers the builder signing the registration, if it is in
+// the allowlist of the validator.
+func (m *MevAPI) RegisterBuilder(_ context.Context, reg types.BuilderRegistration) error {
+	return m.b.RegisterBuilder(&reg)
+}
+
+// ReportIssue receives the issue a validator reports about a bid the node
+// sent in builder mode. Only the validators a bid was sent to can report an
+// issue about it, once.
+func (m *MevAPI) ReportIssue(ctx context.Context, issue types.BidIssue) error {
+	return m.b.ReportIssue(ctx, &issue)
+}
+
+// bidEventChanSize is the size of the channel of the bid subscriptions.
+const bidEventChanSize = 256
+
+// Bids creates a subscription notified of each bid accepted, rejected or
+// promoted to the best bid of its block, along with its rewards.
+func (m *MevAPI) Bids(ctx context.Context) (*rpc.Subscription, error) {
+	return m.subscribeBids(ctx, m.b.SubscribeBidEvent)
+}
+
+// BestBid creates a subscription notified each time a bid becomes the best bid
+// of its block.
+func (m *MevAPI) BestBid(ctx context.Context) (*rpc.Subscription, error) {
+	return m.subscribeBids(ctx, m.b.SubscribeBestBidEvent)
+}
+
+func (m *MevAPI) subscribeBids(ctx context.Context, subscribe func(chan<- types.BidEvent) event.Subscription) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	gopool.Submit(func() {
+		bids := make(chan types.BidEvent, bidEventChanSize)
+		bidSub := subscribe(bids)
+		defer bidSub.Unsubscribe()
+
+		// The notifications are sent by another routine so that a slow
+		// subscriber doesn't hold the feed back, the events are dropped once
+		// it falls bidEventChanSize events behind.
+		pending := make(chan types.BidEvent, bidEventChanSize)
+		defer close(pending)
+		go func() {
+			for bid := range pending {
+				notifier.Notify(rpcSub.ID, bid)
+			}
+		}()
+
+		for {
+			select {
+			case bid := <-bids:
+				select {
+				case pending <- bid:
+				default:
+					log.Debug("Dropping bid event of slow subscriber", "id", rpcSub.ID, "bidHash", bid.BidHash)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-bidSub.Err():
+				return
+			}
+		}
+	})
+
+	return rpcSub, nil
+}
+
 // Running returns true if mev is running
 func (m *MevAPI) Running() bool {
 	return m.b.MevRunning()
 }
+
+// maxBuilderStatsRange is the maximum number of blocks aggregated by a single
+// mev_getBuilderStats call.
+const maxBuilderStatsRange = 10000
+
+// BuilderStats aggregates the bids a builder sent over a range of blocks.
+type BuilderStats struct {
+	Builder   common.Address `json:"builder"`
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+	Blocks    hexutil.Uint64 `json:"blocks"`    // Blocks the builder bid for
+	Bids      hexutil.Uint64 `json:"bids"`      // Bids received
+	Accepted  hexutil.Uint64 `json:"accepted"`  // Bids admitted for simulation
+	Simulated hexutil.Uint64 `json:"simulated"` // Bids simulated to the end
+	Failed    hexutil.Uint64 `json:"failed"`    // Bids rejected or failing the simulation
+	Best      hexutil.Uint64 `json:"best"`      // Bids that were the best of their block
+	Won       hexutil.Uint64 `json:"won"`       // Bids the sealed block was built from
+	GasFee    *hexutil.Big   `json:"gasFee"`    // Total gas fee offered
+	Reward    *hexutil.Big   `json:"reward"`    // Total validator reward of the won bids
+}
+
+// GetBidHistory returns the bids received for the given block, in arrival
+// order. Only the bids of recent blocks are kept.
+func (m *MevAPI) GetBidHistory(_ context.Context, blockNumber hexutil.Uint64) []*types.BidRecord {
+	return rawdb.ReadBidHistory(m.b.ChainDb(), uint64(blockNumber))
+}
+
+// GetBuilderStats aggregates the bid history of the builder over the blocks
+// from..to, inclusive.
+func (m *MevAPI) GetBuilderStats(_ context.Context, builder common.Address, from, to hexutil.Uint64) (*BuilderStats, error) {
+	if from > to {
+		return nil, errors.New("invalid block range")
+	}
+	if to-from >= maxBuilderStatsRange {
+		return nil, fmt.Errorf("block range too large, max %d blocks", maxBuilderStatsRange)
+	}
+	var (
+		gasFee = new(big.Int)
+		reward = new(big.Int)
+		stats  = &BuilderStats{Builder: builder, FromBlock: from, ToBlock: to}
+	)
+	for number := uint64(from); number <= uint64(to); number++ {
+		var bid bool
+		for _, record := range rawdb.ReadBidHistory(m.b.ChainDb(), number) {
+			if record.Builder != builder {
+				continue
+			}
+			bid = true
+			stats.Bids++
+			if record.Accepted {
+				stats.Accepted++
+			}
+			if record.Simulated {
+				stats.Simulated++
+			}
+			if record.Error != "" {
+				stats.Failed++
+			}
+			if record.Best {
+				stats.Best++
+			}
+			if record.GasFee != nil {
+				gasFee.Add(gasFee, record.GasFee)
+			}
+			if record.Won {
+				stats.Won++
+				if record.PackedValidatorReward != nil {
+					reward.Add(reward, record.PackedValidatorReward)
+				}
+			}
+		}
+		if bid {
+			stats.Blocks++
+		}
+	}
+	stats.GasFee = (*hexutil.Big)(gasFee)
+	stats.Reward = (*hexutil.Big)(reward)
+	return stats, nil
+}
@@ -664,7 +664,15 @@ func (b *testBackend) RemoveBuilder(builder common.Address) error
 func (b *testBackend) SendBid(ctx context.Context, bid *types.BidArgs) (common.Hash, error) {
 	panic("implement me")
 }
-func (b *testBackend) MinerInTurn() bool { return false }
+func (b *testBackend) MinerInTurn() bool                                          { return false }
+func (b *testBackend) SendBundle(ctx context.Context, bundle *types.Bundle) error { return nil }
+func (b *testBackend) CancelBundle(ctx context.Context, hash common.Hash, sender common.Address) (bool, error) {
+	return false, nil
+}
+func (b *testBackend) ReportIssue(ctx context.Context, issue *types.BidIssue) error      { return nil }
+func (b *testBackend) SubscribeBidEvent(ch chan<- types.BidEvent) event.Subscription     { return nil }
+func (b *testBackend) SubscribeBestBidEvent(ch chan<- types.BidEvent) event.Subscription { return nil }
+func (b *testBackend) RegisterBuilder(reg *types.BuilderRegistration) error              { return nil }
 func (b *testBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 	//TODO implement me
 	panic("implement me")
@@ -3758,3 +3766,59 @@ func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc s
 func addressToHash(a common.Address) common.Hash {
 	return common.BytesToHash(a.Bytes())
 }
+
+func TestCallBundle(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		reverter = common.HexToAddress("0x0000000000000000000000000000000000000bad")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// PUSH1 0 PUSH1 0 REVERT
+				reverter: {Code: common.FromHex("0x60006000fd")},
+			},
+		}
+		signer = types.LatestSigner(params.TestChainConfig)
+	)
+	api := NewBundleAPI(newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {}))
+
+	newTx := func(nonce uint64, to common.Address) hexutil.Bytes {
+		tx := types.MustSignNewTx(accounts[0].key, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       100000,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+		})
+		data, _ := tx.MarshalBinary()
+		return data
+	}
+	result, err := api.CallBundle(context.Background(), CallBundleArgs{
+		Txs: []hexutil.Bytes{newTx(0, accounts[1].addr), newTx(1, reverter)},
+	})
+	if err != nil {
+		t.Fatalf("failed to call bundle: %v", err)
+	}
+	if len(result.Results) != 2 || result.StateBlockNumber != 1 {
+		t.Fatalf("unexpected bundle result %+v", result)
+	}
+	if res := result.Results[0]; res.Error != "" || res.GasUsed != hexutil.Uint64(params.TxGas) || res.From != accounts[0].addr {
+		t.Fatalf("unexpected transfer result %+v", res)
+	}
+	if res := result.Results[1]; !strings.Contains(res.Error, vm.ErrExecutionReverted.Error()) {
+		t.Fatalf("revert not reported: %+v", res)
+	}
+	wantFees := new(big.Int).Mul(big.NewInt(params.GWei), new(big.Int).SetUint64(uint64(result.TotalGasUsed)))
+	if result.GasFees.ToInt().Cmp(wantFees) != 0 || result.BundleGasPrice.ToInt().Cmp(big.NewInt(params.GWei)) != 0 {
+		t.Fatalf("bundle fees mismatch: have %v at %v, want %v", result.GasFees, result.BundleGasPrice, wantFees)
+	}
+
+	// Invalid transactions fail the whole simulation
+	if _, err := api.CallBundle(context.Background(), CallBundleArgs{Txs: []hexutil.Bytes{newTx(1, accounts[1].addr)}}); err == nil {
+		t.Fatal("bundle with nonce gap simulated")
+	}
+}
@@ -118,12 +118,25 @@ type Backend interface {
 	RemoveBuilder(builder common.Address) error
 	// HasBuilder returns true if the builder is in the builder list.
 	HasBuilder(builder common.Address) bool
+	// RegisterBuilder registers a builder with its signed registration.
+	RegisterBuilder(reg *types.BuilderRegistration) error
 	// SendBid receives bid from the builders.
 	SendBid(ctx context.Context, bid *types.BidArgs) (common.Hash, error)
+	// SubscribeBidEvent subscribes to the accepted, rejected and promoted bids.
+	SubscribeBidEvent(ch chan<- types.BidEvent) event.Subscription
+	// SubscribeBestBidEvent subscribes to the bids becoming the best bid of their block.
+	SubscribeBestBidEvent(ch chan<- types.BidEvent) event.Subscription
 	// BestBidGasFee returns the gas fee of the best bid for the given parent hash.
 	BestBidGasFee(parentHash common.Hash) *big.Int
 	// MinerInTurn returns true if the validator is in turn to propose the block.
 	MinerInTurn() bool
+	// SendBundle adds a bundle to the bundle pool.
+	SendBundle(ctx context.Context, bundle *types.Bundle) error
+	// CancelBundle drops a bundle from the bundle pool on behalf of one of its
+	// senders.
+	CancelBundle(ctx context.Context, hash common.Hash, sender common.Address) (bool, error)
+	// ReportIssue receives the issue reported by a validator about a bid.
+	ReportIssue(ctx context.Context, issue *types.BidIssue) error
 }
 
 func GetAPIs(apiBackend Backend) []rpc.API {
@@ -150,6 +163,9 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		}, {
 			Namespace: "mev",
 			Service:   NewMevAPI(apiBackend),
+		}, {
+			Namespace: "eth",
+			Service:   NewBundleAPI(apiBackend),
 		},
 	}
 }
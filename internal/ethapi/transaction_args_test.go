@@ -428,7 +428,15 @@ func (b *backendMock) RemoveBuilder(builder common.Address) error
 func (b *backendMock) SendBid(ctx context.Context, bid *types.BidArgs) (common.Hash, error) {
 	panic("implement me")
 }
-func (b *backendMock) MinerInTurn() bool { return false }
+func (b *backendMock) MinerInTurn() bool                                          { return false }
+func (b *backendMock) SendBundle(ctx context.Context, bundle *types.Bundle) error { return nil }
+func (b *backendMock) CancelBundle(ctx context.Context, hash common.Hash, sender common.Address) (bool, error) {
+	return false, nil
+}
+func (b *backendMock) ReportIssue(ctx context.Context, issue *types.BidIssue) error      { return nil }
+func (b *backendMock) SubscribeBidEvent(ch chan<- types.BidEvent) event.Subscription     { return nil }
+func (b *backendMock) SubscribeBestBidEvent(ch chan<- types.BidEvent) event.Subscription { return nil }
+func (b *backendMock) RegisterBuilder(reg *types.BuilderRegistration) error              { return nil }
 func (b *backendMock) BestBidGasFee(parentHash common.Hash) *big.Int {
 	panic("implement me")
 }
@@ -41,6 +41,7 @@ const (
 	FastNodeCategory     = "FAST NODE"
 	FastFinalityCategory = "FAST FINALITY"
 	BlockHistoryCategory = "BLOCK HISTORY MANAGEMENT"
+	QuantCategory        = "QUANT"
 )
 
 func init() {
@@ -658,6 +658,26 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getBuilders',
+			call: 'miner_getBuilders',
+		}),
+		new web3._extend.Method({
+			name: 'getSentryStatus',
+			call: 'miner_getSentryStatus',
+		}),
+		new web3._extend.Method({
+			name: 'getBlockSourceReport',
+			call: 'miner_getBlockSourceReport',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setBuilderStatus',
+			call: 'miner_setBuilderStatus',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'setEtherbase',
 			call: 'miner_setEtherbase',
@@ -0,0 +1,129 @@
+package miner
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// bidLedger collects the bids received for the blocks not sealed yet and
+// persists them once the chain moves past them. Only the records of the last
+// limit blocks are kept in the database.
+type bidLedger struct {
+	db    ethdb.KeyValueStore
+	limit uint64
+
+	mu      sync.Mutex
+	blocks  map[uint64][]*types.BidRecord    // blockNumber -> records in arrival order
+	records map[common.Hash]*types.BidRecord // bidHash -> record
+}
+
+func newBidLedger(db ethdb.KeyValueStore, limit uint64) *bidLedger {
+	return &bidLedger{
+		db:      db,
+		limit:   limit,
+		blocks:  make(map[uint64][]*types.BidRecord),
+		records: make(map[common.Hash]*types.BidRecord),
+	}
+}
+
+// received records a bid sent by a builder. A nil error means the bid was
+// admitted for simulation.
+func (l *bidLedger) received(bid *types.Bid, runtime *BidRuntime, err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record, ok := l.records[bid.Hash()]
+	if !ok {
+		record = &types.BidRecord{
+			Builder:     bid.Builder,
+			BidHash:     bid.Hash(),
+			BlockNumber: bid.BlockNumber,
+			ParentHash:  bid.ParentHash,
+			Txs:         len(bid.Txs),
+			GasUsed:     bid.GasUsed,
+			GasFee:      bid.GasFee,
+			BuilderFee:  bid.BuilderFee,
+			ReceivedAt:  uint64(time.Now().UnixMilli()),
+		}
+		l.records[record.BidHash] = record
+		l.blocks[record.BlockNumber] = append(l.blocks[record.BlockNumber], record)
+	}
+	if runtime != nil {
+		record.ExpectedBlockReward = runtime.expectedBlockReward
+		record.ExpectedValidatorReward = runtime.expectedValidatorReward
+	}
+	record.Accepted = err == nil
+	record.Error = errString(err)
+}
+
+// simulated records the outcome of the simulation of a bid, completed being
+// whether the simulated block was compared against the best bid.
+func (l *bidLedger) simulated(bid *BidRuntime, duration time.Duration, completed bool, err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record, ok := l.records[bid.bid.Hash()]
+	if !ok {
+		return
+	}
+	record.Simulated = completed
+	record.SimDuration = uint64(duration.Milliseconds())
+	record.PackedBlockReward = new(big.Int).Set(bid.packedBlockReward)
+	record.PackedValidatorReward = new(big.Int).Set(bid.packedValidatorReward)
+	record.Error = errString(err)
+}
+
+// flush persists the records of the blocks up to number, marking best as the
+// best bid of the block number. won reports whether the block was built from
+// it.
+func (l *bidLedger) flush(number uint64, best *BidRuntime, won bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	batch := l.db.NewBatch()
+	for n, records := range l.blocks {
+		if n > number {
+			continue
+		}
+		for _, record := range records {
+			if n == number && best != nil && record.BidHash == best.bid.Hash() {
+				record.Best = true
+				record.Won = won
+			}
+			delete(l.records, record.BidHash)
+		}
+		delete(l.blocks, n)
+
+		// Append to the records of the block persisted before, if any
+		if stored := rawdb.ReadBidHistory(l.db, n); len(stored) > 0 {
+			records = append(stored, records...)
+		}
+		rawdb.WriteBidHistory(batch, n, records)
+	}
+	if number > l.limit {
+		rawdb.DeleteBidHistoryBelow(l.db, batch, number-l.limit+1)
+	}
+	batch.Write()
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
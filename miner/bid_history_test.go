@@ -0,0 +1,75 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestBidLedger(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		ledger = newBidLedger(db, 2)
+		best   = newTestBidRuntime(t, 300)
+		failed = newTestBidRuntime(t, 200)
+	)
+	ledger.received(best.bid, best, nil)
+	ledger.received(failed.bid, failed, nil)
+	ledger.received(newTestBidRuntime(t, 100).bid, nil, errors.New("rejected"))
+
+	best.packedBlockReward, best.packedValidatorReward = big.NewInt(300), big.NewInt(-1)
+	ledger.simulated(best, 20*time.Millisecond, true, nil)
+	failed.packedBlockReward, failed.packedValidatorReward = new(big.Int), new(big.Int)
+	ledger.simulated(failed, time.Millisecond, false, errors.New("interrupted"))
+
+	if rawdb.ReadBidHistory(db, 1) != nil {
+		t.Fatal("bid history persisted before the block is sealed")
+	}
+	ledger.flush(1, best, true)
+
+	records := rawdb.ReadBidHistory(db, 1)
+	if len(records) != 3 {
+		t.Fatalf("record count mismatch: have %d, want 3", len(records))
+	}
+	if r := records[0]; !r.Accepted || !r.Simulated || !r.Best || !r.Won || r.SimDuration != 20 || r.PackedValidatorReward.Int64() != -1 {
+		t.Errorf("best bid record mismatch: %+v", r)
+	}
+	if r := records[1]; !r.Accepted || r.Simulated || r.Best || r.Error != "interrupted" {
+		t.Errorf("interrupted bid record mismatch: %+v", r)
+	}
+	if r := records[2]; r.Accepted || r.Error != "rejected" || r.ExpectedBlockReward != nil {
+		t.Errorf("rejected bid record mismatch: %+v", r)
+	}
+	if len(ledger.blocks) != 0 || len(ledger.records) != 0 {
+		t.Fatal("flushed records kept in memory")
+	}
+
+	// Records of blocks older than the limit are pruned
+	ledger.flush(3, nil, false)
+	if rawdb.ReadBidHistory(db, 1) != nil {
+		t.Fatal("stale bid history not pruned")
+	}
+
+	// All the records below the limit are pruned when heights are skipped
+	for _, n := range []uint64{4, 5, 9} {
+		rawdb.WriteBidHistory(db, n, records)
+	}
+	ledger.flush(10, nil, false)
+	for _, n := range []uint64{3, 4, 5} {
+		if rawdb.ReadBidHistory(db, n) != nil {
+			t.Fatalf("stale bid history of block %d not pruned", n)
+		}
+	}
+	if rawdb.ReadBidHistory(db, 9) == nil {
+		t.Fatal("bid history within the limit pruned")
+	}
+
+	// A nil ledger is a no-op
+	var disabled *bidLedger
+	disabled.received(best.bid, best, nil)
+	disabled.simulated(best, 0, true, nil)
+	disabled.flush(1, best, true)
+}
@@ -0,0 +1,131 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+)
+
+var (
+	bidPolicyRejectMeter = metrics.NewRegisteredMeter("bid/policy/reject", nil)
+
+	errBidPolicy = errors.New("bid rejected by policy")
+)
+
+// bidPolicy checks the bids against the acceptance policy of the validator.
+type bidPolicy struct {
+	config     *minerconfig.BidPolicyConfig
+	minRewards map[common.Address]*big.Int // builder -> minimum validator reward
+	blacklist  map[common.Address]struct{}
+}
+
+func newBidPolicy(config *minerconfig.MevConfig) *bidPolicy {
+	p := &bidPolicy{
+		config:     &config.BidPolicy,
+		minRewards: make(map[common.Address]*big.Int),
+		blacklist:  make(map[common.Address]struct{}),
+	}
+	for _, builder := range config.Builders {
+		if builder.MinValidatorReward != nil {
+			p.minRewards[builder.Address] = builder.MinValidatorReward
+		}
+	}
+	for _, addr := range types.NanoBlackList {
+		p.blacklist[addr] = struct{}{}
+	}
+	for _, addr := range config.BidPolicy.BlackList {
+		p.blacklist[addr] = struct{}{}
+	}
+	return p
+}
+
+// checkBid checks the rewards and the size of a bid before its simulation. The
+// bid txs are checked by checkTx in the simulation workers.
+func (p *bidPolicy) checkBid(bid *BidRuntime) error {
+	minReward := p.config.MinValidatorReward
+	if reward, ok := p.minRewards[bid.bid.Builder]; ok {
+		minReward = reward
+	}
+	if minReward != nil && bid.expectedValidatorReward.Cmp(minReward) < 0 {
+		return fmt.Errorf("%w: validator reward %v lower than %v", errBidPolicy, bid.expectedValidatorReward, minReward)
+	}
+	// The last tx of the bid is the pay bid tx
+	if txs := len(bid.bid.Txs) - 1; p.config.MaxTxCount > 0 && txs > p.config.MaxTxCount {
+		return fmt.Errorf("%w: %d txs, max %d", errBidPolicy, txs, p.config.MaxTxCount)
+	}
+	return nil
+}
+
+// checkTx checks that a bid tx is neither sent by nor sent to a blacklisted
+// account. It runs in the simulation workers right before the tx is applied,
+// which reuses the recovered sender.
+func (p *bidPolicy) checkTx(tx *types.Transaction, signer types.Signer) error {
+	if len(p.blacklist) == 0 {
+		return nil
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return err
+	}
+	if _, ok := p.blacklist[from]; ok {
+		return fmt.Errorf("%w: tx %s from blacklisted %s", errBidPolicy, tx.Hash(), from)
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.blacklist[*to]; ok {
+			return fmt.Errorf("%w: tx %s to blacklisted %s", errBidPolicy, tx.Hash(), *to)
+		}
+	}
+	return nil
+}
+
+// mandatoryNonces returns the nonces in statedb of the mandatory accounts having
+// an executable tx in the pool.
+func (p *bidPolicy) mandatoryNonces(pool *txpool.TxPool, statedb *state.StateDB, baseFee *big.Int) map[common.Address]uint64 {
+	if len(p.config.MandatoryAccounts) == 0 {
+		return nil
+	}
+	nonces := make(map[common.Address]uint64)
+	for _, addr := range p.config.MandatoryAccounts {
+		pending, _ := pool.ContentFrom(addr)
+		if len(pending) == 0 {
+			continue
+		}
+		nonce := statedb.GetNonce(addr)
+		if tx := pending[0]; tx.Nonce() == nonce && (baseFee == nil || tx.GasFeeCapIntCmp(baseFee) >= 0) {
+			nonces[addr] = nonce
+		}
+	}
+	return nonces
+}
+
+// checkMandatory checks that the next tx of each mandatory account is included
+// in the block built on statedb.
+func (p *bidPolicy) checkMandatory(nonces map[common.Address]uint64, statedb *state.StateDB) error {
+	for addr, nonce := range nonces {
+		if statedb.GetNonce(addr) <= nonce {
+			return fmt.Errorf("%w: mandatory tx of %s with nonce %d not included", errBidPolicy, addr, nonce)
+		}
+	}
+	return nil
+}
+
+// checkRewardRatio checks that the bid block reward is not suspiciously higher
+// than the reward of the local block. Empty local blocks are not compared.
+func checkRewardRatio(config *minerconfig.BidPolicyConfig, bidReward, localReward *big.Int) error {
+	if config.MaxRewardRatio == 0 || localReward.Sign() <= 0 {
+		return nil
+	}
+	limit := new(big.Int).Mul(localReward, new(big.Int).SetUint64(config.MaxRewardRatio))
+	limit.Div(limit, big.NewInt(100))
+	if bidReward.Cmp(limit) > 0 {
+		return fmt.Errorf("%w: block reward %v above %d%% of local block reward %v", errBidPolicy, bidReward, config.MaxRewardRatio, localReward)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBidPolicyCheckBid(t *testing.T) {
+	var (
+		builder   = common.HexToAddress("0xb1")
+		blacklist = common.HexToAddress("0xbad")
+		signer    = types.LatestSigner(ethashChainConfig)
+		config    = &minerconfig.MevConfig{
+			Builders: []minerconfig.BuilderConfig{{Address: builder, MinValidatorReward: big.NewInt(50)}},
+			BidPolicy: minerconfig.BidPolicyConfig{
+				MinValidatorReward: big.NewInt(100),
+				MaxTxCount:         2,
+				BlackList:          []common.Address{blacklist},
+			},
+		}
+		policy = newBidPolicy(config)
+	)
+	newBid := func(builder common.Address, gasFee int64, to ...common.Address) *BidRuntime {
+		txs := make(types.Transactions, 0, len(to)+1)
+		for i, addr := range append(to, builder) {
+			txs = append(txs, types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+				Nonce:    uint64(i),
+				To:       &addr,
+				Gas:      params.TxGas,
+				GasPrice: big.NewInt(params.InitialBaseFee),
+			}))
+		}
+		r, err := newBidRuntime(&types.Bid{Builder: builder, Txs: txs, GasFee: big.NewInt(gasFee), BuilderFee: common.Big0}, 10000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	tests := []struct {
+		bid *BidRuntime
+		ok  bool
+	}{
+		{newBid(common.Address{}, 100, testUserAddress), true},
+		{newBid(common.Address{}, 99, testUserAddress), false}, // below the default minimum reward
+		{newBid(builder, 50, testUserAddress), true},           // builder minimum reward
+		{newBid(builder, 100, testUserAddress, testUserAddress), true},
+		{newBid(builder, 100, testUserAddress, testUserAddress, testUserAddress), false}, // too many txs
+		{newBid(builder, 100, blacklist), false},
+		{newBid(builder, 100, types.NanoBlackList[0]), false},
+	}
+	for i, tt := range tests {
+		err := policy.checkBid(tt.bid)
+		for _, tx := range tt.bid.bid.Txs {
+			if err == nil {
+				err = policy.checkTx(tx, signer)
+			}
+		}
+		if tt.ok && err != nil {
+			t.Errorf("test %d: bid rejected: %v", i, err)
+		}
+		if !tt.ok && !errors.Is(err, errBidPolicy) {
+			t.Errorf("test %d: have %v, want %v", i, err, errBidPolicy)
+		}
+	}
+}
+
+func TestBidPolicyMandatory(t *testing.T) {
+	backend := newTestWorkerBackend(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer backend.chain.Stop()
+
+	policy := newBidPolicy(&minerconfig.MevConfig{
+		BidPolicy: minerconfig.BidPolicyConfig{MandatoryAccounts: []common.Address{testBankAddress, testUserAddress}},
+	})
+	statedb, err := backend.chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonces := policy.mandatoryNonces(backend.txPool, statedb, nil); len(nonces) != 0 {
+		t.Fatalf("mandatory nonces without pending txs: %v", nonces)
+	}
+	if errs := backend.txPool.Add(pendingTxs[:1], true, true); errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+	nonces := policy.mandatoryNonces(backend.txPool, statedb, nil)
+	if len(nonces) != 1 || nonces[testBankAddress] != 0 {
+		t.Fatalf("unexpected mandatory nonces: %v", nonces)
+	}
+	if err := policy.checkMandatory(nonces, statedb); !errors.Is(err, errBidPolicy) {
+		t.Fatalf("missing mandatory tx: have %v, want %v", err, errBidPolicy)
+	}
+	statedb.SetNonce(testBankAddress, 1)
+	if err := policy.checkMandatory(nonces, statedb); err != nil {
+		t.Fatalf("included mandatory tx rejected: %v", err)
+	}
+}
+
+func TestCheckRewardRatio(t *testing.T) {
+	config := &minerconfig.BidPolicyConfig{MaxRewardRatio: 200}
+	tests := []struct {
+		bid, local int64
+		ok         bool
+	}{
+		{200, 100, true},
+		{201, 100, false},
+		{1000, 0, true}, // empty local blocks are not compared
+	}
+	for i, tt := range tests {
+		err := checkRewardRatio(config, big.NewInt(tt.bid), big.NewInt(tt.local))
+		if (err == nil) != tt.ok {
+			t.Errorf("test %d: unexpected result %v", i, err)
+		}
+	}
+	if err := checkRewardRatio(&minerconfig.BidPolicyConfig{}, big.NewInt(1000), big.NewInt(1)); err != nil {
+		t.Fatalf("disabled ratio check failed: %v", err)
+	}
+}
@@ -0,0 +1,372 @@
+package miner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// bidDumpInterval is the minimum interval between two dumps of the failed
+	// simulations of the bids of a builder.
+	bidDumpInterval = 10 * time.Second
+
+	// bidDumpQueueSize is the number of dumps waiting to be written.
+	bidDumpQueueSize = 16
+)
+
+// bidDumpSkipMeter counts the failed simulations not dumped because of the
+// rate limit or of the write queue.
+var bidDumpSkipMeter = metrics.NewRegisteredMeter("mev/bid/dump/skip", nil)
+
+// BidDump is the input and the outcome of the simulation of a bid, dumped so
+// that the simulation can be replayed with ReplayBid when a builder disputes
+// the issue reported about the bid.
+type BidDump struct {
+	Builder      common.Address  `json:"builder"`
+	BidHash      common.Hash     `json:"bidHash"`
+	BlockNumber  uint64          `json:"blockNumber"`
+	ParentHash   common.Hash     `json:"parentHash"`
+	Txs          []hexutil.Bytes `json:"txs"` // Txs of the bid, the pay bid tx last
+	UnRevertible []common.Hash   `json:"unRevertible"`
+	GasUsed      uint64          `json:"gasUsed"`
+	GasFee       *big.Int        `json:"gasFee"`
+	BuilderFee   *big.Int        `json:"builderFee"`
+
+	ValidatorCommission uint64          `json:"validatorCommission"`
+	Coinbase            common.Address  `json:"coinbase"`
+	Header              *types.Header   `json:"header"`    // Header of the simulated block before any tx
+	MergedTxs           []hexutil.Bytes `json:"mergedTxs"` // Txs greedily merged from the txpool before the pay bid tx
+
+	Result *BidSimResult `json:"result"`
+}
+
+// BidSimTxResult is the outcome of a tx of a simulated bid.
+type BidSimTxResult struct {
+	TxHash   common.Hash `json:"txHash"`
+	GasUsed  uint64      `json:"gasUsed"`
+	Reverted bool        `json:"reverted"`
+	Merged   bool        `json:"merged,omitempty"` // Whether the tx was merged from the txpool
+}
+
+// BidSimResult is the outcome of the simulation of a bid.
+type BidSimResult struct {
+	Txs             []BidSimTxResult `json:"txs"`
+	GasUsed         uint64           `json:"gasUsed"`
+	BlockReward     *big.Int         `json:"blockReward"`
+	ValidatorReward *big.Int         `json:"validatorReward"`
+	Error           string           `json:"error,omitempty"`
+}
+
+// newBidDump creates the dump of the bid about to be simulated on top of env.
+func newBidDump(bid *types.Bid, env *environment, validatorCommission uint64) *BidDump {
+	dump := &BidDump{
+		Builder:             bid.Builder,
+		BidHash:             bid.Hash(),
+		BlockNumber:         bid.BlockNumber,
+		ParentHash:          bid.ParentHash,
+		Txs:                 make([]hexutil.Bytes, 0, len(bid.Txs)),
+		UnRevertible:        bid.UnRevertible.ToSlice(),
+		GasUsed:             bid.GasUsed,
+		GasFee:              bid.GasFee,
+		BuilderFee:          bid.BuilderFee,
+		ValidatorCommission: validatorCommission,
+		Coinbase:            env.coinbase,
+		Header:              types.CopyHeader(env.header),
+	}
+	for _, tx := range bid.Txs {
+		enc, _ := tx.MarshalBinary()
+		dump.Txs = append(dump.Txs, enc)
+	}
+	return dump
+}
+
+// merged records the txs merged from the txpool into env, the txs from index
+// from on.
+func (d *BidDump) merged(env *environment, from int) {
+	sidecars := make(map[uint64]*types.BlobSidecar, len(env.sidecars))
+	for _, sc := range env.sidecars {
+		sidecars[sc.TxIndex] = sc
+	}
+	for i := from; i < len(env.txs); i++ {
+		tx := env.txs[i]
+		if sc, ok := sidecars[uint64(i)]; ok {
+			tx = tx.WithBlobTxSidecar(&sc.BlobTxSidecar)
+		}
+		enc, _ := tx.MarshalBinary()
+		d.MergedTxs = append(d.MergedTxs, enc)
+	}
+}
+
+// write stores the dump as JSON in dir, named after the block number and the
+// bid hash, and returns the path of the file.
+func (d *BidDump) write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%d-%s.json", d.BlockNumber, d.BidHash.Hex()))
+	return file, os.WriteFile(file, data, 0644)
+}
+
+// bidDumper writes the dumps of the failed bid simulations in the background,
+// so that the simulations don't wait for the disk. A builder gets at most one
+// dump per bidDumpInterval, the dumps are dropped if the writes fall behind,
+// and the oldest files are removed once the directory holds maxFiles dumps.
+type bidDumper struct {
+	dir      string
+	maxFiles int
+	queue    chan *BidDump
+
+	mu   sync.Mutex
+	last map[common.Address]time.Time // time of the last dump of each builder
+
+	files []string // dump files, the oldest first, only accessed by loop
+}
+
+func newBidDumper(dir string, maxFiles int) *bidDumper {
+	d := &bidDumper{
+		dir:      dir,
+		maxFiles: max(maxFiles, 1),
+		queue:    make(chan *BidDump, bidDumpQueueSize),
+		last:     make(map[common.Address]time.Time),
+	}
+	// Resume the rotation of the dumps of the previous runs
+	entries, _ := os.ReadDir(dir)
+	type dumpFile struct {
+		name    string
+		modTime time.Time
+	}
+	files := make([]dumpFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			files = append(files, dumpFile{entry.Name(), info.ModTime()})
+		}
+	}
+	slices.SortFunc(files, func(a, b dumpFile) int { return a.modTime.Compare(b.modTime) })
+	for _, file := range files {
+		d.files = append(d.files, filepath.Join(dir, file.name))
+	}
+	return d
+}
+
+// dump queues the dump for writing, unless the builder was dumped recently or
+// the queue is full. It returns whether the dump was queued.
+func (d *bidDumper) dump(dump *BidDump, now time.Time) bool {
+	d.mu.Lock()
+	if last, ok := d.last[dump.Builder]; ok && now.Sub(last) < bidDumpInterval {
+		d.mu.Unlock()
+		bidDumpSkipMeter.Mark(1)
+		return false
+	}
+	d.last[dump.Builder] = now
+	d.mu.Unlock()
+
+	select {
+	case d.queue <- dump:
+		return true
+	default:
+		bidDumpSkipMeter.Mark(1)
+		return false
+	}
+}
+
+// loop writes the queued dumps until quit is closed.
+func (d *bidDumper) loop(quit chan struct{}) {
+	for {
+		select {
+		case dump := <-d.queue:
+			d.write(dump)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// write stores the dump and removes the oldest ones beyond the limit.
+func (d *bidDumper) write(dump *BidDump) {
+	file, err := dump.write(d.dir)
+	if err != nil {
+		log.Warn("BidSimulator: failed to dump bid", "bidHash", dump.BidHash, "err", err)
+		return
+	}
+	d.files = append(d.files, file)
+	for len(d.files) > d.maxFiles {
+		if err := os.Remove(d.files[0]); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn("BidSimulator: failed to remove bid dump", "file", d.files[0], "err", err)
+		}
+		d.files = d.files[1:]
+	}
+}
+
+// ReadBidDump reads a bid dump from file.
+func ReadBidDump(file string) (*BidDump, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var dump BidDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, err
+	}
+	if dump.Header == nil || len(dump.Txs) == 0 {
+		return nil, errors.New("bid dump without header or txs")
+	}
+	return &dump, nil
+}
+
+// simResult returns the outcome of the simulation of the bid, merged being the
+// number of txs merged from the txpool.
+func (r *BidRuntime) simResult(merged int, err error) *BidSimResult {
+	var (
+		env    = r.env
+		bidTxs = len(r.bid.Txs) - 1 // pay bid tx excluded
+		result = &BidSimResult{
+			Txs:             make([]BidSimTxResult, 0, len(env.receipts)),
+			GasUsed:         env.header.GasUsed,
+			BlockReward:     new(big.Int).Set(r.packedBlockReward),
+			ValidatorReward: new(big.Int).Set(r.packedValidatorReward),
+		}
+	)
+	for i, receipt := range env.receipts {
+		result.Txs = append(result.Txs, BidSimTxResult{
+			TxHash:   receipt.TxHash,
+			GasUsed:  receipt.GasUsed,
+			Reverted: receipt.Status == types.ReceiptStatusFailed,
+			Merged:   i >= bidTxs && i < bidTxs+merged,
+		})
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ReplayBid re-runs the simulation of a dumped bid on top of the state of its
+// parent block. The txs merged from the txpool are committed as they were, and
+// the checks depending on the txpool or on the time are skipped.
+func ReplayBid(chain *core.BlockChain, dump *BidDump) (*BidSimResult, error) {
+	config := chain.Config()
+	parent := chain.GetHeaderByHash(dump.ParentHash)
+	if parent == nil {
+		return nil, fmt.Errorf("parent block %s not found", dump.ParentHash)
+	}
+	state, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	decode := func(encs []hexutil.Bytes) (types.Transactions, error) {
+		txs := make(types.Transactions, 0, len(encs))
+		for _, enc := range encs {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(enc); err != nil {
+				return nil, err
+			}
+			txs = append(txs, tx)
+		}
+		return txs, nil
+	}
+	bidTxs, err := decode(dump.Txs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid tx: %v", err)
+	}
+	mergedTxs, err := decode(dump.MergedTxs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merged tx: %v", err)
+	}
+	bid := &types.Bid{
+		Builder:      dump.Builder,
+		BlockNumber:  dump.BlockNumber,
+		ParentHash:   dump.ParentHash,
+		Txs:          bidTxs,
+		UnRevertible: mapset.NewThreadUnsafeSet(dump.UnRevertible...),
+		GasUsed:      dump.GasUsed,
+		GasFee:       dump.GasFee,
+		BuilderFee:   dump.BuilderFee,
+	}
+	bidRuntime, err := newBidRuntime(bid, dump.ValidatorCommission)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the environment like worker.prepareWork
+	header := types.CopyHeader(dump.Header)
+	env := &environment{
+		signer:   types.MakeSigner(config, header.Number, header.Time),
+		state:    state,
+		coinbase: dump.Coinbase,
+		header:   header,
+		evm:      vm.NewEVM(core.NewEVMBlockContext(header, chain, &dump.Coinbase), state, config, vm.Config{}),
+	}
+	systemcontracts.TryUpdateBuildInSystemContract(config, header.Number, parent.Time, header.Time, env.state, true)
+	if header.ParentBeaconRoot != nil {
+		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, env.evm)
+	}
+	if config.IsPrague(header.Number, header.Time) {
+		core.ProcessParentBlockHash(header.ParentHash, env.evm)
+	}
+	env.size = uint32(header.Size())
+	env.gasPool = new(core.GasPool).AddGas(header.GasLimit)
+	env.gasPool.SubGas(params.SystemTxsGas)
+	env.gasPool.SubGas(params.PayBidTxGasLimit)
+	bidRuntime.env = env
+
+	// Replay the steps of bidSimulator.simBid
+	var merged int
+	result := func(err error) *BidSimResult {
+		return bidRuntime.simResult(merged, err)
+	}
+	if bid.GasUsed > env.gasPool.Gas() {
+		return result(errors.New("gas used exceeds gas limit")), nil
+	}
+	for _, tx := range bidTxs[:len(bidTxs)-1] {
+		if err := bidRuntime.commitTransaction(chain, config, tx, bid.UnRevertible.Contains(tx.Hash())); err != nil {
+			return result(fmt.Errorf("invalid tx in bid, %v", err)), nil
+		}
+	}
+	bidRuntime.packReward(dump.ValidatorCommission)
+	if !bidRuntime.validReward() {
+		return result(errors.New("reward does not achieve the expectation")), nil
+	}
+	for _, tx := range mergedTxs {
+		if err := bidRuntime.commitTransaction(chain, config, tx, false); err != nil {
+			log.Warn("Failed to replay merged tx", "tx", tx.Hash(), "err", err)
+			return result(fmt.Errorf("invalid merged tx, %v", err)), nil
+		}
+		merged++
+	}
+	bidRuntime.packReward(dump.ValidatorCommission)
+
+	env.gasPool.AddGas(params.PayBidTxGasLimit)
+	if err := bidRuntime.commitTransaction(chain, config, bidTxs[len(bidTxs)-1], true); err != nil {
+		return result(fmt.Errorf("invalid tx in bid, %v", err)), nil
+	}
+	if env.size+blockReserveSize > params.MaxMessageSize {
+		return result(errors.New("invalid bid size")), nil
+	}
+	return result(nil), nil
+}
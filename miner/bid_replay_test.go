@@ -0,0 +1,144 @@
+package miner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestReplayBid(t *testing.T) {
+	w, backend := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	env, err := w.prepareWork(&generateParams{coinbase: testBankAddress}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.discard()
+
+	tests := []struct {
+		nonces []uint64 // the last tx is the pay bid tx
+		txs    int      // committed txs
+		err    bool
+	}{
+		{[]uint64{0, 1, 2}, 3, false},
+		{[]uint64{0, 2, 3}, 1, true}, // nonce gap
+	}
+	for i, tt := range tests {
+		txs := make(types.Transactions, 0, len(tt.nonces))
+		for _, nonce := range tt.nonces {
+			txs = append(txs, newTestBundleTx(t, nonce, params.GWei))
+		}
+		bid := &types.Bid{
+			BlockNumber:  1,
+			ParentHash:   backend.chain.CurrentBlock().Hash(),
+			Txs:          txs,
+			UnRevertible: mapset.NewThreadUnsafeSet[common.Hash](),
+			GasFee:       common.Big0,
+			BuilderFee:   common.Big0,
+		}
+		dump := newBidDump(bid, env, 100)
+
+		// The dump goes through the file like in geth mev replay-bid
+		dir := t.TempDir()
+		if _, err := dump.write(dir); err != nil {
+			t.Fatal(err)
+		}
+		files, _ := os.ReadDir(dir)
+		if len(files) != 1 {
+			t.Fatalf("test %d: %d dump files", i, len(files))
+		}
+		read, err := ReadBidDump(filepath.Join(dir, files[0].Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := ReplayBid(backend.chain, read)
+		if err != nil {
+			t.Fatalf("test %d: replay failed: %v", i, err)
+		}
+		if len(result.Txs) != tt.txs || (result.Error != "") != tt.err {
+			t.Fatalf("test %d: unexpected result %+v", i, result)
+		}
+		for j, tx := range result.Txs {
+			if tx.TxHash != txs[j].Hash() || tx.GasUsed != params.TxGas || tx.Reverted {
+				t.Errorf("test %d: unexpected tx %d result %+v", i, j, tx)
+			}
+		}
+		if result.GasUsed != uint64(tt.txs)*params.TxGas {
+			t.Errorf("test %d: gas used %d", i, result.GasUsed)
+		}
+		// The replay is deterministic
+		again, err := ReplayBid(backend.chain, read)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, again) {
+			t.Errorf("test %d: replay mismatch: %+v != %+v", i, result, again)
+		}
+	}
+	if _, err := ReplayBid(backend.chain, &BidDump{ParentHash: common.HexToHash("0x01"), Header: env.header}); err == nil {
+		t.Fatal("replay on an unknown parent succeeded")
+	}
+}
+
+func TestBidDumper(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		now = time.Now()
+		d   = newBidDumper(dir, 2)
+	)
+	newDump := func(builder byte, number uint64) *BidDump {
+		return &BidDump{Builder: common.Address{builder}, BidHash: common.Hash{builder, byte(number)}, BlockNumber: number}
+	}
+	// A builder gets a single dump per interval
+	tests := []struct {
+		dump   *BidDump
+		time   time.Time
+		queued bool
+	}{
+		{newDump(1, 1), now, true},
+		{newDump(1, 2), now.Add(time.Second), false},
+		{newDump(2, 2), now.Add(time.Second), true},
+		{newDump(1, 3), now.Add(bidDumpInterval), true},
+	}
+	for i, tt := range tests {
+		if queued := d.dump(tt.dump, tt.time); queued != tt.queued {
+			t.Fatalf("test %d: queued %v, want %v", i, queued, tt.queued)
+		}
+		if tt.queued {
+			d.write(<-d.queue)
+		}
+	}
+	// Only the newest dumps are kept
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("dump files: have %d, want 2", len(files))
+	}
+	if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("1-%s.json", newDump(1, 1).BidHash.Hex()))); !os.IsNotExist(err) {
+		t.Fatalf("oldest dump not removed: %v", err)
+	}
+	// The rotation resumes after a restart
+	if d = newBidDumper(dir, 2); len(d.files) != 2 {
+		t.Fatalf("dump files not resumed: %v", d.files)
+	}
+	// The dumps are dropped once the queue is full
+	for i := 0; i < bidDumpQueueSize; i++ {
+		if !d.dump(newDump(byte(10+i), 4), now) {
+			t.Fatalf("dump %d not queued", i)
+		}
+	}
+	if d.dump(newDump(100, 4), now) {
+		t.Fatal("dump queued beyond the queue size")
+	}
+}
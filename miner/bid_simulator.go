@@ -32,10 +32,23 @@ import (
 const (
 	// maxBidPerBuilderPerBlock is the max bid number per builder
 	maxBidPerBuilderPerBlock = 3
+
+	// bidEventQueueSize is the number of bid events waiting to be sent to the
+	// subscribers, the events are dropped once it is full.
+	bidEventQueueSize = 1024
 )
 
 var (
 	bidSimTimer = metrics.NewRegisteredTimer("bid/sim/duration", nil)
+
+	// bidSimWastedMeter counts simulations whose result was thrown away, either
+	// because a better bid interrupted them or because a concurrent simulation
+	// of a better bid finished first.
+	bidSimWastedMeter   = metrics.NewRegisteredMeter("bid/sim/wasted", nil)
+	bidSimSkippedMeter  = metrics.NewRegisteredMeter("bid/sim/skipped", nil)
+	bidSimInflightGauge = metrics.NewRegisteredGauge("bid/sim/inflight", nil)
+
+	bidEventDropMeter = metrics.NewRegisteredMeter("bid/event/drop", nil)
 )
 
 var (
@@ -63,6 +76,7 @@ type bidWorker interface {
 	prepareWork(params *generateParams, witness bool) (*environment, error)
 	etherbase() common.Address
 	fillTransactions(interruptCh chan int32, env *environment, stopTimer *time.Timer, bidTxs mapset.Set[common.Hash]) (err error)
+	commitBundles(env *environment, interruptCh chan int32, stopTimer *time.Timer) ([]*types.Bundle, error)
 }
 
 // simBidReq is the request for simulating a bid
@@ -97,11 +111,12 @@ type bidSimulator struct {
 	chainHeadCh  chan core.ChainHeadEvent
 	chainHeadSub event.Subscription
 
-	sentryCli *builderclient.Client
+	sentry *sentryPool // nil if the builders are reached directly
 
-	// builder info (warning: only keep status in memory!)
+	// clients of the registered builders, banned builders excluded
 	buildersMu sync.RWMutex
-	builders   map[common.Address]*builderclient.Client
+	builders   map[common.Address]builderClient
+	registry   *builderRegistry // persisted status of the builders
 
 	// channels
 	simBidCh chan *simBidReq
@@ -114,7 +129,15 @@ type bidSimulator struct {
 	bestBid   map[common.Hash]*BidRuntime // prevBlockHash -> bidRuntime
 
 	simBidMu      sync.RWMutex
-	simulatingBid map[common.Hash]*BidRuntime // prevBlockHash -> bidRuntime, in the process of simulation
+	simulatingBid map[common.Hash][]*simBidReq // prevBlockHash -> candidates queued or in the process of simulation
+
+	bidLedger *bidLedger // nil if the bid history is disabled
+	dumper    *bidDumper // nil if the failed simulations are not dumped
+	policy    *bidPolicy
+
+	bidEventCh  chan types.BidEvent // events waiting to be sent to the feeds
+	bidFeed     event.Feed          // accepted, rejected and promoted bids
+	bestBidFeed event.Feed          // promoted bids
 }
 
 func newBidSimulator(
@@ -125,7 +148,14 @@ func newBidSimulator(
 	chainConfig *params.ChainConfig,
 	engine consensus.Engine,
 	bidWorker bidWorker,
-) *bidSimulator {
+) (*bidSimulator, error) {
+	var sentry *sentryPool
+	if urls := sentryURLs(config); len(urls) > 0 {
+		var err error
+		if sentry, err = newSentryPool(urls, config.SentryPolicy); err != nil {
+			return nil, fmt.Errorf("invalid mev sentry config: %w", err)
+		}
+	}
 	b := &bidSimulator{
 		config:        config,
 		delayLeftOver: delayLeftOver,
@@ -137,12 +167,24 @@ func newBidSimulator(
 		bidWorker:     bidWorker,
 		exitCh:        make(chan struct{}),
 		chainHeadCh:   make(chan core.ChainHeadEvent, chainHeadChanSize),
-		builders:      make(map[common.Address]*builderclient.Client),
-		simBidCh:      make(chan *simBidReq),
+		builders:      make(map[common.Address]builderClient),
+		simBidCh:      make(chan *simBidReq, bidSimulationWorkers(config)*bidSimulationCandidates(config)),
 		newBidCh:      make(chan newBidPackage, 100),
 		pending:       make(map[uint64]map[common.Address]map[common.Hash]struct{}),
 		bestBid:       make(map[common.Hash]*BidRuntime),
-		simulatingBid: make(map[common.Hash]*BidRuntime),
+		simulatingBid: make(map[common.Hash][]*simBidReq),
+		bidEventCh:    make(chan types.BidEvent, bidEventQueueSize),
+		policy:        newBidPolicy(config),
+		registry:      newBuilderRegistry(eth.ChainDb(), config),
+		sentry:        sentry,
+	}
+
+	if config.BidHistoryLimit > 0 {
+		b.bidLedger = newBidLedger(eth.ChainDb(), config.BidHistoryLimit)
+	}
+	if config.BidDumpDir != "" {
+		b.dumper = newBidDumper(config.BidDumpDir, config.BidDumpMaxFiles)
+		go b.dumper.loop(b.exitCh)
 	}
 
 	b.chainHeadSub = b.chain.SubscribeChainHeadEvent(b.chainHeadCh)
@@ -157,27 +199,52 @@ func newBidSimulator(
 	}
 
 	go b.clearLoop()
-	go b.mainLoop()
+	go b.bidEventLoop()
 	go b.newBidLoop()
+	if config.BuilderHealthCheckInterval > 0 {
+		go b.healthCheckLoop()
+	}
+	if b.sentry != nil && config.SentryHealthCheckInterval > 0 {
+		go b.sentryHealthCheckLoop()
+	}
 
-	return b
-}
+	for i := 0; i < bidSimulationWorkers(config); i++ {
+		go b.mainLoop()
+	}
 
-func (b *bidSimulator) dialSentryAndBuilders() {
-	var sentryCli *builderclient.Client
-	var err error
+	return b, nil
+}
 
-	if b.config.SentryURL != "" {
-		sentryCli, err = builderclient.DialOptions(context.Background(), b.config.SentryURL, rpc.WithHTTPClient(client))
-		if err != nil {
-			log.Error("BidSimulator: failed to dial sentry", "url", b.config.SentryURL, "err", err)
-		}
-	}
+// bidSimulationWorkers returns the number of bids simulated concurrently.
+func bidSimulationWorkers(config *minerconfig.MevConfig) int {
+	return max(config.BidSimulationWorkers, 1)
+}
 
-	b.sentryCli = sentryCli
+// bidSimulationCandidates returns the number of best bids simulated
+// concurrently for the same parent block.
+func bidSimulationCandidates(config *minerconfig.MevConfig) int {
+	return max(config.BidSimulationCandidates, 1)
+}
 
+func (b *bidSimulator) dialSentryAndBuilders() {
+	configured := make(map[common.Address]struct{}, len(b.config.Builders))
 	for _, v := range b.config.Builders {
-		_ = b.AddBuilder(v.Address, v.URL)
+		configured[v.Address] = struct{}{}
+		if err := b.addBuilder(v.Address, v.URL, builderFromConfig); errors.Is(err, errBuilderBanned) {
+			log.Warn("BidSimulator: configured builder is banned", "builder", v.Address)
+		}
+	}
+	// Drop the builders removed from the config since they were added
+	for _, builder := range b.registry.unconfigured(configured) {
+		log.Info("BidSimulator: dropping builder removed from the config", "builder", builder)
+		_ = b.RemoveBuilder(builder)
+	}
+	// Restore the builders added at runtime or registered by themselves
+	for _, record := range b.registry.list() {
+		if _, ok := configured[record.Address]; ok || record.Status == types.BuilderBanned {
+			continue
+		}
+		_ = b.dialBuilder(record.Address, record.URL)
 	}
 }
 
@@ -211,26 +278,48 @@ func (b *bidSimulator) stopReceivingBid() {
 	b.bidReceiving.Store(false)
 }
 
+// AddBuilder registers the builder and dials it, the url is ignored if the
+// validator is equipped with a sentry.
 func (b *bidSimulator) AddBuilder(builder common.Address, url string) error {
-	b.buildersMu.Lock()
-	defer b.buildersMu.Unlock()
+	return b.addBuilder(builder, url, builderFromAPI)
+}
 
-	if b.sentryCli != nil {
-		b.builders[builder] = b.sentryCli
-	} else {
-		var builderCli *builderclient.Client
+func (b *bidSimulator) addBuilder(builder common.Address, url string, source builderSource) error {
+	if err := b.registry.register(builder, url, source); err != nil {
+		return err
+	}
+	return b.dialBuilder(builder, url)
+}
 
-		if url != "" {
-			var err error
+// RegisterBuilder registers a builder after checking its signed registration.
+func (b *bidSimulator) RegisterBuilder(reg *types.BuilderRegistration) error {
+	builder, err := b.registry.verifyRegistration(reg, b.bidWorker.etherbase(), time.Now())
+	if err != nil {
+		return err
+	}
+	if err := b.registry.registerSigned(builder, reg.URL, reg.Timestamp); err != nil {
+		return err
+	}
+	log.Info("BidSimulator: builder registered", "builder", builder, "url", reg.URL)
+	return b.dialBuilder(builder, reg.URL)
+}
 
-			builderCli, err = builderclient.DialOptions(context.Background(), url, rpc.WithHTTPClient(client))
-			if err != nil {
-				log.Error("BidSimulator: failed to dial builder", "url", url, "err", err)
-				return err
-			}
+func (b *bidSimulator) dialBuilder(builder common.Address, url string) error {
+	b.buildersMu.Lock()
+	defer b.buildersMu.Unlock()
+
+	if b.sentry != nil {
+		b.builders[builder] = b.sentry
+	} else if url != "" {
+		builderCli, err := builderclient.DialOptions(context.Background(), url, rpc.WithHTTPClient(client))
+		if err != nil {
+			log.Error("BidSimulator: failed to dial builder", "url", url, "err", err)
+			return err
 		}
 
 		b.builders[builder] = builderCli
+	} else {
+		b.builders[builder] = nil
 	}
 
 	return nil
@@ -241,6 +330,7 @@ func (b *bidSimulator) RemoveBuilder(builder common.Address) error {
 	defer b.buildersMu.Unlock()
 
 	delete(b.builders, builder)
+	b.registry.remove(builder)
 
 	return nil
 }
@@ -254,17 +344,154 @@ func (b *bidSimulator) ExistBuilder(builder common.Address) bool {
 	return ok
 }
 
-func (b *bidSimulator) SetBestBid(prevBlockHash common.Hash, bid *BidRuntime) {
+// CheckBuilder returns an error if the bids of the builder are not accepted.
+func (b *bidSimulator) CheckBuilder(builder common.Address) error {
+	if err := b.registry.check(builder); err != nil {
+		return err
+	}
+	if !b.ExistBuilder(builder) {
+		return errBuilderNotRegistered
+	}
+	return nil
+}
+
+// Builders returns the records of the registered builders.
+func (b *bidSimulator) Builders() []*types.BuilderRecord {
+	return b.registry.list()
+}
+
+// SetBuilderStatus sets the status of a registered builder. The client of a
+// banned builder is dropped, and dialed again once the builder is active.
+func (b *bidSimulator) SetBuilderStatus(builder common.Address, status types.BuilderStatus, reason string) error {
+	if err := b.registry.setStatus(builder, status, reason); err != nil {
+		return err
+	}
+	log.Info("BidSimulator: builder status changed", "builder", builder, "status", status, "reason", reason)
+
+	if status == types.BuilderBanned {
+		b.buildersMu.Lock()
+		delete(b.builders, builder)
+		b.buildersMu.Unlock()
+		return nil
+	}
+	if b.ExistBuilder(builder) {
+		return nil
+	}
+	record, _ := b.registry.get(builder)
+	return b.dialBuilder(builder, record.URL)
+}
+
+// builderFailed counts a failure of the builder, which is suspended after too
+// many consecutive failures. The failures of the sentries aren't counted, they
+// are tracked by the sentry pool.
+func (b *bidSimulator) builderFailed(builder common.Address, err error) {
+	if errors.Is(err, errSentryUnavailable) {
+		return
+	}
+	if b.registry.recordFailure(builder, err.Error()) {
+		log.Warn("BidSimulator: builder suspended", "builder", builder, "err", err)
+	}
+}
+
+// builderSucceeded resets the failures of the builder, and reactivates it if
+// it was suspended.
+func (b *bidSimulator) builderSucceeded(builder common.Address) {
+	if b.registry.recordSuccess(builder) {
+		log.Info("BidSimulator: builder reactivated", "builder", builder)
+	}
+}
+
+// healthCheckLoop pings the registered builders periodically.
+func (b *bidSimulator) healthCheckLoop() {
+	ticker := time.NewTicker(b.config.BuilderHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if b.receivingBid() {
+				b.checkBuilders()
+			}
+		case <-b.exitCh:
+			return
+		}
+	}
+}
+
+// sentryHealthCheckLoop pings the sentries periodically.
+func (b *bidSimulator) sentryHealthCheckLoop() {
+	ticker := time.NewTicker(b.config.SentryHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if b.receivingBid() {
+				b.sentry.healthCheck()
+			}
+		case <-b.exitCh:
+			return
+		}
+	}
+}
+
+// SentryStatus returns the status of the sentries, nil if there is none.
+func (b *bidSimulator) SentryStatus() []SentryStatus {
+	if b.sentry == nil {
+		return nil
+	}
+	return b.sentry.status()
+}
+
+// checkBuilders pings the builders concurrently and waits for the results.
+func (b *bidSimulator) checkBuilders() {
+	b.buildersMu.RLock()
+	clients := make(map[common.Address]builderClient, len(b.builders))
+	for builder, cli := range b.builders {
+		if cli != nil {
+			clients[builder] = cli
+		}
+	}
+	b.buildersMu.RUnlock()
+
+	var wg sync.WaitGroup
+	for builder, cli := range clients {
+		wg.Add(1)
+		go func(builder common.Address, cli builderClient) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), builderPingTimeout)
+			defer cancel()
+
+			if err := cli.Ping(ctx); err != nil {
+				log.Debug("BidSimulator: builder health check failed", "builder", builder, "err", err)
+				b.builderFailed(builder, err)
+				return
+			}
+			b.builderSucceeded(builder)
+		}(builder, cli)
+	}
+	wg.Wait()
+}
+
+// promoteBid makes bid the best bid of its parent block if it packs at least
+// the block reward of the current best bid. The comparison and the update are
+// atomic, so a concurrent simulation can't overwrite a better result. The
+// previous best bid is returned along with whether bid was promoted.
+func (b *bidSimulator) promoteBid(bid *BidRuntime) (*BidRuntime, bool) {
 	b.bestBidMu.Lock()
 	defer b.bestBidMu.Unlock()
 
+	last := b.bestBid[bid.bid.ParentHash]
+	if last != nil && bid.packedBlockReward.Cmp(last.packedBlockReward) < 0 {
+		return last, false
+	}
 	// must discard the environment of the last best bid, otherwise it will cause memory leak
-	last := b.bestBid[prevBlockHash]
-	if last != nil && last.env != nil {
+	if last != nil && last != bid && last.env != nil {
 		last.env.discard()
 	}
-
-	b.bestBid[prevBlockHash] = bid
+	b.bestBid[bid.bid.ParentHash] = bid
+	return last, true
 }
 
 func (b *bidSimulator) GetBestBid(prevBlockHash common.Hash) *BidRuntime {
@@ -274,27 +501,85 @@ func (b *bidSimulator) GetBestBid(prevBlockHash common.Hash) *BidRuntime {
 	return b.bestBid[prevBlockHash]
 }
 
-func (b *bidSimulator) SetSimulatingBid(prevBlockHash common.Hash, bid *BidRuntime) {
-	b.simBidMu.Lock()
-	defer b.simBidMu.Unlock()
-
-	b.simulatingBid[prevBlockHash] = bid
-}
-
+// GetSimulatingBid returns the candidate with the highest expected reward
+// among the bids of the parent block being simulated, nil if there is none.
 func (b *bidSimulator) GetSimulatingBid(prevBlockHash common.Hash) *BidRuntime {
 	b.simBidMu.RLock()
 	defer b.simBidMu.RUnlock()
 
-	return b.simulatingBid[prevBlockHash]
+	var best *BidRuntime
+	for _, req := range b.simulatingBid[prevBlockHash] {
+		if best == nil || req.bid.isExpectedBetterThan(best) {
+			best = req.bid
+		}
+	}
+	return best
 }
 
-func (b *bidSimulator) RemoveSimulatingBid(prevBlockHash common.Hash) {
+// admitBid decides whether the bid of req is worth simulating. The best
+// candidates of each parent block are simulated concurrently, up to the
+// configured number. A new bid has to be expected better than the best bid
+// already simulated and, if all slots are taken, than the worst candidate,
+// whose simulation is then interrupted. If the bid is not admitted, the bid it
+// lost against is returned.
+func (b *bidSimulator) admitBid(req *simBidReq) (*BidRuntime, bool) {
+	parentHash := req.bid.bid.ParentHash
+	if bestBid := b.GetBestBid(parentHash); bestBid != nil && !req.bid.isExpectedBetterThan(bestBid) {
+		return bestBid, false
+	}
+
 	b.simBidMu.Lock()
 	defer b.simBidMu.Unlock()
 
-	delete(b.simulatingBid, prevBlockHash)
+	candidates := b.simulatingBid[parentHash]
+	for _, c := range candidates {
+		if c.bid.bid.Hash() == req.bid.bid.Hash() {
+			return c.bid, false
+		}
+	}
+	if len(candidates) >= bidSimulationCandidates(b.config) {
+		worst := 0
+		for i, c := range candidates {
+			if candidates[worst].bid.isExpectedBetterThan(c.bid) {
+				worst = i
+			}
+		}
+		evicted := candidates[worst]
+		if !req.bid.isExpectedBetterThan(evicted.bid) {
+			return evicted.bid, false
+		}
+		candidates = append(candidates[:worst], candidates[worst+1:]...)
+
+		// each candidate has its own interruptCh to stop work with a reason
+		evicted.interruptCh <- commitInterruptBetterBid
+		close(evicted.interruptCh)
+	}
+	b.simulatingBid[parentHash] = append(candidates, req)
+	return nil, true
 }
 
+// removeSimulatingBid drops a finished candidate, unless it was already
+// evicted by a better bid.
+func (b *bidSimulator) removeSimulatingBid(bid *BidRuntime) {
+	b.simBidMu.Lock()
+	defer b.simBidMu.Unlock()
+
+	parentHash := bid.bid.ParentHash
+	candidates := b.simulatingBid[parentHash]
+	for i, c := range candidates {
+		if c.bid == bid {
+			candidates = append(candidates[:i], candidates[i+1:]...)
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		delete(b.simulatingBid, parentHash)
+	} else {
+		b.simulatingBid[parentHash] = candidates
+	}
+}
+
+// mainLoop is run by each of the simulation workers.
 func (b *bidSimulator) mainLoop() {
 	defer b.chainHeadSub.Unsubscribe()
 
@@ -302,6 +587,8 @@ func (b *bidSimulator) mainLoop() {
 		select {
 		case req := <-b.simBidCh:
 			if !b.isRunning() {
+				b.removeSimulatingBid(req.bid)
+				close(req.bid.finished)
 				continue
 			}
 
@@ -318,21 +605,11 @@ func (b *bidSimulator) mainLoop() {
 }
 
 func (b *bidSimulator) newBidLoop() {
-	var (
-		interruptCh chan int32
-	)
-
-	// commit aborts in-flight bid execution with given signal and resubmits a new one.
-	commit := func(reason int32, bidRuntime *BidRuntime) {
-		if interruptCh != nil {
-			// each commit work will have its own interruptCh to stop work with a reason
-			interruptCh <- reason
-			close(interruptCh)
-		}
-		interruptCh = make(chan int32, 1)
+	// commit queues the bid for simulation by the next free worker.
+	commit := func(req *simBidReq) {
 		select {
-		case b.simBidCh <- &simBidReq{interruptCh: interruptCh, bid: bidRuntime}:
-			log.Debug("BidSimulator: commit", "builder", bidRuntime.bid.Builder, "bidHash", bidRuntime.bid.Hash().Hex())
+		case b.simBidCh <- req:
+			log.Debug("BidSimulator: commit", "builder", req.bid.bid.Builder, "bidHash", req.bid.bid.Hash().Hex())
 		case <-b.exitCh:
 			return
 		}
@@ -352,31 +629,40 @@ func (b *bidSimulator) newBidLoop() {
 			bidRuntime, err := newBidRuntime(newBid.bid, b.config.ValidatorCommission)
 			if err != nil {
 				if newBid.feedback != nil {
+					b.bidLedger.received(newBid.bid, nil, err)
+					b.postBidEvent(newBid.bid, nil, types.BidRejected, err)
 					newBid.feedback <- err
 				}
 				continue
 			}
 
-			var replyErr error
-			// simulatingBid will be nil if there is no bid in simulation, compare with the bestBid instead
-			if simulatingBid := b.GetSimulatingBid(newBid.bid.ParentHash); simulatingBid != nil {
-				// simulatingBid always better than bestBid, so only compare with simulatingBid if a simulatingBid exists
-				if bidRuntime.isExpectedBetterThan(simulatingBid) {
-					commit(commitInterruptBetterBid, bidRuntime)
-				} else {
-					replyErr = genDiscardedReply(simulatingBid)
+			if newBid.feedback != nil {
+				if err := b.policy.checkBid(bidRuntime); err != nil {
+					bidPolicyRejectMeter.Mark(1)
+					b.bidLedger.received(newBid.bid, bidRuntime, err)
+					b.postBidEvent(newBid.bid, bidRuntime, types.BidRejected, err)
+					newBid.feedback <- err
+					go b.reportIssue(newBid.bid, err)
+					continue
 				}
+			}
+
+			var replyErr error
+			req := &simBidReq{interruptCh: make(chan int32, 1), bid: bidRuntime}
+			if betterBid, ok := b.admitBid(req); ok {
+				commit(req)
 			} else {
-				// bestBid is nil means the bid is the first bid, otherwise the bid should compare with the bestBid
-				if bestBid := b.GetBestBid(newBid.bid.ParentHash); bestBid == nil ||
-					bidRuntime.isExpectedBetterThan(bestBid) {
-					commit(commitInterruptBetterBid, bidRuntime)
-				} else {
-					replyErr = genDiscardedReply(bestBid)
-				}
+				replyErr = genDiscardedReply(betterBid)
 			}
 
+			// recommits of simulated bids are not recorded again
 			if newBid.feedback != nil {
+				b.bidLedger.received(newBid.bid, bidRuntime, replyErr)
+				if replyErr == nil {
+					b.postBidEvent(newBid.bid, bidRuntime, types.BidAccepted, nil)
+				} else {
+					b.postBidEvent(newBid.bid, bidRuntime, types.BidRejected, replyErr)
+				}
 				newBid.feedback <- replyErr
 
 				log.Info("[BID ARRIVED]",
@@ -402,14 +688,19 @@ func (b *bidSimulator) bidBetterBefore(parentHash common.Hash) time.Time {
 }
 
 func (b *bidSimulator) clearLoop() {
-	clearFn := func(parentHash common.Hash, blockNumber uint64) {
+	clearFn := func(head *types.Header) {
+		var (
+			parentHash  = head.ParentHash
+			blockNumber = head.Number.Uint64()
+		)
 		b.pendingMu.Lock()
 		delete(b.pending, blockNumber)
 		b.pendingMu.Unlock()
 
 		b.bestBidMu.Lock()
-		if bid, ok := b.bestBid[parentHash]; ok {
-			bid.env.discard()
+		bestBid := b.bestBid[parentHash]
+		if bestBid != nil {
+			bestBid.env.discard()
 		}
 		delete(b.bestBid, parentHash)
 		for k, v := range b.bestBid {
@@ -420,14 +711,19 @@ func (b *bidSimulator) clearLoop() {
 		}
 		b.bestBidMu.Unlock()
 
+		// candidates of stale blocks are left to finish, their simulation
+		// removes them
 		b.simBidMu.Lock()
-		for k, v := range b.simulatingBid {
-			if v.bid.BlockNumber <= blockNumber-b.chain.TriesInMemory() {
-				v.env.discard()
+		for k, candidates := range b.simulatingBid {
+			if len(candidates) > 0 && candidates[0].bid.bid.BlockNumber <= blockNumber-b.chain.TriesInMemory() {
 				delete(b.simulatingBid, k)
 			}
 		}
 		b.simBidMu.Unlock()
+
+		// the bid won if the new head is the local block built from it
+		won := bestBid != nil && bestBid.selected.Load() && head.Coinbase == b.bidWorker.etherbase()
+		b.bidLedger.flush(blockNumber, bestBid, won)
 	}
 
 	for head := range b.chainHeadCh {
@@ -435,7 +731,7 @@ func (b *bidSimulator) clearLoop() {
 			continue
 		}
 
-		clearFn(head.Header.ParentHash, head.Header.Number.Uint64())
+		clearFn(head.Header)
 	}
 }
 
@@ -496,8 +792,18 @@ func (b *bidSimulator) AddPending(blockNumber uint64, builder common.Address, bi
 // simBid simulates a newBid with txs.
 // simBid does not enable state prefetching when commit transaction.
 func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
-	// prevent from stopping happen in time interval from sendBid to simBid
-	if !b.isRunning() || !b.receivingBid() {
+	// prevent from stopping happen in time interval from sendBid to simBid,
+	// and skip candidates evicted by a better bid while queued
+	skip := !b.isRunning() || !b.receivingBid()
+	select {
+	case <-interruptCh:
+		skip = true
+		bidSimSkippedMeter.Mark(1)
+	default:
+	}
+	if skip {
+		b.removeSimulatingBid(bidRuntime)
+		close(bidRuntime.finished)
 		return
 	}
 
@@ -512,14 +818,22 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		bidTxLen = len(bidTxs)
 		payBidTx = bidTxs[bidTxLen-1]
 
-		err     error
-		success bool
-	)
+		err       error
+		success   bool
+		completed bool // set once the simulated block is compared with the best bid
+		wasted    bool // set if the simulation was interrupted or lost to a better one
 
-	// ensure simulation exited then start next simulation
-	b.SetSimulatingBid(parentHash, bidRuntime)
+		dump   *BidDump // nil if the failed simulations are not dumped
+		merged int      // number of txs merged from the txpool
+	)
 
+	bidSimInflightGauge.Inc(1)
 	defer func(simStart time.Time) {
+		bidSimInflightGauge.Dec(1)
+		if wasted {
+			bidSimWastedMeter.Mark(1)
+		}
+
 		logCtx := []any{
 			"blockNumber", blockNumber,
 			"parentHash", parentHash,
@@ -527,6 +841,11 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 			"gasUsed", bidRuntime.bid.GasUsed,
 		}
 
+		if dump != nil && err != nil && !wasted {
+			dump.Result = bidRuntime.simResult(merged, err)
+			b.dumper.dump(dump, time.Now())
+		}
+
 		if bidRuntime.env != nil {
 			logCtx = append(logCtx, "gasLimit", bidRuntime.env.header.GasLimit)
 
@@ -539,10 +858,14 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 			logCtx = append(logCtx, "err", err)
 			log.Info("BidSimulator: simulation failed", logCtx...)
 
-			go b.reportIssue(bidRuntime, err)
+			if !wasted {
+				b.postBidEvent(bidRuntime.bid, bidRuntime, types.BidRejected, err)
+			}
+			go b.reportIssue(bidRuntime.bid, err)
 		}
 
-		b.RemoveSimulatingBid(parentHash)
+		b.removeSimulatingBid(bidRuntime)
+		b.bidLedger.simulated(bidRuntime, time.Since(simStart), completed, err)
 		close(bidRuntime.finished)
 
 		if success {
@@ -570,6 +893,9 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	}, false); err != nil {
 		return
 	}
+	if b.dumper != nil {
+		dump = newBidDump(bidRuntime.bid, bidRuntime.env, b.config.ValidatorCommission)
+	}
 
 	// if the left time is not enough to do simulation, return
 	delay := b.engine.Delay(b.chain, bidRuntime.env.header, &b.delayLeftOver)
@@ -591,11 +917,14 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		return
 	}
 
+	mandatory := b.policy.mandatoryNonces(b.txpool, bidRuntime.env.state, bidRuntime.env.header.BaseFee)
+
 	// commit transactions in bid
 	for _, tx := range bidRuntime.bid.Txs {
 		select {
 		case <-interruptCh:
 			err = errors.New("simulation abort due to better bid arrived")
+			wasted = true
 			return
 
 		case <-b.exitCh:
@@ -605,6 +934,10 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		default:
 		}
 
+		if err = b.policy.checkTx(tx, bidRuntime.env.signer); err != nil {
+			bidPolicyRejectMeter.Mark(1)
+			return
+		}
 		if bidRuntime.env.tcount == bidTxLen-1 {
 			break
 		}
@@ -674,7 +1007,12 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 				bidTxsSet.Add(tx.Hash())
 			}
 
+			from := len(bidRuntime.env.txs)
 			fillErr := b.bidWorker.fillTransactions(interruptCh, bidRuntime.env, nil, bidTxsSet)
+			merged = len(bidRuntime.env.txs) - from
+			if dump != nil {
+				dump.merged(bidRuntime.env, from)
+			}
 			log.Trace("BidSimulator: greedy merge stopped", "block", bidRuntime.env.header.Number,
 				"builder", bidRuntime.bid.Builder, "tx count", bidRuntime.env.tcount-bidTxLen+1, "err", fillErr)
 
@@ -701,17 +1039,26 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		return
 	}
 
-	bestBid := b.GetBestBid(parentHash)
+	if err = b.policy.checkMandatory(mandatory, bidRuntime.env.state); err != nil {
+		bidPolicyRejectMeter.Mark(1)
+		return
+	}
+
+	completed = true
+	bestBid, promoted := b.promoteBid(bidRuntime)
+	// recommits of the best bid are not posted again
+	if promoted && (bestBid == nil || bestBid.bid.Hash() != bidRuntime.bid.Hash()) {
+		b.postBidEvent(bidRuntime.bid, bidRuntime, types.BidPromoted, nil)
+	}
 	if bestBid == nil {
 		log.Info("[BID RESULT]", "win", "true[first]", "builder", bidRuntime.bid.Builder, "hash", bidRuntime.bid.Hash().TerminalString())
-		b.SetBestBid(bidRuntime.bid.ParentHash, bidRuntime)
 		success = true
 		return
 	}
 
 	if bidRuntime.bid.Hash() != bestBid.bid.Hash() {
 		log.Info("[BID RESULT]",
-			"win", bidRuntime.packedBlockReward.Cmp(bestBid.packedBlockReward) >= 0,
+			"win", promoted,
 
 			"bidHash", bidRuntime.bid.Hash().TerminalString(),
 			"bestHash", bestBid.bid.Hash().TerminalString(),
@@ -727,11 +1074,11 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	}
 
 	// this is the simplest strategy: best for all the delegators.
-	if bidRuntime.packedBlockReward.Cmp(bestBid.packedBlockReward) >= 0 {
-		b.SetBestBid(bidRuntime.bid.ParentHash, bidRuntime)
+	if promoted {
 		success = true
 		return
 	}
+	wasted = true
 
 	// only recommit last best bid when newBidCh is empty
 	if len(b.newBidCh) > 0 {
@@ -746,20 +1093,97 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 }
 
 // reportIssue reports the issue to the mev-sentry
-func (b *bidSimulator) reportIssue(bidRuntime *BidRuntime, err error) {
-	metrics.GetOrRegisterCounter(fmt.Sprintf("bid/err/%v", bidRuntime.bid.Builder), nil).Inc(1)
+func (b *bidSimulator) reportIssue(bid *types.Bid, err error) {
+	metrics.GetOrRegisterCounter(fmt.Sprintf("bid/err/%v", bid.Builder), nil).Inc(1)
+
+	b.buildersMu.RLock()
+	cli := b.builders[bid.Builder]
+	b.buildersMu.RUnlock()
 
-	cli := b.builders[bidRuntime.bid.Builder]
 	if cli != nil {
 		err = cli.ReportIssue(context.Background(), &types.BidIssue{
-			Validator: bidRuntime.env.header.Coinbase,
-			Builder:   bidRuntime.bid.Builder,
-			BidHash:   bidRuntime.bid.Hash(),
+			Validator: b.bidWorker.etherbase(),
+			Builder:   bid.Builder,
+			BidHash:   bid.Hash(),
 			Message:   err.Error(),
 		})
 
 		if err != nil {
-			log.Warn("BidSimulator: failed to report issue", "builder", bidRuntime.bid.Builder, "err", err)
+			log.Warn("BidSimulator: failed to report issue", "builder", bid.Builder, "err", err)
+			b.builderFailed(bid.Builder, err)
+		} else {
+			b.builderSucceeded(bid.Builder)
+		}
+	}
+}
+
+// rejectBid drops the best bid of its parent block after the final comparison
+// with the local block, and reports the issue to the builder.
+func (b *bidSimulator) rejectBid(bid *BidRuntime, err error) {
+	bidPolicyRejectMeter.Mark(1)
+	log.Info("BidSimulator: bid rejected", "builder", bid.bid.Builder, "bidHash", bid.bid.Hash().TerminalString(), "err", err)
+	b.postBidEvent(bid.bid, bid, types.BidRejected, err)
+
+	go b.reportIssue(bid.bid, err)
+}
+
+// SubscribeBidEvent registers a subscription of the accepted, rejected and
+// promoted bids.
+func (b *bidSimulator) SubscribeBidEvent(ch chan<- types.BidEvent) event.Subscription {
+	return b.bidFeed.Subscribe(ch)
+}
+
+// SubscribeBestBidEvent registers a subscription of the bids becoming the best
+// bid of their block.
+func (b *bidSimulator) SubscribeBestBidEvent(ch chan<- types.BidEvent) event.Subscription {
+	return b.bestBidFeed.Subscribe(ch)
+}
+
+// postBidEvent queues the event of a bid for the subscribers, runtime being nil
+// if the bid was rejected before the computation of its expected rewards. The
+// event is dropped if the subscribers fall too far behind.
+func (b *bidSimulator) postBidEvent(bid *types.Bid, runtime *BidRuntime, status string, err error) {
+	ev := types.BidEvent{
+		Status:      status,
+		Builder:     bid.Builder,
+		BidHash:     bid.Hash(),
+		BlockNumber: bid.BlockNumber,
+		ParentHash:  bid.ParentHash,
+		Txs:         len(bid.Txs),
+		GasUsed:     bid.GasUsed,
+		GasFee:      bid.GasFee,
+		BuilderFee:  bid.BuilderFee,
+		Time:        uint64(time.Now().UnixMilli()),
+		Error:       errString(err),
+	}
+	if runtime != nil {
+		ev.ExpectedBlockReward = runtime.expectedBlockReward
+		ev.ExpectedValidatorReward = runtime.expectedValidatorReward
+		// accepted bids may be under simulation already
+		if status != types.BidAccepted && runtime.env != nil {
+			ev.PackedBlockReward = new(big.Int).Set(runtime.packedBlockReward)
+			ev.PackedValidatorReward = new(big.Int).Set(runtime.packedValidatorReward)
+		}
+	}
+	select {
+	case b.bidEventCh <- ev:
+	default:
+		bidEventDropMeter.Mark(1)
+	}
+}
+
+// bidEventLoop sends the bid events to the subscribers, so that the bid
+// processing never waits for them.
+func (b *bidSimulator) bidEventLoop() {
+	for {
+		select {
+		case ev := <-b.bidEventCh:
+			b.bidFeed.Send(ev)
+			if ev.Status == types.BidPromoted {
+				b.bestBidFeed.Send(ev)
+			}
+		case <-b.exitCh:
+			return
 		}
 	}
 }
@@ -777,6 +1201,7 @@ type BidRuntime struct {
 
 	finished chan struct{}
 	duration time.Duration
+	selected atomic.Bool // set when the worker builds the block from the bid
 }
 
 func newBidRuntime(newBid *types.Bid, validatorCommission uint64) (*BidRuntime, error) {
@@ -0,0 +1,251 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+)
+
+var testParentHash = common.HexToHash("0x01")
+
+func newTestBidRuntime(t *testing.T, gasFee int64) *BidRuntime {
+	t.Helper()
+
+	args := &types.BidArgs{RawBid: &types.RawBid{
+		BlockNumber: 1,
+		ParentHash:  testParentHash,
+		GasFee:      big.NewInt(gasFee),
+		BuilderFee:  common.Big0,
+	}}
+	bid, err := args.ToBid(common.Address{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newBidRuntime(bid, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func newTestSimBidReq(t *testing.T, gasFee int64) *simBidReq {
+	return &simBidReq{bid: newTestBidRuntime(t, gasFee), interruptCh: make(chan int32, 1)}
+}
+
+func TestAdmitBid(t *testing.T) {
+	b := &bidSimulator{
+		config:        &minerconfig.MevConfig{BidSimulationCandidates: 2},
+		bestBid:       make(map[common.Hash]*BidRuntime),
+		simulatingBid: make(map[common.Hash][]*simBidReq),
+	}
+	var (
+		low    = newTestSimBidReq(t, 100)
+		mid    = newTestSimBidReq(t, 200)
+		high   = newTestSimBidReq(t, 300)
+		lowest = newTestSimBidReq(t, 50)
+	)
+	// The first candidates fill the slots
+	for _, req := range []*simBidReq{low, mid} {
+		if _, ok := b.admitBid(req); !ok {
+			t.Fatalf("bid %v not admitted", req.bid.expectedBlockReward)
+		}
+	}
+	if _, ok := b.admitBid(low); ok {
+		t.Fatal("duplicate bid admitted")
+	}
+	if better, ok := b.admitBid(lowest); ok || better != low.bid {
+		t.Fatalf("worse bid admitted, lost against %v", better)
+	}
+	// A better bid evicts the worst candidate, interrupting its simulation
+	if _, ok := b.admitBid(high); !ok {
+		t.Fatal("better bid not admitted")
+	}
+	select {
+	case reason := <-low.interruptCh:
+		if reason != commitInterruptBetterBid {
+			t.Fatalf("unexpected interrupt reason %d", reason)
+		}
+	default:
+		t.Fatal("evicted candidate not interrupted")
+	}
+	if have := b.GetSimulatingBid(testParentHash); have != high.bid {
+		t.Fatalf("best simulating bid mismatch: have %v", have.expectedBlockReward)
+	}
+
+	// Bids expected worse than the best simulated one are not admitted
+	b.removeSimulatingBid(mid.bid)
+	b.removeSimulatingBid(high.bid)
+	if b.GetSimulatingBid(testParentHash) != nil {
+		t.Fatal("simulating bids not removed")
+	}
+	b.bestBid[testParentHash] = high.bid
+	if better, ok := b.admitBid(newTestSimBidReq(t, 250)); ok || better != high.bid {
+		t.Fatal("bid worse than the best bid admitted")
+	}
+}
+
+func TestPromoteBid(t *testing.T) {
+	b := &bidSimulator{bestBid: make(map[common.Hash]*BidRuntime)}
+
+	first, second, third := newTestBidRuntime(t, 100), newTestBidRuntime(t, 200), newTestBidRuntime(t, 300)
+	first.packedBlockReward = big.NewInt(100)
+	second.packedBlockReward = big.NewInt(300)
+	third.packedBlockReward = big.NewInt(200)
+
+	if last, ok := b.promoteBid(first); !ok || last != nil {
+		t.Fatal("first bid not promoted")
+	}
+	if last, ok := b.promoteBid(second); !ok || last != first {
+		t.Fatal("better bid not promoted")
+	}
+	// A simulation finishing later with a lower packed reward loses
+	if last, ok := b.promoteBid(third); ok || last != second {
+		t.Fatal("worse bid promoted")
+	}
+	if b.GetBestBid(testParentHash) != second {
+		t.Fatal("best bid mismatch")
+	}
+}
+
+func TestBidEventFeeds(t *testing.T) {
+	var (
+		b       = &bidSimulator{bidEventCh: make(chan types.BidEvent, bidEventQueueSize), exitCh: make(chan struct{})}
+		bids    = make(chan types.BidEvent, 3)
+		best    = make(chan types.BidEvent, 3)
+		bidSub  = b.SubscribeBidEvent(bids)
+		bestSub = b.SubscribeBestBidEvent(best)
+	)
+	defer bidSub.Unsubscribe()
+	defer bestSub.Unsubscribe()
+	go b.bidEventLoop()
+	defer close(b.exitCh)
+
+	bid := newTestBidRuntime(t, 100)
+	b.postBidEvent(bid.bid, bid, types.BidAccepted, nil)
+	b.postBidEvent(bid.bid, nil, types.BidRejected, errors.New("invalid"))
+	bid.env = &environment{}
+	bid.packedBlockReward = big.NewInt(100)
+	b.postBidEvent(bid.bid, bid, types.BidPromoted, nil)
+
+	for _, want := range []string{types.BidAccepted, types.BidRejected, types.BidPromoted} {
+		ev := <-bids
+		if ev.Status != want || ev.BidHash != bid.bid.Hash() {
+			t.Fatalf("unexpected bid event %+v, want status %s", ev, want)
+		}
+		switch want {
+		case types.BidAccepted:
+			if ev.ExpectedBlockReward.Int64() != 100 || ev.PackedBlockReward != nil {
+				t.Fatalf("unexpected rewards of accepted bid: %+v", ev)
+			}
+		case types.BidRejected:
+			if ev.Error != "invalid" {
+				t.Fatalf("rejected bid without error: %+v", ev)
+			}
+		case types.BidPromoted:
+			if ev.PackedBlockReward.Int64() != 100 {
+				t.Fatalf("promoted bid without packed reward: %+v", ev)
+			}
+		}
+	}
+	if ev := <-best; ev.Status != types.BidPromoted {
+		t.Fatalf("unexpected best bid event %+v", ev)
+	}
+	if len(best) != 0 {
+		t.Fatal("best bid feed notified of non promoted bids")
+	}
+
+	// A subscriber not reading its events doesn't block the bids, the events
+	// are dropped instead
+	stuck := b.SubscribeBidEvent(make(chan types.BidEvent))
+	defer stuck.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*bidEventQueueSize; i++ {
+			b.postBidEvent(bid.bid, bid, types.BidAccepted, nil)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bid events blocked by a stuck subscriber")
+	}
+}
+
+func TestDialConfiguredBuilders(t *testing.T) {
+	var (
+		db         = rawdb.NewMemoryDatabase()
+		configured = common.HexToAddress("0xb1")
+		removed    = common.HexToAddress("0xb2")
+		added      = common.HexToAddress("0xb3")
+		config     = &minerconfig.MevConfig{Builders: []minerconfig.BuilderConfig{{Address: configured}, {Address: removed}}}
+	)
+	newSimulator := func() *bidSimulator {
+		return &bidSimulator{
+			config:   config,
+			builders: make(map[common.Address]builderClient),
+			registry: newBuilderRegistry(db, config),
+		}
+	}
+	b := newSimulator()
+	b.dialSentryAndBuilders()
+	if err := b.AddBuilder(added, ""); err != nil {
+		t.Fatal(err)
+	}
+	// The builders removed from the config are dropped on restart, unlike the
+	// ones added at runtime
+	config.Builders = config.Builders[:1]
+	b = newSimulator()
+	b.dialSentryAndBuilders()
+	for addr, want := range map[common.Address]bool{configured: true, removed: false, added: true} {
+		if have := b.ExistBuilder(addr); have != want {
+			t.Errorf("builder %x dialed: have %v, want %v", addr, have, want)
+		}
+		if _, have := b.registry.get(addr); have != want {
+			t.Errorf("builder %x registered: have %v, want %v", addr, have, want)
+		}
+	}
+}
+
+func TestSentryOutageKeepsBuilders(t *testing.T) {
+	sentry, url := newFakeSentry(t)
+	pool, err := newSentryPool([]string{url}, minerconfig.SentryPolicyPriority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		builder = common.HexToAddress("0xb1")
+		config  = &minerconfig.MevConfig{BuilderMaxFailures: 1}
+		b       = &bidSimulator{
+			config:   config,
+			builders: map[common.Address]builderClient{builder: pool},
+			registry: newBuilderRegistry(rawdb.NewMemoryDatabase(), config),
+			sentry:   pool,
+		}
+	)
+	if err := b.registry.register(builder, "", builderFromConfig); err != nil {
+		t.Fatal(err)
+	}
+	// The builder isn't suspended while the sentry is down
+	sentry.down.Store(true)
+	b.checkBuilders()
+	if err := b.registry.check(builder); err != nil {
+		t.Fatalf("builder suspended by a sentry outage: %v", err)
+	}
+	// but it is when the builder itself fails
+	sentry.down.Store(false)
+	sentry.reject.Store(true)
+	err = pool.ReportIssue(context.Background(), &types.BidIssue{Builder: builder})
+	b.builderFailed(builder, err)
+	if err := b.registry.check(builder); !errors.Is(err, errBuilderSuspended) {
+		t.Fatalf("have %v, want %v", err, errBuilderSuspended)
+	}
+}
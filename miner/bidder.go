@@ -0,0 +1,396 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/parlia"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner/builderclient"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	bidderBuildTimer  = metrics.NewRegisteredTimer("bidder/build", nil)
+	bidderSentMeter   = metrics.NewRegisteredMeter("bidder/sent", nil)
+	bidderFailedMeter = metrics.NewRegisteredMeter("bidder/failed", nil)
+	bidderIssueMeter  = metrics.NewRegisteredMeter("bidder/issue", nil)
+)
+
+var (
+	errBidderDisabled   = errors.New("builder mode is not enabled")
+	errBidderNoSigner   = errors.New("builder account not authorized")
+	errBidTooLate       = errors.New("not enough time left to bid")
+	errBidNoReward      = errors.New("block without reward")
+	errBidSignerInvalid = errors.New("bid signed by an unexpected account")
+	errBidIssueUnknown  = errors.New("issue about a bid not sent to the validator")
+)
+
+// bidIssueBlocks is the number of blocks after which the validators can no
+// longer report issues about the bids sent to them.
+const bidIssueBlocks = 16
+
+// sentBidKey identifies a bid sent to a validator.
+type sentBidKey struct {
+	bidHash   common.Hash
+	validator common.Address
+}
+
+// SignerFn signs data with an account, SignerTxFn signs a transaction.
+type (
+	SignerFn   func(accounts.Account, string, []byte) ([]byte, error)
+	SignerTxFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error)
+)
+
+// bidder runs the builder side of BEP-322. It builds blocks on top of the
+// chain head out of the received bundles and the txpool, and sends them as
+// signed bids to the validators until the next block is sealed.
+type bidder struct {
+	config      *minerconfig.BidderConfig
+	chain       *core.BlockChain
+	chainConfig *params.ChainConfig
+	engine      consensus.Engine
+	worker      bidWorker
+	syncing     *atomic.Bool // set while the node is syncing
+
+	signMu   sync.RWMutex
+	signFn   SignerFn
+	signTxFn SignerTxFn
+
+	validators map[common.Address]*builderclient.Client
+
+	sentMu sync.Mutex
+	sent   map[sentBidKey]uint64 // Bids sent, not reported yet, by block number
+
+	chainHeadCh  chan core.ChainHeadEvent
+	chainHeadSub event.Subscription
+	exitCh       chan struct{}
+	wg           sync.WaitGroup
+}
+
+func newBidder(config *minerconfig.BidderConfig, engine consensus.Engine, eth Backend, worker bidWorker, syncing *atomic.Bool) *bidder {
+	b := &bidder{
+		config:      config,
+		chain:       eth.BlockChain(),
+		chainConfig: eth.BlockChain().Config(),
+		engine:      engine,
+		worker:      worker,
+		syncing:     syncing,
+		validators:  make(map[common.Address]*builderclient.Client),
+		sent:        make(map[sentBidKey]uint64),
+		chainHeadCh: make(chan core.ChainHeadEvent, chainHeadChanSize),
+		exitCh:      make(chan struct{}),
+	}
+	for _, v := range config.Validators {
+		cli, err := builderclient.DialOptions(context.Background(), v.URL, rpc.WithHTTPClient(client))
+		if err != nil {
+			log.Error("Bidder: failed to dial validator", "validator", v.Address, "url", v.URL, "err", err)
+			continue
+		}
+		b.validators[v.Address] = cli
+	}
+	if len(b.validators) == 0 {
+		log.Warn("Bidder: no valid validators")
+	}
+	b.chainHeadSub = b.chain.SubscribeChainHeadEvent(b.chainHeadCh)
+
+	b.wg.Add(1)
+	go b.loop()
+	return b
+}
+
+// authorize sets the functions signing the bids and the pay bid txs with the
+// builder account.
+func (b *bidder) authorize(signFn SignerFn, signTxFn SignerTxFn) {
+	b.signMu.Lock()
+	defer b.signMu.Unlock()
+
+	b.signFn, b.signTxFn = signFn, signTxFn
+}
+
+func (b *bidder) signers() (SignerFn, SignerTxFn) {
+	b.signMu.RLock()
+	defer b.signMu.RUnlock()
+
+	return b.signFn, b.signTxFn
+}
+
+func (b *bidder) close() {
+	close(b.exitCh)
+	b.wg.Wait()
+}
+
+// reportIssue handles the issue reported by a validator about a bid. Only
+// the validators the bid was sent to can report an issue about it, once, so
+// the reports are bounded by the bids sent.
+func (b *bidder) reportIssue(issue *types.BidIssue) error {
+	key := sentBidKey{bidHash: issue.BidHash, validator: issue.Validator}
+
+	b.sentMu.Lock()
+	_, ok := b.sent[key]
+	delete(b.sent, key)
+	b.sentMu.Unlock()
+
+	if !ok {
+		return errBidIssueUnknown
+	}
+	bidderIssueMeter.Mark(1)
+	log.Warn("Bidder: issue reported", "validator", issue.Validator, "bidHash", issue.BidHash, "msg", issue.Message)
+	return nil
+}
+
+// pruneSent drops the sent bids too old to be reported about.
+func (b *bidder) pruneSent(head uint64) {
+	b.sentMu.Lock()
+	defer b.sentMu.Unlock()
+
+	for key, number := range b.sent {
+		if number+bidIssueBlocks < head {
+			delete(b.sent, key)
+		}
+	}
+}
+
+func (b *bidder) loop() {
+	defer b.wg.Done()
+	defer b.chainHeadSub.Unsubscribe()
+
+	var stopCh chan struct{}
+	defer func() {
+		if stopCh != nil {
+			close(stopCh)
+		}
+	}()
+	for {
+		select {
+		case head := <-b.chainHeadCh:
+			if stopCh != nil {
+				close(stopCh)
+			}
+			b.pruneSent(head.Header.Number.Uint64())
+
+			stopCh = make(chan struct{})
+			b.wg.Add(1)
+			go b.bidLoop(head.Header, stopCh)
+
+		case <-b.chainHeadSub.Err():
+			return
+
+		case <-b.exitCh:
+			return
+		}
+	}
+}
+
+// bidLoop bids blocks on top of parent every bid interval, until a new head
+// arrives or the time left to seal the block runs out. A new bid is only sent
+// if its reward is higher than the last one.
+func (b *bidder) bidLoop(parent *types.Header, stopCh chan struct{}) {
+	defer b.wg.Done()
+
+	var (
+		timer   = time.NewTimer(0)
+		lastFee = new(big.Int)
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-stopCh:
+			return
+		case <-b.exitCh:
+			return
+		}
+		if b.syncing.Load() {
+			return
+		}
+		validators := b.targets(parent)
+		if len(validators) == 0 {
+			return
+		}
+		start := time.Now()
+		args, err := b.buildBid(parent, validators[0])
+		switch {
+		case errors.Is(err, errBidTooLate), errors.Is(err, errBidderNoSigner):
+			log.Debug("Bidder: stop bidding", "parent", parent.Hash(), "err", err)
+			return
+		case err != nil:
+			log.Debug("Bidder: failed to build bid", "parent", parent.Hash(), "err", err)
+		case args.RawBid.GasFee.Cmp(lastFee) > 0:
+			bidderBuildTimer.UpdateSince(start)
+			lastFee = args.RawBid.GasFee
+			b.sendBid(args, validators)
+		}
+		timer.Reset(b.config.BidInterval)
+	}
+}
+
+// targets returns the validators to bid the block on top of parent to. With
+// parlia only the in-turn validator is targeted.
+func (b *bidder) targets(parent *types.Header) []common.Address {
+	if p, ok := b.engine.(*parlia.Parlia); ok {
+		validator, err := p.NextInTurnValidator(b.chain, parent)
+		if err != nil {
+			log.Debug("Bidder: failed to get in-turn validator", "parent", parent.Hash(), "err", err)
+			return nil
+		}
+		if _, ok := b.validators[validator]; !ok {
+			return nil
+		}
+		return []common.Address{validator}
+	}
+	validators := make([]common.Address, 0, len(b.validators))
+	for validator := range b.validators {
+		validators = append(validators, validator)
+	}
+	return validators
+}
+
+// sendBid sends the bid to the validators concurrently.
+func (b *bidder) sendBid(args *types.BidArgs, validators []common.Address) {
+	hash := args.RawBid.Hash()
+
+	b.sentMu.Lock()
+	for _, validator := range validators {
+		b.sent[sentBidKey{bidHash: hash, validator: validator}] = args.RawBid.BlockNumber
+	}
+	b.sentMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, validator := range validators {
+		wg.Add(1)
+		go func(validator common.Address, cli *builderclient.Client) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), b.config.BidInterval)
+			defer cancel()
+
+			if _, err := cli.SendBid(ctx, args); err != nil {
+				bidderFailedMeter.Mark(1)
+				log.Debug("Bidder: failed to send bid", "validator", validator, "block", args.RawBid.BlockNumber, "err", err)
+				return
+			}
+			bidderSentMeter.Mark(1)
+			log.Debug("Bidder: bid sent", "validator", validator, "block", args.RawBid.BlockNumber,
+				"txs", len(args.RawBid.Txs), "gasFee", args.RawBid.GasFee, "hash", args.RawBid.Hash())
+		}(validator, b.validators[validator])
+	}
+	wg.Wait()
+}
+
+// buildBid builds a block on top of parent, the pending bundles first and the
+// txpool txs after them, and returns a signed bid for it.
+func (b *bidder) buildBid(parent *types.Header, coinbase common.Address) (*types.BidArgs, error) {
+	signFn, signTxFn := b.signers()
+	if signFn == nil || signTxFn == nil {
+		return nil, errBidderNoSigner
+	}
+	env, err := b.worker.prepareWork(&generateParams{
+		parentHash: parent.Hash(),
+		coinbase:   coinbase,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer env.discard()
+
+	// Engines without block deadline are bid to until the next head
+	if delay := b.engine.Delay(b.chain, env.header, &b.config.DelayLeftOver); delay != nil && *delay <= 0 {
+		return nil, errBidTooLate
+	}
+	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	env.gasPool.SubGas(params.SystemTxsGas)
+	env.gasPool.SubGas(params.PayBidTxGasLimit)
+
+	bundles, err := b.worker.commitBundles(env, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.worker.fillTransactions(nil, env, nil, bundleTxHashes(bundles)); err != nil {
+		log.Trace("Bidder: filling txpool transactions stopped", "err", err)
+	}
+	reward := new(big.Int)
+	for i, receipt := range env.receipts {
+		reward.Add(reward, txFee(env.txs[i], receipt, env.header.BaseFee))
+	}
+	if reward.Sign() <= 0 {
+		return nil, errBidNoReward
+	}
+
+	rawBid := &types.RawBid{
+		BlockNumber:  env.header.Number.Uint64(),
+		ParentHash:   parent.Hash(),
+		Txs:          make([]hexutil.Bytes, 0, len(env.txs)),
+		UnRevertible: unRevertibleTxs(bundles),
+		GasUsed:      env.header.GasUsed,
+		GasFee:       reward,
+		BuilderFee:   b.config.BuilderFee,
+	}
+	sidecars := make(map[uint64]*types.BlobTxSidecar, len(env.sidecars))
+	for _, sc := range env.sidecars {
+		sidecars[sc.TxIndex] = &sc.BlobTxSidecar
+	}
+	for i, tx := range env.txs {
+		if sc := sidecars[uint64(i)]; sc != nil {
+			tx = tx.WithBlobTxSidecar(sc)
+		}
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		rawBid.Txs = append(rawBid.Txs, data)
+	}
+
+	// Without a sentry in between there is no payment for the bid, the pay
+	// bid tx the validators require is a plain transfer to the builder itself.
+	account := accounts.Account{Address: b.config.Account}
+	payBidTx, err := signTxFn(account, types.NewTx(&types.LegacyTx{
+		Nonce:    env.state.GetNonce(account.Address),
+		To:       &account.Address,
+		Gas:      params.TxGas,
+		GasPrice: env.header.BaseFee,
+	}), b.chainConfig.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	payBidTxData, err := payBidTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data, err := rlp.EncodeToBytes(rawBid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signFn(account, accounts.MimetypeTextPlain, data)
+	if err != nil {
+		return nil, err
+	}
+	args := &types.BidArgs{
+		RawBid:          rawBid,
+		Signature:       signature,
+		PayBidTx:        payBidTxData,
+		PayBidTxGasUsed: params.TxGas,
+	}
+	// External signers may apply their own signing rules, make sure the
+	// validators recover the builder account.
+	if signer, err := args.EcrecoverSender(); err != nil || signer != account.Address {
+		return nil, errBidSignerInvalid
+	}
+	return args, nil
+}
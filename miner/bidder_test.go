@@ -0,0 +1,193 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/miner/builderclient"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func newTestBidder(t *testing.T) (*bidder, *worker, *testWorkerBackend) {
+	t.Helper()
+
+	w, backend := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	t.Cleanup(w.close)
+
+	config := minerconfig.DefaultBidderConfig
+	config.Account = testBankAddress
+	b := &bidder{
+		config:      &config,
+		chain:       backend.chain,
+		chainConfig: ethashChainConfig,
+		engine:      w.engine,
+		worker:      w,
+		validators:  make(map[common.Address]*builderclient.Client),
+		sent:        make(map[sentBidKey]uint64),
+	}
+	b.authorize(func(_ accounts.Account, _ string, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), testBankKey)
+	}, func(_ accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.LatestSignerForChainID(chainID), testBankKey)
+	})
+	return b, w, backend
+}
+
+func TestBuildBid(t *testing.T) {
+	b, w, backend := newTestBidder(t)
+
+	bundle := &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, 3*params.GWei)}}
+	if err := backend.txPool.AddBundle(bundle); err != nil {
+		t.Fatal(err)
+	}
+	args, err := b.buildBid(w.chain.CurrentBlock(), testUserAddress)
+	if err != nil {
+		t.Fatalf("failed to build bid: %v", err)
+	}
+	if builder, err := args.EcrecoverSender(); err != nil || builder != testBankAddress {
+		t.Fatalf("bid signer mismatch: have %v, err %v", builder, err)
+	}
+	bid, err := args.ToBid(testBankAddress, types.LatestSigner(ethashChainConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The bundle replaces the pending txpool tx with the same nonce, the pay
+	// bid tx comes last.
+	if len(bid.Txs) != 2 || bid.Txs[0].Hash() != bundle.Txs[0].Hash() {
+		t.Fatalf("unexpected bid txs %v", bid.Txs)
+	}
+	if payBidTx := bid.Txs[1]; payBidTx.Nonce() != 1 || *payBidTx.To() != testBankAddress || args.PayBidTxGasUsed != params.TxGas {
+		t.Fatalf("unexpected pay bid tx %+v", payBidTx)
+	}
+	if !bid.UnRevertible.Contains(bundle.Txs[0].Hash()) {
+		t.Fatal("bundle tx allowed to revert")
+	}
+	if want := big.NewInt(3 * params.GWei * int64(params.TxGas)); bid.GasFee.Cmp(want) != 0 || args.RawBid.GasUsed != params.TxGas {
+		t.Fatalf("bid reward mismatch: have %v gas %d, want %v", bid.GasFee, args.RawBid.GasUsed, want)
+	}
+
+	b.authorize(nil, nil)
+	if _, err := b.buildBid(w.chain.CurrentBlock(), testUserAddress); !errors.Is(err, errBidderNoSigner) {
+		t.Fatalf("unauthorized bid: have %v, want %v", err, errBidderNoSigner)
+	}
+}
+
+// fakeValidatorMevAPI is the mev API of a validator, checking the bids as
+// Miner.SendBid does before simulating them.
+type fakeValidatorMevAPI struct {
+	mu   sync.Mutex
+	bids []*BidRuntime
+}
+
+func (api *fakeValidatorMevAPI) SendBid(_ context.Context, args types.BidArgs) (common.Hash, error) {
+	builder, err := args.EcrecoverSender()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	bid, err := args.ToBid(builder, types.LatestSigner(ethashChainConfig))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	runtime, err := newBidRuntime(bid, 100)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	api.mu.Lock()
+	api.bids = append(api.bids, runtime)
+	api.mu.Unlock()
+	return bid.Hash(), nil
+}
+
+// fakeBuilderMevAPI serves the issues reported to the bidder.
+type fakeBuilderMevAPI struct{ b *bidder }
+
+func (api *fakeBuilderMevAPI) ReportIssue(issue types.BidIssue) error {
+	return api.b.reportIssue(&issue)
+}
+
+func newTestMevServer(t *testing.T, api interface{}) *builderclient.Client {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("mev", api); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	cli, err := builderclient.DialOptions(context.Background(), httpServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+// TestBidRoundTrip sends a bid built by the bidder to a validator over RPC,
+// and reports an issue about it back to the bidder.
+func TestBidRoundTrip(t *testing.T) {
+	b, w, backend := newTestBidder(t)
+
+	var (
+		validator    = testUserAddress
+		validatorAPI = new(fakeValidatorMevAPI)
+	)
+	b.validators[validator] = newTestMevServer(t, validatorAPI)
+	builder := newTestMevServer(t, &fakeBuilderMevAPI{b})
+
+	bundle := &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, 3*params.GWei)}}
+	if err := backend.txPool.AddBundle(bundle); err != nil {
+		t.Fatal(err)
+	}
+	args, err := b.buildBid(w.chain.CurrentBlock(), validator)
+	if err != nil {
+		t.Fatalf("failed to build bid: %v", err)
+	}
+	b.sendBid(args, []common.Address{validator})
+
+	// The validator recovers the builder and the bid as built
+	if len(validatorAPI.bids) != 1 {
+		t.Fatalf("bid count mismatch: have %d, want 1", len(validatorAPI.bids))
+	}
+	received := validatorAPI.bids[0]
+	if received.bid.Builder != testBankAddress || received.bid.Hash() != args.RawBid.Hash() {
+		t.Fatalf("received bid mismatch: builder %v, hash %v", received.bid.Builder, received.bid.Hash())
+	}
+	if received.bid.GasFee.Cmp(args.RawBid.GasFee) != 0 || len(received.bid.Txs) != 2 {
+		t.Fatalf("received bid content mismatch: fee %v, txs %d", received.bid.GasFee, len(received.bid.Txs))
+	}
+
+	// Only the validator the bid was sent to can report an issue about it, once
+	issue := &types.BidIssue{Validator: validator, Builder: testBankAddress, BidHash: received.bid.Hash(), Message: "simulation failed"}
+	other := *issue
+	other.Validator = common.Address{0x1}
+	if err := builder.ReportIssue(context.Background(), &other); err == nil {
+		t.Fatal("issue of another validator accepted")
+	}
+	if err := builder.ReportIssue(context.Background(), issue); err != nil {
+		t.Fatalf("failed to report issue: %v", err)
+	}
+	if err := builder.ReportIssue(context.Background(), issue); err == nil {
+		t.Fatal("issue reported twice")
+	}
+
+	// The bids too old to be reported about are dropped
+	b.sendBid(args, []common.Address{validator})
+	b.pruneSent(args.RawBid.BlockNumber + bidIssueBlocks + 1)
+	if err := b.reportIssue(issue); !errors.Is(err, errBidIssueUnknown) {
+		t.Fatalf("stale issue: have %v, want %v", err, errBidIssueUnknown)
+	}
+}
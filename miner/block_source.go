@@ -0,0 +1,83 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	blockSourceLocalMeter = metrics.NewRegisteredMeter("mev/source/local", nil)
+	blockSourceBidMeter   = metrics.NewRegisteredMeter("mev/source/bid", nil)
+
+	// rewards of the sealed blocks in gwei
+	blockSourceLocalRewardCounter  = metrics.NewRegisteredCounter("mev/source/localreward", nil)
+	blockSourceSealedRewardCounter = metrics.NewRegisteredCounter("mev/source/sealedreward", nil)
+	blockSourceDeltaCounter        = metrics.NewRegisteredCounter("mev/source/delta", nil)
+)
+
+// newBlockSourceReport creates the report of a block built locally, the best
+// bid is recorded with compareBid.
+func newBlockSourceReport(number uint64, localBlockReward, localValidatorReward *big.Int) *types.BlockSourceReport {
+	return &types.BlockSourceReport{
+		BlockNumber:          number,
+		Source:               types.BlockSourceLocal,
+		LocalBlockReward:     localBlockReward,
+		LocalValidatorReward: localValidatorReward,
+		Delta:                new(big.Int),
+	}
+}
+
+// compareBid records the best bid the local block was compared with, selected
+// reporting whether the block is built from it.
+func compareBid(report *types.BlockSourceReport, bid *BidRuntime, selected bool) {
+	var (
+		builder = bid.bid.Builder
+		hash    = bid.bid.Hash()
+	)
+	report.Builder, report.BidHash = &builder, &hash
+	report.BidBlockReward = new(big.Int).Set(bid.packedBlockReward)
+	report.BidValidatorReward = new(big.Int).Set(bid.packedValidatorReward)
+	if selected {
+		report.Source = types.BlockSourceBid
+		report.Delta = new(big.Int).Sub(report.BidBlockReward, report.LocalBlockReward)
+	}
+}
+
+// recordBlockSource persists the source report of a block once sealed and
+// written to the chain. Only the reports of the recent blocks are kept, as
+// many as the bid history.
+func (w *worker) recordBlockSource(report *types.BlockSourceReport, block *types.Block) {
+	report.BlockHash = block.Hash()
+
+	sealed := report.LocalBlockReward
+	if report.Source == types.BlockSourceBid {
+		sealed = report.BidBlockReward
+		blockSourceBidMeter.Mark(1)
+	} else {
+		blockSourceLocalMeter.Mark(1)
+	}
+	blockSourceLocalRewardCounter.Inc(toGwei(report.LocalBlockReward))
+	blockSourceSealedRewardCounter.Inc(toGwei(sealed))
+	blockSourceDeltaCounter.Inc(toGwei(report.Delta))
+
+	var (
+		db    = w.eth.ChainDb()
+		batch = db.NewBatch()
+	)
+	rawdb.WriteBlockSourceReport(batch, report)
+	if limit := w.config.Mev.BidHistoryLimit; limit > 0 && report.BlockNumber >= limit {
+		rawdb.DeleteBlockSourceReportsBelow(db, batch, report.BlockNumber-limit+1)
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to write block source report", "number", report.BlockNumber, "err", err)
+	}
+}
+
+func toGwei(wei *big.Int) int64 {
+	return new(big.Int).Div(wei, big.NewInt(params.GWei)).Int64()
+}
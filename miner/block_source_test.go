@@ -0,0 +1,84 @@
+package miner
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBlockSourceReport(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), db, 0)
+	defer w.close()
+
+	bid := newTestBidRuntime(t, 1000)
+	bid.packedBlockReward, bid.packedValidatorReward = big.NewInt(300), big.NewInt(3)
+
+	tests := []struct {
+		number   uint64
+		selected bool
+		source   string
+		delta    int64
+	}{
+		{1, false, types.BlockSourceLocal, 0},
+		{2, true, types.BlockSourceBid, 100},
+	}
+	for _, tt := range tests {
+		report := newBlockSourceReport(tt.number, big.NewInt(200), big.NewInt(2))
+		compareBid(report, bid, tt.selected)
+
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(tt.number)})
+		w.recordBlockSource(report, block)
+
+		stored := rawdb.ReadBlockSourceReport(db, tt.number)
+		if stored == nil {
+			t.Fatalf("block %d: report not stored", tt.number)
+		}
+		if stored.BlockHash != block.Hash() || stored.Source != tt.source || stored.Delta.Int64() != tt.delta {
+			t.Errorf("block %d: unexpected report %+v", tt.number, stored)
+		}
+		if stored.BidHash == nil || *stored.BidHash != bid.bid.Hash() || stored.BidBlockReward.Int64() != 300 {
+			t.Errorf("block %d: best bid not reported: %+v", tt.number, stored)
+		}
+	}
+}
+
+func TestBlockSourceReportPruning(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), db, 0)
+	defer w.close()
+
+	config := *w.config
+	config.Mev.BidHistoryLimit = 3
+	w.config = &config
+
+	// Sealed blocks are sparse, every report below the retention limit goes
+	for _, number := range []uint64{1, 2, 5, 9} {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number)})
+		w.recordBlockSource(newBlockSourceReport(number, big.NewInt(200), big.NewInt(2)), block)
+	}
+	for number, want := range map[uint64]bool{1: false, 2: false, 5: false, 9: true} {
+		if have := rawdb.ReadBlockSourceReport(db, number) != nil; have != want {
+			t.Errorf("block %d: report kept %v, want %v", number, have, want)
+		}
+	}
+}
+
+func TestBlockSourceReportJSON(t *testing.T) {
+	report := newBlockSourceReport(16, big.NewInt(200), big.NewInt(2))
+	blob, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["blockNumber"] != "0x10" || fields["localBlockReward"] != "0xc8" || fields["delta"] != "0x0" {
+		t.Fatalf("report not hex encoded: %s", blob)
+	}
+}
@@ -0,0 +1,301 @@
+package miner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+)
+
+const (
+	// builderPingTimeout is the timeout of a health check of a builder.
+	builderPingTimeout = 5 * time.Second
+
+	// builderRegistrationMaxAge is the maximum difference between the timestamp
+	// of a builder registration and the local time.
+	builderRegistrationMaxAge = 5 * time.Minute
+)
+
+var (
+	builderSuspendMeter    = metrics.NewRegisteredMeter("mev/builder/suspend", nil)
+	builderReactivateMeter = metrics.NewRegisteredMeter("mev/builder/reactivate", nil)
+
+	errBuilderNotRegistered  = errors.New("builder is not registered")
+	errBuilderSuspended      = errors.New("builder is suspended")
+	errBuilderBanned         = errors.New("builder is banned")
+	errBuilderNotAllowed     = errors.New("builder not allowed to register")
+	errRegistrationValidator = errors.New("registration for another validator")
+	errRegistrationExpired   = errors.New("registration timestamp too far from local time")
+	errRegistrationReplayed  = errors.New("registration not newer than the last accepted one")
+)
+
+// builderSource is how a builder was added to the registry.
+type builderSource int
+
+const (
+	builderFromAPI        builderSource = iota // Added at runtime through the mev API
+	builderFromConfig                          // Listed in the config
+	builderSelfRegistered                      // Registered itself with a signed registration
+)
+
+// builderRegistry keeps the status of the builders in the node database, so
+// that suspended and banned builders stay so across restarts. A builder is
+// suspended after too many consecutive failures, and reactivated once healthy.
+type builderRegistry struct {
+	db          ethdb.KeyValueStore
+	maxFailures uint64
+	allowlist   map[common.Address]struct{}
+
+	mu      sync.RWMutex
+	records map[common.Address]*types.BuilderRecord
+}
+
+func newBuilderRegistry(db ethdb.KeyValueStore, config *minerconfig.MevConfig) *builderRegistry {
+	r := &builderRegistry{
+		db:          db,
+		maxFailures: config.BuilderMaxFailures,
+		allowlist:   make(map[common.Address]struct{}, len(config.BuilderAllowlist)),
+		records:     make(map[common.Address]*types.BuilderRecord),
+	}
+	for _, addr := range config.BuilderAllowlist {
+		r.allowlist[addr] = struct{}{}
+	}
+	for _, record := range rawdb.ReadAllBuilderRecords(db) {
+		r.records[record.Address] = record
+	}
+	return r
+}
+
+// get returns a copy of the record of the builder.
+func (r *builderRegistry) get(builder common.Address) (types.BuilderRecord, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[builder]
+	if !ok {
+		return types.BuilderRecord{}, false
+	}
+	return *record, true
+}
+
+// list returns a copy of the records of all the builders, sorted by address.
+func (r *builderRegistry) list() []*types.BuilderRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]*types.BuilderRecord, 0, len(r.records))
+	for _, record := range r.records {
+		cpy := *record
+		records = append(records, &cpy)
+	}
+	slices.SortFunc(records, func(a, b *types.BuilderRecord) int {
+		return bytes.Compare(a.Address[:], b.Address[:])
+	})
+	return records
+}
+
+// check returns an error if the bids of the builder are not accepted.
+func (r *builderRegistry) check(builder common.Address) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[builder]
+	if !ok {
+		return errBuilderNotRegistered
+	}
+	switch record.Status {
+	case types.BuilderSuspended:
+		return errBuilderSuspended
+	case types.BuilderBanned:
+		return errBuilderBanned
+	}
+	return nil
+}
+
+// register adds the builder to the registry, or updates its url and source if
+// it is already registered. Banned builders can't register again.
+func (r *builderRegistry) register(builder common.Address, url string, source builderSource) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, err := r.registerLocked(builder, url, source)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteBuilderRecord(r.db, record)
+	return nil
+}
+
+// registerSigned registers a builder from its verified registration, which
+// must be newer than the last accepted one so that it can't be replayed.
+func (r *builderRegistry) registerSigned(builder common.Address, url string, timestamp uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[builder]; ok && timestamp <= record.LastRegistration {
+		return errRegistrationReplayed
+	}
+	record, err := r.registerLocked(builder, url, builderSelfRegistered)
+	if err != nil {
+		return err
+	}
+	record.LastRegistration = timestamp
+	rawdb.WriteBuilderRecord(r.db, record)
+	return nil
+}
+
+// registerLocked adds or updates the record of the builder without storing
+// it. The caller must hold the lock.
+func (r *builderRegistry) registerLocked(builder common.Address, url string, source builderSource) (*types.BuilderRecord, error) {
+	var (
+		now        = uint64(time.Now().Unix())
+		self       = source == builderSelfRegistered
+		configured = source == builderFromConfig
+	)
+	record, ok := r.records[builder]
+	if ok {
+		if record.Status == types.BuilderBanned {
+			return nil, errBuilderBanned
+		}
+		if record.URL != url || record.SelfRegister != self || record.Configured != configured {
+			record.URL, record.SelfRegister, record.Configured, record.UpdatedAt = url, self, configured, now
+		}
+		return record, nil
+	}
+	record = &types.BuilderRecord{
+		Address:      builder,
+		URL:          url,
+		Status:       types.BuilderActive,
+		RegisteredAt: now,
+		UpdatedAt:    now,
+		SelfRegister: self,
+		Configured:   configured,
+	}
+	r.records[builder] = record
+	return record, nil
+}
+
+// unconfigured returns the builders added from the config that are no longer
+// listed in it, except the banned ones whose record keeps them banned.
+func (r *builderRegistry) unconfigured(configured map[common.Address]struct{}) []common.Address {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var builders []common.Address
+	for addr, record := range r.records {
+		if _, ok := configured[addr]; !ok && record.Configured && record.Status != types.BuilderBanned {
+			builders = append(builders, addr)
+		}
+	}
+	return builders
+}
+
+// remove drops the builder from the registry.
+func (r *builderRegistry) remove(builder common.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.records[builder]; ok {
+		delete(r.records, builder)
+		rawdb.DeleteBuilderRecord(r.db, builder)
+	}
+}
+
+// setStatus sets the status of a registered builder and resets its failures.
+func (r *builderRegistry) setStatus(builder common.Address, status types.BuilderStatus, reason string) error {
+	if status > types.BuilderBanned {
+		return fmt.Errorf("invalid builder status %v", status)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[builder]
+	if !ok {
+		return errBuilderNotRegistered
+	}
+	record.Status, record.Failures, record.UpdatedAt = status, 0, uint64(time.Now().Unix())
+	record.Reason = ""
+	if status != types.BuilderActive {
+		record.Reason = reason
+	}
+	rawdb.WriteBuilderRecord(r.db, record)
+	return nil
+}
+
+// recordFailure counts a failed health check or issue report of the builder,
+// and suspends the active builder once it reaches the maximum consecutive
+// failures. It returns whether the builder was suspended.
+func (r *builderRegistry) recordFailure(builder common.Address, reason string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[builder]
+	if !ok || record.Status == types.BuilderBanned {
+		return false
+	}
+	record.Failures++
+
+	var suspended bool
+	if record.Status == types.BuilderActive && r.maxFailures > 0 && record.Failures >= r.maxFailures {
+		record.Status, record.UpdatedAt = types.BuilderSuspended, uint64(time.Now().Unix())
+		record.Reason = fmt.Sprintf("%d consecutive failures, last: %s", record.Failures, reason)
+		suspended = true
+		builderSuspendMeter.Mark(1)
+	}
+	rawdb.WriteBuilderRecord(r.db, record)
+	return suspended
+}
+
+// recordSuccess resets the failures of the builder, and reactivates it if it
+// was suspended. It returns whether the builder was reactivated.
+func (r *builderRegistry) recordSuccess(builder common.Address) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[builder]
+	if !ok || record.Status == types.BuilderBanned {
+		return false
+	}
+	if record.Failures == 0 && record.Status == types.BuilderActive {
+		return false
+	}
+	record.Failures = 0
+
+	var reactivated bool
+	if record.Status == types.BuilderSuspended {
+		record.Status, record.UpdatedAt, record.Reason = types.BuilderActive, uint64(time.Now().Unix()), ""
+		reactivated = true
+		builderReactivateMeter.Mark(1)
+	}
+	rawdb.WriteBuilderRecord(r.db, record)
+	return reactivated
+}
+
+// verifyRegistration checks the signed registration of a builder to the
+// validator, and returns the address of the builder.
+func (r *builderRegistry) verifyRegistration(reg *types.BuilderRegistration, validator common.Address, now time.Time) (common.Address, error) {
+	if reg.Validator != validator {
+		return common.Address{}, errRegistrationValidator
+	}
+	sent := time.Unix(int64(reg.Timestamp), 0)
+	if sent.Before(now.Add(-builderRegistrationMaxAge)) || sent.After(now.Add(builderRegistrationMaxAge)) {
+		return common.Address{}, errRegistrationExpired
+	}
+	builder, err := reg.EcrecoverSender()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %v", err)
+	}
+	if _, ok := r.allowlist[builder]; !ok {
+		return common.Address{}, errBuilderNotAllowed
+	}
+	return builder, nil
+}
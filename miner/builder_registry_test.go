@@ -0,0 +1,134 @@
+package miner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+)
+
+func TestBuilderRegistry(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		config  = &minerconfig.MevConfig{BuilderMaxFailures: 2}
+		builder = common.HexToAddress("0xb1")
+		r       = newBuilderRegistry(db, config)
+	)
+	if err := r.check(builder); !errors.Is(err, errBuilderNotRegistered) {
+		t.Fatalf("unregistered builder: have %v, want %v", err, errBuilderNotRegistered)
+	}
+	if err := r.register(builder, "http://builder", builderFromAPI); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.check(builder); err != nil {
+		t.Fatalf("registered builder rejected: %v", err)
+	}
+	if r.recordFailure(builder, "timeout") {
+		t.Fatal("builder suspended after a single failure")
+	}
+	if !r.recordFailure(builder, "timeout") {
+		t.Fatal("builder not suspended after max failures")
+	}
+	if err := r.check(builder); !errors.Is(err, errBuilderSuspended) {
+		t.Fatalf("suspended builder: have %v, want %v", err, errBuilderSuspended)
+	}
+	// The status is persisted across restarts
+	if record, _ := newBuilderRegistry(db, config).get(builder); record.Status != types.BuilderSuspended || record.Failures != 2 {
+		t.Fatalf("unexpected persisted record: %+v", record)
+	}
+	if !r.recordSuccess(builder) {
+		t.Fatal("healthy builder not reactivated")
+	}
+	if err := r.check(builder); err != nil {
+		t.Fatalf("reactivated builder rejected: %v", err)
+	}
+	if err := r.setStatus(builder, types.BuilderBanned, "invalid blocks"); err != nil {
+		t.Fatal(err)
+	}
+	if r.recordSuccess(builder) {
+		t.Fatal("banned builder reactivated by a health check")
+	}
+	if err := r.register(builder, "http://builder", builderFromAPI); !errors.Is(err, errBuilderBanned) {
+		t.Fatalf("banned builder registration: have %v, want %v", err, errBuilderBanned)
+	}
+	r.remove(builder)
+	if records := newBuilderRegistry(db, config).list(); len(records) != 0 {
+		t.Fatalf("removed builder persisted: %v", records)
+	}
+}
+
+func TestVerifyBuilderRegistration(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		other, _  = crypto.GenerateKey()
+		builder   = crypto.PubkeyToAddress(key.PublicKey)
+		validator = common.HexToAddress("0xa1")
+		now       = time.Unix(1700000000, 0)
+		r         = newBuilderRegistry(rawdb.NewMemoryDatabase(), &minerconfig.MevConfig{BuilderAllowlist: []common.Address{builder}})
+	)
+	sign := func(key *ecdsa.PrivateKey, validator common.Address, timestamp time.Time) *types.BuilderRegistration {
+		reg := &types.BuilderRegistration{Validator: validator, URL: "http://builder", Timestamp: uint64(timestamp.Unix())}
+		sig, err := crypto.Sign(reg.Hash().Bytes(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reg.Signature = sig
+		return reg
+	}
+	tests := []struct {
+		reg *types.BuilderRegistration
+		err error
+	}{
+		{sign(key, validator, now), nil},
+		{sign(key, validator, now.Add(-time.Minute)), nil},
+		{sign(key, common.HexToAddress("0xa2"), now), errRegistrationValidator},
+		{sign(key, validator, now.Add(-time.Hour)), errRegistrationExpired},
+		{sign(other, validator, now), errBuilderNotAllowed},
+	}
+	for i, tt := range tests {
+		have, err := r.verifyRegistration(tt.reg, validator, now)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: have %v, want %v", i, err, tt.err)
+		}
+		if err == nil && have != builder {
+			t.Errorf("test %d: recovered %s, want %s", i, have, builder)
+		}
+	}
+	tampered := sign(key, validator, now)
+	tampered.URL = "http://attacker"
+	if _, err := r.verifyRegistration(tampered, validator, now); err == nil {
+		t.Fatal("tampered registration accepted")
+	}
+}
+
+func TestBuilderRegistrationReplay(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		config  = &minerconfig.MevConfig{}
+		builder = common.HexToAddress("0xb1")
+		r       = newBuilderRegistry(db, config)
+	)
+	if err := r.registerSigned(builder, "http://builder", 100); err != nil {
+		t.Fatal(err)
+	}
+	// Registrations not newer than the last accepted one are replays, also
+	// after a restart
+	r = newBuilderRegistry(db, config)
+	for _, timestamp := range []uint64{99, 100} {
+		if err := r.registerSigned(builder, "http://attacker", timestamp); !errors.Is(err, errRegistrationReplayed) {
+			t.Fatalf("registration at %d: have %v, want %v", timestamp, err, errRegistrationReplayed)
+		}
+	}
+	if err := r.registerSigned(builder, "http://builder2", 101); err != nil {
+		t.Fatalf("newer registration rejected: %v", err)
+	}
+	if record, _ := r.get(builder); record.URL != "http://builder2" || record.LastRegistration != 101 || !record.SelfRegister {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
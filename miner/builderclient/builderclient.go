@@ -3,6 +3,7 @@ package builderclient
 import (
 	"context"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -31,3 +32,21 @@ func newClient(c *rpc.Client) *Client {
 func (ec *Client) ReportIssue(ctx context.Context, args *types.BidIssue) error {
 	return ec.c.CallContext(ctx, nil, "mev_reportIssue", args)
 }
+
+// SendBid sends a bid to a validator
+func (ec *Client) SendBid(ctx context.Context, args *types.BidArgs) (common.Hash, error) {
+	var hash common.Hash
+	err := ec.c.CallContext(ctx, &hash, "mev_sendBid", args)
+	return hash, err
+}
+
+// Ping checks that the builder is reachable
+func (ec *Client) Ping(ctx context.Context) error {
+	var version string
+	return ec.c.CallContext(ctx, &version, "web3_clientVersion")
+}
+
+// Close closes the underlying RPC connection.
+func (ec *Client) Close() {
+	ec.c.Close()
+}
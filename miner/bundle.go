@@ -0,0 +1,341 @@
+package miner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var errBundleReverted = errors.New("non reverting bundle transaction failed")
+
+// commitBundles applies the pending bundles of the txpool paying at least the
+// minimum gas tip to env, the most profitable first. At most maxBundles of
+// them are simulated, once per parent block, and they use at most
+// maxBundleGasPercent of the block gas. It returns the committed bundles. The
+// commits stop with the interruption error if the work is interrupted or the
+// timer fires.
+func (w *worker) commitBundles(env *environment, interruptCh chan int32, stopTimer *time.Timer) ([]*types.Bundle, error) {
+	bundles := w.eth.TxPool().PendingBundles(env.header.Number.Uint64(), env.header.Time)
+	if len(bundles) == 0 {
+		return nil, nil
+	}
+	tip := new(big.Int)
+	w.mu.RLock()
+	if w.tip != nil {
+		tip = w.tip.ToBig()
+	}
+	w.mu.RUnlock()
+
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+		env.gasPool.SubGas(params.SystemTxsGas)
+	}
+	simulated, err := sortBundles(env, bundles, tip, w.maxBundles, w.bundleSims, interruptCh, stopTimer)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		committed []*types.Bundle
+		gasLeft   = env.header.GasLimit / 100 * w.maxBundleGasPercent
+	)
+	for _, sim := range simulated {
+		if err := checkInterrupt(interruptCh, stopTimer); err != nil {
+			return committed, err
+		}
+		if sim.gasUsed > gasLeft {
+			continue
+		}
+		_, gasUsed, err := applyBundle(env, sim.bundle, false)
+		if err != nil {
+			log.Trace("Bundle dropped from block", "bundle", sim.bundle.Hash(), "err", err)
+			continue
+		}
+		gasLeft -= min(gasUsed, gasLeft)
+		committed = append(committed, sim.bundle)
+	}
+	return committed, nil
+}
+
+// bundleTxHashes returns the hashes of the bundle txs, nil without bundles.
+func bundleTxHashes(bundles []*types.Bundle) mapset.Set[common.Hash] {
+	if len(bundles) == 0 {
+		return nil
+	}
+	hashes := mapset.NewThreadUnsafeSet[common.Hash]()
+	for _, bundle := range bundles {
+		for _, tx := range bundle.Txs {
+			hashes.Add(tx.Hash())
+		}
+	}
+	return hashes
+}
+
+// unRevertibleTxs returns the hashes of the bundle txs not allowed to revert.
+func unRevertibleTxs(bundles []*types.Bundle) []common.Hash {
+	var hashes []common.Hash
+	for _, bundle := range bundles {
+		for _, tx := range bundle.Txs {
+			if !bundle.CanRevert(tx.Hash()) {
+				hashes = append(hashes, tx.Hash())
+			}
+		}
+	}
+	return hashes
+}
+
+// simulatedBundle is a bundle simulated on top of an environment.
+type simulatedBundle struct {
+	bundle  *types.Bundle
+	price   *big.Int // Fees paid per gas
+	gasUsed uint64
+}
+
+// sortBundles simulates the bundles on top of env and returns the ones that
+// succeed with a gas price of at least minPrice, by decreasing gas price. Only
+// the maxBundles bundles offering the highest tips are simulated, and the
+// simulations already in sims, if not nil, are reused.
+func sortBundles(env *environment, bundles []*types.Bundle, minPrice *big.Int, maxBundles int, sims *bundleSims,
+	interruptCh chan int32, stopTimer *time.Timer) ([]*simulatedBundle, error) {
+	if len(bundles) > maxBundles {
+		tips := make(map[*types.Bundle]*big.Int, len(bundles))
+		for _, bundle := range bundles {
+			tips[bundle] = bundle.GasTip(env.header.BaseFee)
+		}
+		bundles = slices.Clone(bundles)
+		slices.SortFunc(bundles, func(a, b *types.Bundle) int {
+			return tips[b].Cmp(tips[a])
+		})
+		bundles = bundles[:maxBundles]
+	}
+	simulated := make([]*simulatedBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		if err := checkInterrupt(interruptCh, stopTimer); err != nil {
+			return nil, err
+		}
+		sim, ok := sims.get(env, bundle)
+		if !ok {
+			sim = simulateBundle(env, bundle)
+			sims.put(env, bundle, sim)
+		}
+		if sim == nil || sim.price.Cmp(minPrice) < 0 {
+			continue
+		}
+		simulated = append(simulated, sim)
+	}
+	slices.SortFunc(simulated, func(a, b *simulatedBundle) int {
+		if c := b.price.Cmp(a.price); c != 0 {
+			return c
+		}
+		ha, hb := a.bundle.Hash(), b.bundle.Hash()
+		return bytes.Compare(ha[:], hb[:])
+	})
+	return simulated, nil
+}
+
+// simulateBundle simulates the bundle on top of env, it returns nil if the
+// bundle fails or uses no gas.
+func simulateBundle(env *environment, bundle *types.Bundle) *simulatedBundle {
+	fee, gasUsed, err := applyBundle(env, bundle, true)
+	if err != nil || gasUsed == 0 {
+		return nil
+	}
+	return &simulatedBundle{bundle, fee.Div(fee, new(big.Int).SetUint64(gasUsed)), gasUsed}
+}
+
+// bundleSims caches the bundle simulations on top of a parent block, so that
+// the works built on the same parent, on every recommit and for every bid,
+// don't copy the state to simulate the same bundles again. The simulations
+// only rank the bundles, which are checked again once applied.
+type bundleSims struct {
+	parent common.Hash
+	sims   map[bundleSimKey]*simulatedBundle // nil simulations for failed bundles
+	lock   sync.Mutex
+}
+
+// bundleSimKey identifies a bundle simulation on top of the parent block, the
+// coinbase receives the fees and may be used by the bundle.
+type bundleSimKey struct {
+	bundle   common.Hash
+	coinbase common.Address
+}
+
+func newBundleSims() *bundleSims {
+	return &bundleSims{sims: make(map[bundleSimKey]*simulatedBundle)}
+}
+
+// get returns the simulation of the bundle on top of the parent of env, and
+// whether it was simulated.
+func (s *bundleSims) get(env *environment, bundle *types.Bundle) (*simulatedBundle, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.parent != env.header.ParentHash {
+		return nil, false
+	}
+	sim, ok := s.sims[bundleSimKey{bundle.Hash(), env.header.Coinbase}]
+	return sim, ok
+}
+
+// put stores the simulation of the bundle on top of the parent of env,
+// dropping the simulations on top of another parent.
+func (s *bundleSims) put(env *environment, bundle *types.Bundle, sim *simulatedBundle) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.parent != env.header.ParentHash {
+		s.parent = env.header.ParentHash
+		s.sims = make(map[bundleSimKey]*simulatedBundle)
+	}
+	s.sims[bundleSimKey{bundle.Hash(), env.header.Coinbase}] = sim
+}
+
+// checkInterrupt returns the error interrupting the work, if it was
+// interrupted or the timer fired.
+func checkInterrupt(interruptCh chan int32, stopTimer *time.Timer) error {
+	if interruptCh != nil {
+		select {
+		case signal := <-interruptCh:
+			return signalToErr(signal)
+		default:
+		}
+	}
+	if stopTimer != nil {
+		select {
+		case <-stopTimer.C:
+			stopTimer.Reset(0) // re-active the timer, in case it will be used later.
+			return errBlockInterruptedByTimeout
+		default:
+		}
+	}
+	return nil
+}
+
+// applyBundle applies the bundle txs to env atomically and returns the fees
+// they paid and the gas they used. env is left untouched if any of them fails,
+// or if the bundle is only simulated.
+//
+// The journal of the state is cleared when a transaction is finalised, so a
+// bundle of a single transaction is reverted through a state snapshot and
+// only finalised once kept, while the state is copied for longer bundles.
+func applyBundle(env *environment, bundle *types.Bundle, simulate bool) (*big.Int, uint64, error) {
+	var (
+		single  = len(bundle.Txs) == 1 && env.evm.ChainConfig().IsByzantium(env.header.Number)
+		cp      = newEnvCheckpoint(env, !single)
+		fee     = new(big.Int)
+		gasUsed = env.header.GasUsed
+	)
+	for _, tx := range bundle.Txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+
+		var (
+			receipt *types.Receipt
+			err     error
+		)
+		if single {
+			receipt, err = core.ApplyTransactionPersonal(env.evm, env.gasPool, env.state, env.header, tx,
+				&env.header.GasUsed, core.NewReceiptBloomGenerator())
+		} else {
+			receipt, err = core.ApplyTransaction(env.evm, env.gasPool, env.state, env.header, tx,
+				&env.header.GasUsed, core.NewReceiptBloomGenerator())
+		}
+		if err == nil && receipt.Status == types.ReceiptStatusFailed && !bundle.CanRevert(tx.Hash()) {
+			err = errBundleReverted
+		}
+		if err != nil {
+			cp.restore(env)
+			return nil, 0, fmt.Errorf("tx %s: %w", tx.Hash(), err)
+		}
+		env.txs = append(env.txs, tx)
+		env.receipts = append(env.receipts, receipt)
+		env.tcount++
+		env.size += uint32(tx.Size())
+		fee.Add(fee, txFee(tx, receipt, env.header.BaseFee))
+	}
+	gasUsed = env.header.GasUsed - gasUsed
+
+	switch {
+	case simulate:
+		cp.restore(env)
+	case single:
+		env.state.Finalise(true)
+	}
+	return fee, gasUsed, nil
+}
+
+// txFee returns the fee the block producer earns from the transaction.
+func txFee(tx *types.Transaction, receipt *types.Receipt, baseFee *big.Int) *big.Int {
+	tip, err := tx.EffectiveGasTip(baseFee)
+	if err != nil {
+		return new(big.Int)
+	}
+	fee := tip.Mul(tip, new(big.Int).SetUint64(receipt.GasUsed))
+	if receipt.BlobGasPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(receipt.BlobGasPrice, new(big.Int).SetUint64(receipt.BlobGasUsed)))
+	}
+	return fee
+}
+
+// envCheckpoint is a checkpoint of an environment that can be restored. The
+// state is either snapshotted, if no transaction is finalised before the
+// restore, or copied.
+type envCheckpoint struct {
+	state    *state.StateDB // Copy of the state, nil if snapshotted
+	snapshot int
+	gas      uint64
+	gasUsed  uint64
+	txs      int
+	tcount   int
+	size     uint32
+}
+
+func newEnvCheckpoint(env *environment, copyState bool) *envCheckpoint {
+	cp := &envCheckpoint{
+		gas:     env.gasPool.Gas(),
+		gasUsed: env.header.GasUsed,
+		txs:     len(env.txs),
+		tcount:  env.tcount,
+		size:    env.size,
+	}
+	if copyState {
+		cp.state = env.state.Copy()
+	} else {
+		cp.snapshot = env.state.Snapshot()
+	}
+	return cp
+}
+
+// restore resets env to the checkpoint. The checkpoint can't be reused.
+func (cp *envCheckpoint) restore(env *environment) {
+	if cp.state != nil {
+		env.state.StopPrefetcher()
+		env.state = cp.state
+		env.evm.StateDB = cp.state
+	} else {
+		env.state.RevertToSnapshot(cp.snapshot)
+	}
+	env.gasPool.SetGas(cp.gas)
+	env.header.GasUsed = cp.gasUsed
+	env.txs = env.txs[:cp.txs]
+	env.receipts = env.receipts[:cp.txs]
+	env.tcount = cp.tcount
+	env.size = cp.size
+}
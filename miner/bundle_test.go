@@ -0,0 +1,199 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newTestBundleTx(t *testing.T, nonce uint64, tip int64) *types.Transaction {
+	t.Helper()
+
+	return types.MustSignNewTx(testBankKey, types.LatestSigner(ethashChainConfig), &types.DynamicFeeTx{
+		ChainID:   ethashChainConfig.ChainID,
+		Nonce:     nonce,
+		To:        &testUserAddress,
+		Value:     big.NewInt(1000),
+		Gas:       params.TxGas,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(10 * params.InitialBaseFee),
+	})
+}
+
+func TestCommitBundle(t *testing.T) {
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	env, err := w.prepareWork(&generateParams{coinbase: testBankAddress}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.discard()
+	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+
+	// A bundle with an invalid tx leaves the environment untouched
+	invalid := &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, 1), newTestBundleTx(t, 5, 1)}}
+	if _, _, err := applyBundle(env, invalid, false); err == nil {
+		t.Fatal("invalid bundle committed")
+	}
+	if len(env.txs) != 0 || env.header.GasUsed != 0 || env.state.GetNonce(testBankAddress) != 0 {
+		t.Fatal("failed bundle not reverted")
+	}
+
+	// Competing bundles are sorted by gas price, only the best one fits
+	var (
+		low  = &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, params.GWei)}}
+		high = &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, 3*params.GWei)}}
+	)
+	sorted, err := sortBundles(env, []*types.Bundle{low, invalid, high}, common.Big0, 10, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sorted) != 2 || sorted[0].bundle != high || sorted[1].bundle != low || sorted[0].gasUsed != params.TxGas {
+		t.Fatalf("unexpected bundle order %v", sorted)
+	}
+	if len(env.txs) != 0 || env.state.GetNonce(testBankAddress) != 0 {
+		t.Fatal("bundle simulation not reverted")
+	}
+	// Only the bundles offering the best tips are simulated
+	if sorted, _ = sortBundles(env, []*types.Bundle{low, high}, common.Big0, 1, nil, nil, nil); len(sorted) != 1 || sorted[0].bundle != high {
+		t.Fatalf("unexpected capped bundles %v", sorted)
+	}
+	fee, gasUsed, err := applyBundle(env, high, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(3 * params.GWei * int64(params.TxGas)); fee.Cmp(want) != 0 || gasUsed != params.TxGas {
+		t.Fatalf("bundle fee mismatch: have %v %d, want %v %d", fee, gasUsed, want, params.TxGas)
+	}
+	if _, _, err := applyBundle(env, low, false); err == nil {
+		t.Fatal("conflicting bundle committed")
+	}
+	if len(env.txs) != 1 || env.txs[0] != high.Txs[0] {
+		t.Fatal("committed bundle txs mismatch")
+	}
+}
+
+func TestBundleSimsReuse(t *testing.T) {
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	env, err := w.prepareWork(&generateParams{coinbase: testBankAddress}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.discard()
+	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+
+	var (
+		sims   = newBundleSims()
+		bundle = &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, params.GWei)}}
+	)
+	if sorted, _ := sortBundles(env, []*types.Bundle{bundle}, common.Big0, 10, sims, nil, nil); len(sorted) != 1 {
+		t.Fatalf("bundle not simulated: %v", sorted)
+	}
+	// The simulations on top of the same parent are reused
+	sims.put(env, bundle, nil)
+	if sorted, _ := sortBundles(env, []*types.Bundle{bundle}, common.Big0, 10, sims, nil, nil); len(sorted) != 0 {
+		t.Fatalf("bundle simulated again: %v", sorted)
+	}
+	// But not on top of another parent or for another coinbase
+	env.header.Coinbase = testUserAddress
+	if sorted, _ := sortBundles(env, []*types.Bundle{bundle}, common.Big0, 10, sims, nil, nil); len(sorted) != 1 {
+		t.Fatalf("bundle simulation reused for another coinbase: %v", sorted)
+	}
+	env.header.ParentHash = common.Hash{0x1}
+	if _, ok := sims.get(env, bundle); ok {
+		t.Fatal("bundle simulation reused on top of another parent")
+	}
+}
+
+func TestCommitBundles(t *testing.T) {
+	w, backend := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+	w.setGasTip(big.NewInt(2 * params.GWei))
+
+	var (
+		cheap     = &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, params.GWei)}}
+		reverting = &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, 3*params.GWei), newTestBundleTx(t, 1, 3*params.GWei)}}
+		later     = &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, 4*params.GWei)}, BlockNumber: 2, MaxBlockNumber: 2}
+	)
+	reverting.RevertingTxHashes = []common.Hash{reverting.Txs[1].Hash()}
+	for _, bundle := range []*types.Bundle{cheap, reverting, later} {
+		if err := backend.txPool.AddBundle(bundle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env, err := w.prepareWork(&generateParams{coinbase: testBankAddress}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.discard()
+
+	// Only the bundle of the block paying the minimum tip is committed
+	bundles, err := w.commitBundles(env, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundleTxs := bundleTxHashes(bundles)
+	if bundleTxs.Cardinality() != 2 || !bundleTxs.Contains(reverting.Txs[0].Hash()) || !bundleTxs.Contains(reverting.Txs[1].Hash()) {
+		t.Fatalf("unexpected committed bundle txs %v", bundleTxs)
+	}
+	if unRevertible := unRevertibleTxs(bundles); len(unRevertible) != 1 || unRevertible[0] != reverting.Txs[0].Hash() {
+		t.Fatalf("unexpected unrevertible txs %v", unRevertible)
+	}
+	if len(env.txs) != 2 || env.gasPool == nil {
+		t.Fatalf("bundle not committed, have %d txs", len(env.txs))
+	}
+}
+
+func TestCommitBundlesLimits(t *testing.T) {
+	w, backend := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	bundle := &types.Bundle{Txs: types.Transactions{newTestBundleTx(t, 0, params.GWei)}}
+	if err := backend.txPool.AddBundle(bundle); err != nil {
+		t.Fatal(err)
+	}
+	env, err := w.prepareWork(&generateParams{coinbase: testBankAddress}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.discard()
+
+	// The interrupted work commits no bundle
+	interruptCh := make(chan int32, 1)
+	interruptCh <- commitInterruptNewHead
+	if _, err := w.commitBundles(env, interruptCh, nil); !errors.Is(err, errBlockInterruptedByNewHead) {
+		t.Fatalf("interrupted commit: have %v, want %v", err, errBlockInterruptedByNewHead)
+	}
+	stopTimer := time.NewTimer(0)
+	defer stopTimer.Stop()
+	<-stopTimer.C
+	stopTimer.Reset(0)
+	time.Sleep(10 * time.Millisecond)
+	if _, err := w.commitBundles(env, nil, stopTimer); !errors.Is(err, errBlockInterruptedByTimeout) {
+		t.Fatalf("timed out commit: have %v, want %v", err, errBlockInterruptedByTimeout)
+	}
+	if len(env.txs) != 0 {
+		t.Fatal("bundle committed after the interruption")
+	}
+	// Bundles exceeding the gas share are skipped
+	w.maxBundleGasPercent = 1
+	env.header.GasLimit = params.TxGas * 50
+	if bundles, err := w.commitBundles(env, nil, nil); err != nil || len(bundles) != 0 {
+		t.Fatalf("bundle over the gas limit committed: %v %v", bundles, err)
+	}
+	w.maxBundleGasPercent = 2
+	if bundles, err := w.commitBundles(env, nil, nil); err != nil || len(bundles) != 1 {
+		t.Fatalf("bundle within the gas limit not committed: %v %v", bundles, err)
+	}
+}
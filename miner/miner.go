@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/miner/minerconfig"
@@ -41,6 +42,7 @@ import (
 type Backend interface {
 	BlockChain() *core.BlockChain
 	TxPool() *txpool.TxPool
+	ChainDb() ethdb.Database
 }
 
 // Miner is the main object which takes care of submitting new work to consensus
@@ -55,11 +57,12 @@ type Miner struct {
 	worker  *worker
 
 	bidSimulator *bidSimulator
+	bidder       *bidder // nil unless the builder mode is enabled
 
 	wg sync.WaitGroup
 }
 
-func New(eth Backend, config *minerconfig.Config, mux *event.TypeMux, engine consensus.Engine) *Miner {
+func New(eth Backend, config *minerconfig.Config, mux *event.TypeMux, engine consensus.Engine) (*Miner, error) {
 	miner := &Miner{
 		mux:     mux,
 		eth:     eth,
@@ -70,12 +73,20 @@ func New(eth Backend, config *minerconfig.Config, mux *event.TypeMux, engine con
 		worker:  newWorker(config, engine, eth, mux, false),
 	}
 
-	miner.bidSimulator = newBidSimulator(&config.Mev, config.DelayLeftOver, config.GasPrice, eth, eth.BlockChain().Config(), engine, miner.worker)
+	bidSimulator, err := newBidSimulator(&config.Mev, config.DelayLeftOver, config.GasPrice, eth, eth.BlockChain().Config(), engine, miner.worker)
+	if err != nil {
+		miner.worker.close()
+		return nil, err
+	}
+	miner.bidSimulator = bidSimulator
 	miner.worker.setBestBidFetcher(miner.bidSimulator)
+	if config.Bidder.Enabled {
+		miner.bidder = newBidder(&config.Bidder, engine, eth, miner.worker, &miner.worker.syncing)
+	}
 
 	miner.wg.Add(1)
 	go miner.update()
-	return miner
+	return miner, nil
 }
 
 // update keeps track of the downloader events. Please be aware that this is a one shot type of update loop.
@@ -148,6 +159,9 @@ func (miner *Miner) update() {
 		case <-miner.exitCh:
 			miner.worker.close()
 			miner.bidSimulator.close()
+			if miner.bidder != nil {
+				miner.bidder.close()
+			}
 			return
 		}
 	}
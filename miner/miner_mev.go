@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -37,19 +38,52 @@ func (miner *Miner) RemoveBuilder(builderAddr common.Address) error {
 	return miner.bidSimulator.RemoveBuilder(builderAddr)
 }
 
+// SentryStatus returns the status of the Mev sentries.
+func (miner *Miner) SentryStatus() []SentryStatus {
+	return miner.bidSimulator.SentryStatus()
+}
+
+// SubscribeBidEvent registers a subscription of the accepted, rejected and
+// promoted bids.
+func (miner *Miner) SubscribeBidEvent(ch chan<- types.BidEvent) event.Subscription {
+	return miner.bidSimulator.SubscribeBidEvent(ch)
+}
+
+// SubscribeBestBidEvent registers a subscription of the bids becoming the best
+// bid of their block.
+func (miner *Miner) SubscribeBestBidEvent(ch chan<- types.BidEvent) event.Subscription {
+	return miner.bidSimulator.SubscribeBestBidEvent(ch)
+}
+
 // HasBuilder returns true if the builder is in the builder list.
 func (miner *Miner) HasBuilder(builder common.Address) bool {
 	return miner.bidSimulator.ExistBuilder(builder)
 }
 
+// RegisterBuilder registers a builder allowed to register itself with a
+// registration signed by the builder.
+func (miner *Miner) RegisterBuilder(reg *types.BuilderRegistration) error {
+	return miner.bidSimulator.RegisterBuilder(reg)
+}
+
+// Builders returns the records of the builders in the registry.
+func (miner *Miner) Builders() []*types.BuilderRecord {
+	return miner.bidSimulator.Builders()
+}
+
+// SetBuilderStatus activates, suspends or bans a registered builder.
+func (miner *Miner) SetBuilderStatus(builder common.Address, status types.BuilderStatus, reason string) error {
+	return miner.bidSimulator.SetBuilderStatus(builder, status, reason)
+}
+
 func (miner *Miner) SendBid(ctx context.Context, bidArgs *types.BidArgs) (common.Hash, error) {
 	builder, err := bidArgs.EcrecoverSender()
 	if err != nil {
 		return common.Hash{}, types.NewInvalidBidError(fmt.Sprintf("invalid signature:%v", err))
 	}
 
-	if !miner.bidSimulator.ExistBuilder(builder) {
-		return common.Hash{}, types.NewInvalidBidError("builder is not registered")
+	if err := miner.bidSimulator.CheckBuilder(builder); err != nil {
+		return common.Hash{}, types.NewInvalidBidError(err.Error())
 	}
 
 	err = miner.bidSimulator.CheckPending(bidArgs.RawBid.BlockNumber, builder, bidArgs.RawBid.Hash())
@@ -105,3 +139,19 @@ func (miner *Miner) MevParams() *types.MevParams {
 		Version:               version.Semantic,
 	}
 }
+
+// AuthorizeBidder sets the functions signing the bids of the builder mode.
+func (miner *Miner) AuthorizeBidder(signFn SignerFn, signTxFn SignerTxFn) {
+	if miner.bidder != nil {
+		miner.bidder.authorize(signFn, signTxFn)
+	}
+}
+
+// ReportIssue receives the issue reported by a validator about a bid sent in
+// builder mode. Issues about the bids not sent to the validator are rejected.
+func (miner *Miner) ReportIssue(issue *types.BidIssue) error {
+	if miner.bidder == nil {
+		return errBidderDisabled
+	}
+	return miner.bidder.reportIssue(issue)
+}
@@ -40,7 +40,11 @@ type Config struct {
 
 	DisableVoteAttestation bool // Whether to skip assembling vote attestation
 
-	Mev MevConfig // Mev configuration
+	MaxBundles          int    // Maximum number of pending bundles simulated for a block, the best paying first
+	MaxBundleGasPercent uint64 // Maximum share of the block gas limit used by the bundles, in percent
+
+	Mev    MevConfig    // Mev configuration
+	Bidder BidderConfig // Builder mode configuration
 }
 
 // DefaultConfig contains default settings for miner.
@@ -59,12 +63,17 @@ var DefaultConfig = Config{
 	// Because the avg restart time in mainnet is around 30s, so the node try to wait for the next multi-proposals to be done.
 	MaxWaitProposalInSecs: 30,
 
-	Mev: DefaultMevConfig,
+	MaxBundles:          100,
+	MaxBundleGasPercent: 50,
+
+	Mev:    DefaultMevConfig,
+	Bidder: DefaultBidderConfig,
 }
 
 type BuilderConfig struct {
-	Address common.Address
-	URL     string
+	Address            common.Address
+	URL                string
+	MinValidatorReward *big.Int `toml:",omitempty"` // Minimum validator reward of the builder bids, overrides BidPolicy.MinValidatorReward
 }
 
 type MevConfig struct {
@@ -72,15 +81,79 @@ type MevConfig struct {
 	GreedyMergeTx         bool            // Whether to merge local transactions to the bid
 	BuilderFeeCeil        string          // The maximum builder fee of a bid
 	SentryURL             string          // The url of Mev sentry
+	SentryURLs            []string        `toml:",omitempty"` // The urls of the fallback Mev sentries, tried after SentryURL
+	SentryPolicy          string          // The order the sentries are used in, "priority" or "round-robin"
 	Builders              []BuilderConfig // The list of builders
 	ValidatorCommission   uint64          // 100 means the validator claims 1% from block reward
 	BidSimulationLeftOver time.Duration
+
+	BidSimulationWorkers    int    // Number of bids simulated concurrently
+	BidSimulationCandidates int    // Number of best bids simulated concurrently per parent block
+	BidHistoryLimit         uint64 // Number of recent blocks whose received bids are kept, 0 disables the history
+	BidDumpDir              string // Directory the failed bid simulations are dumped to, for replay with geth mev replay-bid
+	BidDumpMaxFiles         int    // Maximum number of dumps kept in BidDumpDir, the oldest removed first
+
+	BidPolicy BidPolicyConfig // Acceptance policy of the bids
+
+	BuilderAllowlist           []common.Address `toml:",omitempty"` // Builders allowed to register themselves, empty disables the self-registration
+	BuilderMaxFailures         uint64           // Consecutive failed health checks or issue reports suspending a builder, 0 disables the suspension
+	BuilderHealthCheckInterval time.Duration    // Interval between two health checks of the builders, 0 disables the health checks
+	SentryHealthCheckInterval  time.Duration    // Interval between two health checks of the sentries, 0 disables the health checks
 }
 
+// Policies choosing the sentry the calls to the builders go through.
+const (
+	SentryPolicyPriority   = "priority"    // The first healthy sentry in the configured order
+	SentryPolicyRoundRobin = "round-robin" // The healthy sentries in turn
+)
+
 var DefaultMevConfig = MevConfig{
 	Enabled:               false,
 	SentryURL:             "",
+	SentryPolicy:          SentryPolicyPriority,
 	Builders:              nil,
 	ValidatorCommission:   100,
 	BidSimulationLeftOver: 50 * time.Millisecond,
+
+	BidSimulationWorkers:    4,
+	BidSimulationCandidates: 3,
+	BidHistoryLimit:         50000,
+	BidDumpMaxFiles:         1000,
+
+	BuilderMaxFailures:         5,
+	BuilderHealthCheckInterval: 30 * time.Second,
+	SentryHealthCheckInterval:  10 * time.Second,
+}
+
+// BidPolicyConfig is the acceptance policy the validator applies to the bids on
+// top of the BEP-322 checks. Rejected bids are reported to their builder.
+type BidPolicyConfig struct {
+	MinValidatorReward *big.Int         `toml:",omitempty"` // Minimum validator reward of a bid
+	MaxTxCount         int              // Maximum number of txs in a bid, pay bid tx excluded, 0 means no limit
+	BlackList          []common.Address `toml:",omitempty"` // Senders and recipients rejected on top of types.NanoBlackList
+	MandatoryAccounts  []common.Address `toml:",omitempty"` // Local accounts whose next pending tx must be in the blocks built from bids
+	MaxRewardRatio     uint64           // Maximum bid block reward in percent of the local block reward, 0 means no limit
+}
+
+type ValidatorConfig struct {
+	Address common.Address
+	URL     string
+}
+
+// BidderConfig is the configuration of the builder mode, in which the node
+// builds blocks out of the received bundles and the txpool and bids them to
+// the validators (BEP-322).
+type BidderConfig struct {
+	Enabled       bool              // Whether to run the node as a builder
+	Account       common.Address    // Account signing the bids and paying the pay bid txs
+	Validators    []ValidatorConfig // The list of validators bids are sent to
+	BuilderFee    *big.Int          `toml:",omitempty"` // Fee claimed from the validators for each bid
+	BidInterval   time.Duration     // Interval between two bids for the same block
+	DelayLeftOver time.Duration     // Time before the block deadline the bidding stops
+}
+
+var DefaultBidderConfig = BidderConfig{
+	Enabled:       false,
+	BidInterval:   500 * time.Millisecond,
+	DelayLeftOver: 150 * time.Millisecond,
 }
@@ -0,0 +1,264 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner/builderclient"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	sentryFailoverMeter = metrics.NewRegisteredMeter("mev/sentry/failover", nil)
+
+	errNoSentry = errors.New("no sentry available")
+
+	// errSentryUnavailable wraps the error of a call no sentry could relay, so
+	// that it isn't held against the builder.
+	errSentryUnavailable = errors.New("sentry unavailable")
+)
+
+// builderClient is the connection to a builder, direct or through the sentries.
+type builderClient interface {
+	ReportIssue(ctx context.Context, args *types.BidIssue) error
+	Ping(ctx context.Context) error
+}
+
+// SentryStatus is the status of a sentry endpoint.
+type SentryStatus struct {
+	URL       string `json:"url"`
+	Active    bool   `json:"active"` // Whether the last successful call went through the sentry
+	Healthy   bool   `json:"healthy"`
+	Failures  uint64 `json:"failures"` // Consecutive failed calls and health checks
+	LastError string `json:"lastError,omitempty"`
+	LastCheck uint64 `json:"lastCheck,omitempty"` // Unix time in seconds of the last call or health check
+}
+
+type sentryEndpoint struct {
+	url string
+	cli *builderclient.Client // nil until dialed, reset on failure to redial
+
+	healthy   bool
+	failures  uint64
+	lastErr   error
+	lastCheck time.Time
+}
+
+// sentryPool sends the calls to the builders through a list of sentries. The
+// healthy sentries are tried first, in the configured order with the priority
+// policy or in turn with the round-robin policy. A failing sentry is redialed
+// on its next use, and the others are tried meanwhile.
+type sentryPool struct {
+	roundRobin bool
+
+	mu        sync.Mutex
+	endpoints []*sentryEndpoint
+	active    int // index of the sentry of the last successful call
+	next      int // index of the sentry the next round starts with
+}
+
+func newSentryPool(urls []string, policy string) (*sentryPool, error) {
+	if len(urls) == 0 {
+		return nil, errNoSentry
+	}
+	p := &sentryPool{endpoints: make([]*sentryEndpoint, 0, len(urls))}
+	switch policy {
+	case minerconfig.SentryPolicyPriority, "":
+	case minerconfig.SentryPolicyRoundRobin:
+		p.roundRobin = true
+	default:
+		return nil, fmt.Errorf("unknown sentry policy %q", policy)
+	}
+	for _, url := range urls {
+		// sentries are healthy until proven otherwise
+		p.endpoints = append(p.endpoints, &sentryEndpoint{url: url, healthy: true})
+	}
+	return p, nil
+}
+
+// sentryURLs returns the configured sentries, the legacy single sentry first.
+func sentryURLs(config *minerconfig.MevConfig) []string {
+	var urls []string
+	if config.SentryURL != "" {
+		urls = append(urls, config.SentryURL)
+	}
+	for _, url := range config.SentryURLs {
+		if url != "" && url != config.SentryURL {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// order returns the sentries in the order they are tried, the unhealthy ones
+// last.
+func (p *sentryPool) order() []*sentryEndpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	start := 0
+	if p.roundRobin {
+		start = p.next
+		p.next = (p.next + 1) % len(p.endpoints)
+	}
+	var healthy, unhealthy []*sentryEndpoint
+	for i := range p.endpoints {
+		e := p.endpoints[(start+i)%len(p.endpoints)]
+		if e.healthy {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// client returns the client of the sentry, dialing it if needed.
+func (p *sentryPool) client(e *sentryEndpoint) (*builderclient.Client, error) {
+	p.mu.Lock()
+	cli := e.cli
+	p.mu.Unlock()
+	if cli != nil {
+		return cli, nil
+	}
+	cli, err := builderclient.DialOptions(context.Background(), e.url, rpc.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e.cli != nil {
+		// dialed concurrently
+		cli.Close()
+		return e.cli, nil
+	}
+	e.cli = cli
+	return cli, nil
+}
+
+// record updates the status of the sentry after a call or a health check made
+// with cli, nil if it couldn't be dialed. A failed client is only closed if the
+// sentry still uses it, a concurrent call may have redialed it already.
+func (p *sentryPool) record(e *sentryEndpoint, cli *builderclient.Client, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e.lastCheck = time.Now()
+	if err == nil {
+		if !e.healthy {
+			log.Info("BidSimulator: sentry recovered", "url", e.url)
+		}
+		e.healthy, e.failures, e.lastErr = true, 0, nil
+		return
+	}
+	if e.healthy {
+		log.Warn("BidSimulator: sentry failed", "url", e.url, "err", err)
+	}
+	e.healthy, e.lastErr = false, err
+	e.failures++
+	if cli != nil && e.cli == cli {
+		e.cli.Close()
+		e.cli = nil
+	}
+}
+
+// call runs fn against the sentries until one relays it. An error response
+// relayed by a sentry comes from the builder, so it is returned as is without
+// failing over, and the sentry stays healthy. If no sentry could relay the
+// call, the error wraps errSentryUnavailable.
+func (p *sentryPool) call(fn func(*builderclient.Client) error) error {
+	err := errNoSentry
+	for i, e := range p.order() {
+		var cli *builderclient.Client
+		if cli, err = p.client(e); err == nil {
+			err = fn(cli)
+		}
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			p.record(e, cli, nil)
+			return err
+		}
+		p.record(e, cli, err)
+		if err == nil {
+			if i > 0 {
+				sentryFailoverMeter.Mark(1)
+			}
+			p.mu.Lock()
+			for j := range p.endpoints {
+				if p.endpoints[j] == e {
+					p.active = j
+				}
+			}
+			p.mu.Unlock()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v", errSentryUnavailable, err)
+}
+
+// ReportIssue reports the issue to the builder through the sentries.
+func (p *sentryPool) ReportIssue(ctx context.Context, args *types.BidIssue) error {
+	return p.call(func(cli *builderclient.Client) error {
+		return cli.ReportIssue(ctx, args)
+	})
+}
+
+// Ping checks that a sentry is reachable.
+func (p *sentryPool) Ping(ctx context.Context) error {
+	return p.call(func(cli *builderclient.Client) error {
+		return cli.Ping(ctx)
+	})
+}
+
+// healthCheck pings all the sentries concurrently, so that the failed ones are
+// redialed and used again once they recover.
+func (p *sentryPool) healthCheck() {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *sentryEndpoint) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), builderPingTimeout)
+			defer cancel()
+
+			cli, err := p.client(e)
+			if err == nil {
+				err = cli.Ping(ctx)
+			}
+			p.record(e, cli, err)
+		}(e)
+	}
+	wg.Wait()
+}
+
+// status returns the status of the sentries in the configured order.
+func (p *sentryPool) status() []SentryStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := make([]SentryStatus, 0, len(p.endpoints))
+	for i, e := range p.endpoints {
+		s := SentryStatus{
+			URL:      e.url,
+			Active:   i == p.active && e.healthy,
+			Healthy:  e.healthy,
+			Failures: e.failures,
+		}
+		if e.lastErr != nil {
+			s.LastError = e.lastErr.Error()
+		}
+		if !e.lastCheck.IsZero() {
+			s.LastCheck = uint64(e.lastCheck.Unix())
+		}
+		status = append(status, s)
+	}
+	return status
+}
@@ -0,0 +1,208 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner/minerconfig"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var errBuilderRejected = errors.New("issue rejected by the builder")
+
+// fakeSentry is a sentry serving mev_reportIssue and web3_clientVersion. It
+// answers with an HTTP error while down, and relays a builder error response
+// to the issue reports while rejecting.
+type fakeSentry struct {
+	down    atomic.Bool
+	reject  atomic.Bool
+	reports atomic.Int32
+}
+
+type fakeSentryMevAPI struct{ s *fakeSentry }
+
+func (api *fakeSentryMevAPI) ReportIssue(issue types.BidIssue) error {
+	if api.s.reject.Load() {
+		return errBuilderRejected
+	}
+	api.s.reports.Add(1)
+	return nil
+}
+
+type fakeSentryWeb3API struct{}
+
+func (api *fakeSentryWeb3API) ClientVersion() string {
+	return "fake-sentry"
+}
+
+func newFakeSentry(t *testing.T) (*fakeSentry, string) {
+	t.Helper()
+
+	sentry := new(fakeSentry)
+	server := rpc.NewServer()
+	if err := server.RegisterName("mev", &fakeSentryMevAPI{sentry}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("web3", new(fakeSentryWeb3API)); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sentry.down.Load() {
+			http.Error(w, "sentry down", http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return sentry, httpServer.URL
+}
+
+func TestSentryPriorityFailover(t *testing.T) {
+	var (
+		first, firstURL   = newFakeSentry(t)
+		second, secondURL = newFakeSentry(t)
+		issue             = &types.BidIssue{Message: "test"}
+	)
+	pool, err := newSentryPool([]string{firstURL, secondURL}, minerconfig.SentryPolicyPriority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.ReportIssue(context.Background(), issue); err != nil {
+		t.Fatal(err)
+	}
+	if first.reports.Load() != 1 || second.reports.Load() != 0 {
+		t.Fatalf("issue not reported to the first sentry: %d, %d", first.reports.Load(), second.reports.Load())
+	}
+	// The second sentry takes over while the first is down
+	first.down.Store(true)
+	for i := 0; i < 2; i++ {
+		if err := pool.ReportIssue(context.Background(), issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if second.reports.Load() != 2 {
+		t.Fatalf("issues not reported to the second sentry: %d", second.reports.Load())
+	}
+	status := pool.status()
+	if status[0].Healthy || status[0].Failures != 1 || status[0].LastError == "" || !status[1].Active {
+		t.Fatalf("unexpected status after failover: %+v", status)
+	}
+	// The first sentry is used again once the health check sees it recovered
+	first.down.Store(false)
+	pool.healthCheck()
+	if err := pool.ReportIssue(context.Background(), issue); err != nil {
+		t.Fatal(err)
+	}
+	if first.reports.Load() != 2 {
+		t.Fatalf("issue not reported to the recovered sentry: %d", first.reports.Load())
+	}
+	if status := pool.status(); !status[0].Healthy || !status[0].Active || status[1].Active {
+		t.Fatalf("unexpected status after recovery: %+v", status)
+	}
+	// All sentries down
+	first.down.Store(true)
+	second.down.Store(true)
+	if err := pool.ReportIssue(context.Background(), issue); !errors.Is(err, errSentryUnavailable) {
+		t.Fatalf("have %v, want %v", err, errSentryUnavailable)
+	}
+}
+
+func TestSentryStaleFailure(t *testing.T) {
+	_, url := newFakeSentry(t)
+	pool, err := newSentryPool([]string{url}, minerconfig.SentryPolicyPriority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := pool.endpoints[0]
+	stale, err := pool.client(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.record(e, stale, errors.New("call failed"))
+
+	// A failure of the replaced client doesn't close the redialed one
+	cli, err := pool.client(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.record(e, stale, errors.New("late failure"))
+	if have, _ := pool.client(e); have != cli {
+		t.Fatal("redialed sentry client dropped by a stale failure")
+	}
+	if err := cli.Ping(context.Background()); err != nil {
+		t.Fatalf("redialed sentry client closed: %v", err)
+	}
+}
+
+func TestSentryBuilderError(t *testing.T) {
+	var (
+		first, firstURL   = newFakeSentry(t)
+		second, secondURL = newFakeSentry(t)
+	)
+	pool, err := newSentryPool([]string{firstURL, secondURL}, minerconfig.SentryPolicyPriority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An error relayed from the builder neither fails over nor marks the
+	// sentry unhealthy
+	first.reject.Store(true)
+	err = pool.ReportIssue(context.Background(), &types.BidIssue{})
+	if err == nil || errors.Is(err, errSentryUnavailable) {
+		t.Fatalf("have %v, want the builder error", err)
+	}
+	if second.reports.Load() != 0 {
+		t.Fatal("builder error failed over to the second sentry")
+	}
+	if status := pool.status(); !status[0].Healthy || status[0].Failures != 0 {
+		t.Fatalf("sentry failed on a builder error: %+v", status)
+	}
+}
+
+func TestSentryRoundRobin(t *testing.T) {
+	var (
+		first, firstURL   = newFakeSentry(t)
+		second, secondURL = newFakeSentry(t)
+	)
+	pool, err := newSentryPool([]string{firstURL, secondURL}, minerconfig.SentryPolicyRoundRobin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := pool.ReportIssue(context.Background(), &types.BidIssue{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.reports.Load() != 2 || second.reports.Load() != 2 {
+		t.Fatalf("issues not reported in turn: %d, %d", first.reports.Load(), second.reports.Load())
+	}
+	second.down.Store(true)
+	for i := 0; i < 2; i++ {
+		if err := pool.ReportIssue(context.Background(), &types.BidIssue{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.reports.Load() != 4 {
+		t.Fatalf("issues not reported to the healthy sentry: %d", first.reports.Load())
+	}
+}
+
+func TestSentryConfig(t *testing.T) {
+	urls := sentryURLs(&minerconfig.MevConfig{SentryURL: "http://a", SentryURLs: []string{"http://a", "http://b", ""}})
+	if len(urls) != 2 || urls[0] != "http://a" || urls[1] != "http://b" {
+		t.Fatalf("unexpected sentry urls: %v", urls)
+	}
+	if _, err := newSentryPool(urls, "random"); err == nil {
+		t.Fatal("unknown sentry policy accepted")
+	}
+	if _, err := newSentryPool(nil, minerconfig.SentryPolicyPriority); !errors.Is(err, errNoSentry) {
+		t.Fatalf("have %v, want %v", err, errNoSentry)
+	}
+}
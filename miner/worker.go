@@ -154,6 +154,7 @@ type task struct {
 	state     *state.StateDB
 	block     *types.Block
 	createdAt time.Time
+	report    *types.BlockSourceReport // nil if the block is not compared with the bids
 }
 
 const (
@@ -192,6 +193,7 @@ type getWorkReq struct {
 type bidFetcher interface {
 	GetBestBid(parentHash common.Hash) *BidRuntime
 	GetSimulatingBid(prevBlockHash common.Hash) *BidRuntime
+	rejectBid(bid *BidRuntime, err error)
 }
 
 // worker is the main object which takes care of submitting new work to consensus engine
@@ -247,6 +249,11 @@ type worker struct {
 	// payload in proof-of-stake stage.
 	recommit time.Duration
 
+	// Sanitized bundle limits of the blocks.
+	maxBundles          int
+	maxBundleGasPercent uint64
+	bundleSims          *bundleSims // Bundle simulations on top of the last parent block
+
 	// Test hooks
 	newTaskHook       func(*task)                        // Method to call upon receiving a new sealing task.
 	skipSealHook      func(*task) bool                   // Method to decide whether skipping the sealing.
@@ -279,6 +286,7 @@ func newWorker(config *minerconfig.Config, engine consensus.Engine, eth Backend,
 		exitCh:             make(chan struct{}),
 		resubmitIntervalCh: make(chan time.Duration),
 		recentMinedBlocks:  recentMinedBlocks,
+		bundleSims:         newBundleSims(),
 	}
 	// Subscribe events for blockchain
 	worker.chainHeadSub = eth.BlockChain().SubscribeChainHeadEvent(worker.chainHeadCh)
@@ -291,6 +299,18 @@ func newWorker(config *minerconfig.Config, engine consensus.Engine, eth Backend,
 	}
 	worker.recommit = recommit
 
+	// Sanitize the bundle limits, which must leave room for some bundles.
+	worker.maxBundles = worker.config.MaxBundles
+	if worker.maxBundles <= 0 {
+		log.Warn("Sanitizing miner max bundles", "provided", worker.maxBundles, "updated", minerconfig.DefaultConfig.MaxBundles)
+		worker.maxBundles = minerconfig.DefaultConfig.MaxBundles
+	}
+	worker.maxBundleGasPercent = worker.config.MaxBundleGasPercent
+	if worker.maxBundleGasPercent == 0 || worker.maxBundleGasPercent > 100 {
+		log.Warn("Sanitizing miner max bundle gas", "provided", worker.maxBundleGasPercent, "updated", minerconfig.DefaultConfig.MaxBundleGasPercent)
+		worker.maxBundleGasPercent = minerconfig.DefaultConfig.MaxBundleGasPercent
+	}
+
 	worker.wg.Add(4)
 	go worker.mainLoop()
 	go worker.newWorkLoop(recommit)
@@ -658,6 +678,9 @@ func (w *worker) resultLoop() {
 			log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", sealhash, "hash", hash,
 				"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
 			w.mux.Post(core.NewMinedBlockEvent{Block: block})
+			if task.report != nil {
+				w.recordBlockSource(task.report, block)
+			}
 
 		case <-w.exitCh:
 			return
@@ -1166,7 +1189,10 @@ func (w *worker) generateWork(params *generateParams, witness bool) *newPayloadR
 		})
 		defer timer.Stop()
 
-		err := w.fillTransactions(nil, work, nil, nil)
+		bundles, err := w.commitBundles(work, nil, nil)
+		if err == nil {
+			err = w.fillTransactions(nil, work, nil, bundleTxHashes(bundles))
+		}
 		if errors.Is(err, errBlockInterruptedByTimeout) {
 			log.Warn("Block building is interrupted", "allowance", common.PrettyDuration(w.recommit))
 		}
@@ -1293,7 +1319,10 @@ LOOP:
 
 		// Fill pending transactions from the txpool into the block.
 		fillStart := time.Now()
-		err = w.fillTransactions(interruptCh, work, stopTimer, nil)
+		bundles, err := w.commitBundles(work, interruptCh, stopTimer)
+		if err == nil {
+			err = w.fillTransactions(interruptCh, work, stopTimer, bundleTxHashes(bundles))
+		}
 		fillDuration := time.Since(fillStart)
 		switch {
 		case errors.Is(err, errBlockInterruptedByNewHead):
@@ -1378,6 +1407,15 @@ LOOP:
 		}
 	}
 
+	// localValidatorReward is the reward for the validator self by the local block.
+	localValidatorReward := new(uint256.Int).Mul(bestReward, uint256.NewInt(w.config.Mev.ValidatorCommission))
+	localValidatorReward.Div(localValidatorReward, uint256.NewInt(10000))
+
+	var report *types.BlockSourceReport
+	if w.bidFetcher != nil {
+		report = newBlockSourceReport(bestWork.header.Number.Uint64(), bestReward.ToBig(), localValidatorReward.ToBig())
+	}
+
 	// when out-turn, use bestWork to prevent bundle leakage.
 	// when in-turn, compare with remote work.
 	from := bestWork.coinbase
@@ -1392,18 +1430,20 @@ LOOP:
 		}
 
 		bestBid := w.bidFetcher.GetBestBid(bestWork.header.ParentHash)
+		selected := false
 
 		if bestBid != nil {
 			log.Debug("BidSimulator: final compare", "block", bestWork.header.Number.Uint64(),
 				"localBlockReward", bestReward.String(),
 				"bidBlockReward", bestBid.packedBlockReward.String())
+
+			if err := checkRewardRatio(&w.config.Mev.BidPolicy, bestBid.packedBlockReward, bestReward.ToBig()); err != nil {
+				w.bidFetcher.rejectBid(bestBid, err)
+				bestBid = nil
+			}
 		}
 
 		if bestBid != nil && bestReward.CmpBig(bestBid.packedBlockReward) < 0 {
-			// localValidatorReward is the reward for the validator self by the local block.
-			localValidatorReward := new(uint256.Int).Mul(bestReward, uint256.NewInt(w.config.Mev.ValidatorCommission))
-			localValidatorReward.Div(localValidatorReward, uint256.NewInt(10000))
-
 			log.Debug("BidSimulator: final compare", "block", bestWork.header.Number.Uint64(),
 				"localValidatorReward", localValidatorReward.String(),
 				"bidValidatorReward", bestBid.packedValidatorReward.String())
@@ -1412,6 +1452,8 @@ LOOP:
 			if localValidatorReward.CmpBig(bestBid.packedValidatorReward) < 0 {
 				bestWork = bestBid.env
 				from = bestBid.bid.Builder
+				bestBid.selected.Store(true)
+				selected = true
 
 				log.Info("[BUILDER BLOCK]",
 					"block", bestWork.header.Number.Uint64(),
@@ -1422,11 +1464,14 @@ LOOP:
 				)
 			}
 		}
+		if bestBid != nil {
+			compareBid(report, bestBid, selected)
+		}
 	}
 
 	metrics.GetOrRegisterCounter(fmt.Sprintf("block/from/%v", from), nil).Inc(1)
 
-	w.commit(bestWork, w.fullTaskHook, true, start)
+	w.commit(bestWork, w.fullTaskHook, true, start, report)
 
 	// Swap out the old work with the new one, terminating any leftover
 	// prefetcher processes in the mean time and starting a new one.
@@ -1446,7 +1491,7 @@ func (w *worker) inTurn() bool {
 // and commits new work if consensus engine is running.
 // Note the assumption is held that the mutation is allowed to the passed env, do
 // the deep copy first.
-func (w *worker) commit(env *environment, interval func(), update bool, start time.Time) error {
+func (w *worker) commit(env *environment, interval func(), update bool, start time.Time, report *types.BlockSourceReport) error {
 	if w.isRunning() {
 		if interval != nil {
 			interval()
@@ -1478,7 +1523,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 		block = block.WithSidecars(env.sidecars)
 
 		select {
-		case w.taskCh <- &task{receipts: receipts, state: env.state, block: block, createdAt: time.Now()}:
+		case w.taskCh <- &task{receipts: receipts, state: env.state, block: block, createdAt: time.Now(), report: report}:
 			log.Info("Commit new sealing work", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
 				"txs", env.tcount, "blobs", env.blobs, "gas", block.GasUsed(), "fees", feesInEther, "elapsed", common.PrettyDuration(time.Since(start)))
 
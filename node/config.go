@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -212,9 +213,21 @@ type Config struct {
 	// EnableDoubleSignMonitor is a flag that whether to enable the double signature checker
 	EnableDoubleSignMonitor bool `toml:",omitempty"`
 
+	// DoubleSignReporter is the account submitting the evidences of the double
+	// signs found by the double sign monitor, none if zero
+	DoubleSignReporter common.Address `toml:",omitempty"`
+
 	// EnableMaliciousVoteMonitor is a flag that whether to enable the malicious vote checker
 	EnableMaliciousVoteMonitor bool `toml:",omitempty"`
 
+	// MaliciousVoteReporter is the account submitting the evidences of the
+	// malicious votes found by the malicious vote monitor, none if zero
+	MaliciousVoteReporter common.Address `toml:",omitempty"`
+
+	// MaliciousVoteDryRun is a flag that whether to only write the evidences of
+	// the malicious votes to files instead of submitting them
+	MaliciousVoteDryRun bool `toml:",omitempty"`
+
 	// BLSPasswordFile is the file that contains BLS wallet password.
 	BLSPasswordFile string `toml:",omitempty"`
 
@@ -223,6 +236,24 @@ type Config struct {
 	// current directory.
 	BLSWalletDir string `toml:",omitempty"`
 
+	// BLSRemoteSigner is the URL of the remote signer speaking the Web3Signer
+	// eth2 API to sign the votes with, instead of the BLS wallet.
+	BLSRemoteSigner string `toml:",omitempty"`
+
+	// BLSRemoteSignerPubKey is the BLS public key of the remote signer to sign
+	// the votes with, the first key of the signer if empty.
+	BLSRemoteSignerPubKey string `toml:",omitempty"`
+
+	// BLSRemoteSignerCACert, BLSRemoteSignerClientCert and BLSRemoteSignerClientKey
+	// are the PEM files of the CA certificates verifying the remote signer, and of
+	// the client certificate and key authenticating to it.
+	BLSRemoteSignerCACert     string `toml:",omitempty"`
+	BLSRemoteSignerClientCert string `toml:",omitempty"`
+	BLSRemoteSignerClientKey  string `toml:",omitempty"`
+
+	// BLSRemoteSignerTimeout is the timeout of the requests to the remote signer.
+	BLSRemoteSignerTimeout time.Duration `toml:",omitempty"`
+
 	// VoteJournalDir is the directory to store votes in the fast finality feature.
 	VoteJournalDir string `toml:",omitempty"`
 
@@ -0,0 +1,51 @@
+package quant
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+var (
+	//go:embed abi/pancakeV3PoolABI.json
+	pancakeV3PoolABI string
+
+	//go:embed abi/v3swapRouterABI.json
+	v3SwapRouterABI string
+
+	//go:embed abi/v2RouterABI.json
+	v2RouterABI string
+
+	//go:embed abi/erc20ABI.json
+	erc20ABI string
+)
+
+// ParseAbi returns the PancakeV3 pool ABI.
+func ParseAbi() (*abi.ABI, error) {
+	return parseABI(pancakeV3PoolABI)
+}
+
+// ParseRouterAbi returns the PancakeV3 swap router ABI.
+func ParseRouterAbi() (*abi.ABI, error) {
+	return parseABI(v3SwapRouterABI)
+}
+
+// ParseV2RouterAbi returns the swap methods of the UniswapV2 style router.
+func ParseV2RouterAbi() (*abi.ABI, error) {
+	return parseABI(v2RouterABI)
+}
+
+// ParseERC20Abi returns the subset of the ERC-20 ABI used to query token
+// metadata and balances.
+func ParseERC20Abi() (*abi.ABI, error) {
+	return parseABI(erc20ABI)
+}
+
+func parseABI(def string) (*abi.ABI, error) {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,59 @@
+// Package amm models the swap math of Uniswap V2 style constant-product pairs
+// and Uniswap/PancakeSwap V3 concentrated-liquidity pools, and sizes sandwich
+// and backrun trades against them.
+//
+// All amounts are raw token units. Pool states are loaded straight from the
+// contract storage, so quotes can be computed on any state, including the
+// post-state of a simulated transaction.
+package amm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrZeroAmount        = errors.New("zero input amount")
+	ErrNoLiquidity       = errors.New("pool has no liquidity")
+	ErrInsufficientTicks = errors.New("swap crosses the loaded tick range")
+	ErrUnprofitable      = errors.New("no profitable trade")
+)
+
+// StateReader gives access to contract storage. It is satisfied by
+// *state.StateDB.
+type StateReader interface {
+	GetState(addr common.Address, hash common.Hash) common.Hash
+}
+
+// Pool is a liquidity pool quoted in exact-input mode. zeroForOne selects the
+// direction: token0 in and token1 out if true, the opposite otherwise.
+type Pool interface {
+	// Address returns the address of the pool contract.
+	Address() common.Address
+
+	// Swap returns the output of swapping amountIn and the state of the pool
+	// after the swap. The receiver is not modified.
+	Swap(amountIn *big.Int, zeroForOne bool) (*big.Int, Pool, error)
+}
+
+// mulDiv returns floor(a*b/d).
+func mulDiv(a, b, d *big.Int) *big.Int {
+	n := new(big.Int).Mul(a, b)
+	return n.Quo(n, d)
+}
+
+// mulDivRoundingUp returns ceil(a*b/d).
+func mulDivRoundingUp(a, b, d *big.Int) *big.Int {
+	return divRoundingUp(new(big.Int).Mul(a, b), d)
+}
+
+// divRoundingUp returns ceil(a/d) for non-negative values.
+func divRoundingUp(a, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(a, d, new(big.Int))
+	if r.Sign() != 0 {
+		q.Add(q, common.Big1)
+	}
+	return q
+}
@@ -0,0 +1,222 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrVictimSlippage is returned if a front-run pushes the victim's output below
+// its minimum, which would make the victim transaction revert.
+var ErrVictimSlippage = errors.New("victim output below minimum")
+
+// Costs are the fixed costs of a trade, expressed in the token the trade
+// starts and ends with.
+type Costs struct {
+	Gas   *big.Int // Gas fees of the searcher transactions
+	Bribe *big.Int // Payment to the block builder
+}
+
+func (c Costs) total() *big.Int {
+	total := new(big.Int)
+	if c.Gas != nil {
+		total.Add(total, c.Gas)
+	}
+	if c.Bribe != nil {
+		total.Add(total, c.Bribe)
+	}
+	return total
+}
+
+// Victim is a pending exact input swap.
+type Victim struct {
+	AmountIn     *big.Int
+	AmountOutMin *big.Int // Slippage limit, nil if there is none
+	ZeroForOne   bool
+}
+
+// Sandwich is a front-run buying ahead of a victim swap in the same direction
+// and a back-run selling the proceeds right after it.
+type Sandwich struct {
+	AmountIn  *big.Int // Front-run input, in the victim's input token
+	FrontOut  *big.Int // Front-run output, sold in the back-run
+	VictimOut *big.Int // Victim output after the front-run
+	BackOut   *big.Int // Back-run output, in the victim's input token
+	Profit    *big.Int // BackOut - AmountIn - costs
+}
+
+// SandwichAt computes the outcome of sandwiching victim with a front-run of
+// amountIn.
+func SandwichAt(pool Pool, victim Victim, amountIn *big.Int, costs Costs) (*Sandwich, error) {
+	frontOut, victimOut, pool, err := frontRun(pool, victim, amountIn)
+	if err != nil {
+		return nil, err
+	}
+	backOut, _, err := pool.Swap(frontOut, !victim.ZeroForOne)
+	if err != nil {
+		return nil, err
+	}
+	profit := new(big.Int).Sub(backOut, amountIn)
+	return &Sandwich{
+		AmountIn:  new(big.Int).Set(amountIn),
+		FrontOut:  frontOut,
+		VictimOut: victimOut,
+		BackOut:   backOut,
+		Profit:    profit.Sub(profit, costs.total()),
+	}, nil
+}
+
+// frontRun executes a front-run of amountIn followed by the victim swap and
+// returns both outputs and the resulting pool state.
+func frontRun(pool Pool, victim Victim, amountIn *big.Int) (*big.Int, *big.Int, Pool, error) {
+	frontOut, pool, err := pool.Swap(amountIn, victim.ZeroForOne)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	victimOut, pool, err := pool.Swap(victim.AmountIn, victim.ZeroForOne)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if victim.AmountOutMin != nil && victimOut.Cmp(victim.AmountOutMin) < 0 {
+		return nil, nil, nil, ErrVictimSlippage
+	}
+	return frontOut, victimOut, pool, nil
+}
+
+// OptimalSandwich finds the front-run size up to maxIn maximising the profit
+// of sandwiching victim, while keeping the victim within its slippage limit.
+// It returns ErrUnprofitable if no size covers the costs.
+func OptimalSandwich(pool Pool, victim Victim, maxIn *big.Int, costs Costs) (*Sandwich, error) {
+	// The victim output shrinks as the front-run grows, find the largest
+	// front-run the victim tolerates.
+	fits := func(amountIn *big.Int) bool {
+		_, _, _, err := frontRun(pool, victim, amountIn)
+		return err == nil
+	}
+	if !fits(common.Big1) {
+		return nil, ErrUnprofitable
+	}
+	lo, hi := new(big.Int).Set(common.Big1), new(big.Int).Set(maxIn)
+	for lo.Cmp(hi) < 0 {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Add(mid, common.Big1).Rsh(mid, 1)
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid.Sub(mid, common.Big1)
+		}
+	}
+	best := maximise(common.Big1, lo, func(amountIn *big.Int) *big.Int {
+		s, err := SandwichAt(pool, victim, amountIn, costs)
+		if err != nil {
+			return nil
+		}
+		return s.Profit
+	})
+	if best == nil {
+		return nil, ErrUnprofitable
+	}
+	s, err := SandwichAt(pool, victim, best, costs)
+	if err != nil {
+		return nil, err
+	}
+	if s.Profit.Sign() <= 0 {
+		return nil, ErrUnprofitable
+	}
+	return s, nil
+}
+
+// Backrun is an arbitrage buying from one pool and selling to another one
+// whose price moved, typically after a large swap.
+type Backrun struct {
+	AmountIn  *big.Int // Input into the buy pool
+	Mid       *big.Int // Output of the buy pool, sold to the sell pool
+	AmountOut *big.Int // Output of the sell pool, in the input token
+	Profit    *big.Int // AmountOut - AmountIn - costs
+}
+
+// BackrunAt computes the outcome of swapping amountIn through buy in the
+// zeroForOne direction and the output back through sell.
+func BackrunAt(buy, sell Pool, zeroForOne bool, amountIn *big.Int, costs Costs) (*Backrun, error) {
+	mid, _, err := buy.Swap(amountIn, zeroForOne)
+	if err != nil {
+		return nil, err
+	}
+	if mid.Sign() == 0 {
+		return nil, ErrZeroAmount
+	}
+	amountOut, _, err := sell.Swap(mid, !zeroForOne)
+	if err != nil {
+		return nil, err
+	}
+	profit := new(big.Int).Sub(amountOut, amountIn)
+	return &Backrun{
+		AmountIn:  new(big.Int).Set(amountIn),
+		Mid:       mid,
+		AmountOut: amountOut,
+		Profit:    profit.Sub(profit, costs.total()),
+	}, nil
+}
+
+// OptimalBackrun finds the input up to maxIn maximising the profit of
+// arbitraging buy against sell. It returns ErrUnprofitable if no size covers
+// the costs.
+func OptimalBackrun(buy, sell Pool, zeroForOne bool, maxIn *big.Int, costs Costs) (*Backrun, error) {
+	best := maximise(common.Big1, maxIn, func(amountIn *big.Int) *big.Int {
+		b, err := BackrunAt(buy, sell, zeroForOne, amountIn, costs)
+		if err != nil {
+			return nil
+		}
+		return b.Profit
+	})
+	if best == nil {
+		return nil, ErrUnprofitable
+	}
+	b, err := BackrunAt(buy, sell, zeroForOne, best, costs)
+	if err != nil {
+		return nil, err
+	}
+	if b.Profit.Sign() <= 0 {
+		return nil, ErrUnprofitable
+	}
+	return b, nil
+}
+
+// maximise runs a ternary search for the maximum of a unimodal function over
+// [lo, hi]. The function returns nil for inputs that are not feasible, which
+// are ranked below any value. It returns nil if no input is feasible.
+func maximise(lo, hi *big.Int, f func(*big.Int) *big.Int) *big.Int {
+	if lo.Cmp(hi) > 0 {
+		return nil
+	}
+	less := func(a, b *big.Int) bool {
+		if a == nil {
+			return b != nil
+		}
+		return b != nil && a.Cmp(b) < 0
+	}
+	lo, hi = new(big.Int).Set(lo), new(big.Int).Set(hi)
+	three := big.NewInt(3)
+	for new(big.Int).Sub(hi, lo).Cmp(three) > 0 {
+		third := new(big.Int).Sub(hi, lo)
+		third.Quo(third, three)
+		m1 := new(big.Int).Add(lo, third)
+		m2 := new(big.Int).Sub(hi, third)
+		if less(f(m1), f(m2)) {
+			lo = m1.Add(m1, common.Big1)
+		} else {
+			hi = m2.Sub(m2, common.Big1)
+		}
+	}
+	var (
+		best      *big.Int
+		bestValue *big.Int
+	)
+	for x := lo; x.Cmp(hi) <= 0; x = new(big.Int).Add(x, common.Big1) {
+		if v := f(x); v != nil && (best == nil || less(bestValue, v)) {
+			best, bestValue = x, v
+		}
+	}
+	return best
+}
@@ -0,0 +1,114 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var testCosts = Costs{
+	Gas:   big.NewInt(params.Ether / 1000),
+	Bribe: big.NewInt(params.Ether / 100),
+}
+
+// checkOptimal verifies that no size on a grid over [1, maxIn], nor the sizes
+// next to best, does better than best.
+func checkOptimal(t *testing.T, best *big.Int, maxIn *big.Int, profit func(*big.Int) *big.Int) {
+	t.Helper()
+
+	want := profit(best)
+	candidates := []*big.Int{new(big.Int).Sub(best, common.Big1), new(big.Int).Add(best, common.Big1)}
+	for i := int64(1); i <= 100; i++ {
+		x := new(big.Int).Mul(maxIn, big.NewInt(i))
+		candidates = append(candidates, x.Quo(x, big.NewInt(100)))
+	}
+	for _, x := range candidates {
+		if p := profit(x); p != nil && p.Cmp(want) > 0 {
+			t.Fatalf("size %v beats the optimum %v: %v > %v", x, best, p, want)
+		}
+	}
+}
+
+func TestOptimalSandwich(t *testing.T) {
+	tests := []struct {
+		name   string
+		pool   Pool
+		victim Victim
+	}{
+		{
+			name:   "v2",
+			pool:   &V2Pair{Reserve0: ether(1000), Reserve1: ether(600_000), Fee: PancakeV2Fee},
+			victim: Victim{AmountIn: ether(10), ZeroForOne: true},
+		},
+		{
+			name:   "v3",
+			pool:   newTestV3Pool(),
+			victim: Victim{AmountIn: ether(100_000), ZeroForOne: true},
+		},
+	}
+	for _, tt := range tests {
+		// Allow the victim 1% slippage
+		out, _, err := tt.pool.Swap(tt.victim.AmountIn, tt.victim.ZeroForOne)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.victim.AmountOutMin = new(big.Int).Sub(out, new(big.Int).Div(out, big.NewInt(100)))
+
+		maxIn := new(big.Int).Mul(tt.victim.AmountIn, big.NewInt(100))
+		s, err := OptimalSandwich(tt.pool, tt.victim, maxIn, testCosts)
+		if err != nil {
+			t.Fatalf("%s: no sandwich found: %v", tt.name, err)
+		}
+		if s.VictimOut.Cmp(tt.victim.AmountOutMin) < 0 {
+			t.Fatalf("%s: victim output %v below minimum %v", tt.name, s.VictimOut, tt.victim.AmountOutMin)
+		}
+		if s.Profit.Sign() <= 0 {
+			t.Fatalf("%s: unprofitable sandwich: %v", tt.name, s.Profit)
+		}
+		checkOptimal(t, s.AmountIn, maxIn, func(x *big.Int) *big.Int {
+			s, err := SandwichAt(tt.pool, tt.victim, x, testCosts)
+			if err != nil {
+				return nil
+			}
+			return s.Profit
+		})
+
+		// Without any slippage allowance there is no room for a front-run
+		tt.victim.AmountOutMin = out
+		if _, err := OptimalSandwich(tt.pool, tt.victim, maxIn, testCosts); !errors.Is(err, ErrUnprofitable) {
+			t.Fatalf("%s: no slippage: have %v, want %v", tt.name, err, ErrUnprofitable)
+		}
+	}
+}
+
+func TestOptimalBackrun(t *testing.T) {
+	var (
+		cheap = &V2Pair{Reserve0: ether(1000), Reserve1: ether(610_000), Fee: PancakeV2Fee}
+		dear  = &V2Pair{Reserve0: ether(1000), Reserve1: ether(600_000), Fee: UniswapV2Fee}
+		maxIn = ether(100)
+	)
+	// Buy token1 where it is cheap and sell it where it is dear
+	b, err := OptimalBackrun(cheap, dear, true, maxIn, testCosts)
+	if err != nil {
+		t.Fatalf("no backrun found: %v", err)
+	}
+	checkOptimal(t, b.AmountIn, maxIn, func(x *big.Int) *big.Int {
+		b, err := BackrunAt(cheap, dear, true, x, testCosts)
+		if err != nil {
+			return nil
+		}
+		return b.Profit
+	})
+	// The optimum of a constant-product arbitrage is where the marginal
+	// prices meet, about 2.75 token0 here.
+	if b.AmountIn.Cmp(ether(2)) < 0 || b.AmountIn.Cmp(ether(3)) > 0 {
+		t.Fatalf("backrun size out of range: %v", b.AmountIn)
+	}
+	// The other way round only loses money
+	if _, err := OptimalBackrun(dear, cheap, true, maxIn, testCosts); !errors.Is(err, ErrUnprofitable) {
+		t.Fatalf("reverse backrun: have %v, want %v", err, ErrUnprofitable)
+	}
+}
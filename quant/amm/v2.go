@@ -0,0 +1,108 @@
+package amm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// V2 pair storage slots, shared by Uniswap V2 and its forks such as
+// PancakeSwap V2.
+const (
+	v2Token0Slot   = 6
+	v2Token1Slot   = 7
+	v2ReservesSlot = 8 // reserve0 (uint112) | reserve1 (uint112) | blockTimestampLast (uint32)
+)
+
+// Swap fees of common V2 deployments, in basis points.
+const (
+	UniswapV2Fee     = 30
+	PancakeV2Fee     = 25
+	v2FeeDenominator = 10_000
+)
+
+var mask112 = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 112), common.Big1)
+
+// V2Pair is the state of a constant-product pair.
+type V2Pair struct {
+	Addr     common.Address
+	Token0   common.Address
+	Token1   common.Address
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	Fee      uint64 // Swap fee in basis points
+}
+
+// LoadV2Pair reads the tokens and reserves of the pair at addr.
+func LoadV2Pair(state StateReader, addr common.Address, fee uint64) *V2Pair {
+	reserves := state.GetState(addr, common.BigToHash(big.NewInt(v2ReservesSlot))).Big()
+	return &V2Pair{
+		Addr:     addr,
+		Token0:   common.BytesToAddress(state.GetState(addr, common.BigToHash(big.NewInt(v2Token0Slot))).Bytes()),
+		Token1:   common.BytesToAddress(state.GetState(addr, common.BigToHash(big.NewInt(v2Token1Slot))).Bytes()),
+		Reserve0: new(big.Int).And(reserves, mask112),
+		Reserve1: new(big.Int).And(new(big.Int).Rsh(reserves, 112), mask112),
+		Fee:      fee,
+	}
+}
+
+// Address implements Pool.
+func (p *V2Pair) Address() common.Address {
+	return p.Addr
+}
+
+// AmountOut mirrors UniswapV2Library.getAmountOut.
+func (p *V2Pair) AmountOut(amountIn *big.Int, zeroForOne bool) (*big.Int, error) {
+	if amountIn.Sign() <= 0 {
+		return nil, ErrZeroAmount
+	}
+	reserveIn, reserveOut := p.reserves(zeroForOne)
+	if reserveIn.Sign() == 0 || reserveOut.Sign() == 0 {
+		return nil, ErrNoLiquidity
+	}
+	amountInWithFee := new(big.Int).Mul(amountIn, new(big.Int).SetUint64(v2FeeDenominator-p.Fee))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, big.NewInt(v2FeeDenominator))
+	denominator.Add(denominator, amountInWithFee)
+	return numerator.Quo(numerator, denominator), nil
+}
+
+// AmountIn mirrors UniswapV2Library.getAmountIn.
+func (p *V2Pair) AmountIn(amountOut *big.Int, zeroForOne bool) (*big.Int, error) {
+	if amountOut.Sign() <= 0 {
+		return nil, ErrZeroAmount
+	}
+	reserveIn, reserveOut := p.reserves(zeroForOne)
+	if reserveIn.Sign() == 0 || amountOut.Cmp(reserveOut) >= 0 {
+		return nil, ErrNoLiquidity
+	}
+	numerator := new(big.Int).Mul(reserveIn, amountOut)
+	numerator.Mul(numerator, big.NewInt(v2FeeDenominator))
+	denominator := new(big.Int).Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, new(big.Int).SetUint64(v2FeeDenominator-p.Fee))
+	return numerator.Quo(numerator, denominator).Add(numerator, common.Big1), nil
+}
+
+// Swap implements Pool.
+func (p *V2Pair) Swap(amountIn *big.Int, zeroForOne bool) (*big.Int, Pool, error) {
+	amountOut, err := p.AmountOut(amountIn, zeroForOne)
+	if err != nil {
+		return nil, nil, err
+	}
+	next := *p
+	if zeroForOne {
+		next.Reserve0 = new(big.Int).Add(p.Reserve0, amountIn)
+		next.Reserve1 = new(big.Int).Sub(p.Reserve1, amountOut)
+	} else {
+		next.Reserve0 = new(big.Int).Sub(p.Reserve0, amountOut)
+		next.Reserve1 = new(big.Int).Add(p.Reserve1, amountIn)
+	}
+	return amountOut, &next, nil
+}
+
+func (p *V2Pair) reserves(zeroForOne bool) (*big.Int, *big.Int) {
+	if zeroForOne {
+		return p.Reserve0, p.Reserve1
+	}
+	return p.Reserve1, p.Reserve0
+}
@@ -0,0 +1,102 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+func newTestState(t *testing.T) *state.StateDB {
+	t.Helper()
+
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statedb
+}
+
+func TestV2AmountOut(t *testing.T) {
+	tests := []struct {
+		fee  uint64
+		want string
+	}{
+		{UniswapV2Fee, "996006981039903216"},
+		{PancakeV2Fee, "996505985279683515"},
+	}
+	for _, tt := range tests {
+		pair := &V2Pair{Reserve0: ether(1000), Reserve1: ether(1000), Fee: tt.fee}
+		out, err := pair.AmountOut(ether(1), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("fee %d: amount out mismatch: have %v, want %v", tt.fee, out, tt.want)
+		}
+		// Buying the quoted output must not cost more than the input
+		in, err := pair.AmountIn(out, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if in.Cmp(ether(1)) > 0 {
+			t.Errorf("fee %d: amount in mismatch: have %v, want <= %v", tt.fee, in, ether(1))
+		}
+	}
+	pair := &V2Pair{Reserve0: ether(1000), Reserve1: new(big.Int), Fee: PancakeV2Fee}
+	if _, err := pair.AmountOut(ether(1), true); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("empty pair: have %v, want %v", err, ErrNoLiquidity)
+	}
+}
+
+func TestV2Swap(t *testing.T) {
+	pair := &V2Pair{Reserve0: ether(1000), Reserve1: ether(600_000), Fee: PancakeV2Fee}
+	out, next, err := pair.Swap(ether(10), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := next.(*V2Pair)
+	if after.Reserve0.Cmp(ether(1010)) != 0 || new(big.Int).Add(after.Reserve1, out).Cmp(ether(600_000)) != 0 {
+		t.Fatalf("reserves mismatch: %v %v", after.Reserve0, after.Reserve1)
+	}
+	if pair.Reserve0.Cmp(ether(1000)) != 0 {
+		t.Fatal("swap modified the original pair")
+	}
+	// The constant product must not decrease
+	k := new(big.Int).Mul(pair.Reserve0, pair.Reserve1)
+	if new(big.Int).Mul(after.Reserve0, after.Reserve1).Cmp(k) < 0 {
+		t.Fatal("constant product decreased")
+	}
+}
+
+func TestLoadV2Pair(t *testing.T) {
+	var (
+		statedb = newTestState(t)
+		addr    = common.HexToAddress("0x16b9a82891338f9ba80e2d6970fdda79d1eb0dae")
+		token0  = common.HexToAddress("0x55d398326f99059ff775485246999027b3197955")
+		token1  = common.HexToAddress("0xbb4cdb9cbd36b01bd1cbaebf2de08d9173bc095c")
+	)
+	reserves := new(big.Int).Lsh(big.NewInt(1_700_000_000), 224) // blockTimestampLast
+	reserves.Or(reserves, new(big.Int).Lsh(ether(1000), 112))
+	reserves.Or(reserves, ether(600_000))
+
+	statedb.SetState(addr, common.BigToHash(big.NewInt(6)), common.BytesToHash(token0.Bytes()))
+	statedb.SetState(addr, common.BigToHash(big.NewInt(7)), common.BytesToHash(token1.Bytes()))
+	statedb.SetState(addr, common.BigToHash(big.NewInt(8)), common.BigToHash(reserves))
+
+	pair := LoadV2Pair(statedb, addr, PancakeV2Fee)
+	if pair.Address() != addr || pair.Token0 != token0 || pair.Token1 != token1 {
+		t.Fatalf("pair mismatch: %+v", pair)
+	}
+	if pair.Reserve0.Cmp(ether(600_000)) != 0 || pair.Reserve1.Cmp(ether(1000)) != 0 {
+		t.Fatalf("reserves mismatch: have %v/%v", pair.Reserve0, pair.Reserve1)
+	}
+}
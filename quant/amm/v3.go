@@ -0,0 +1,277 @@
+package amm
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// V3Layout holds the storage slots of the V3 pool state variables.
+type V3Layout struct {
+	Slot0      uint64
+	Liquidity  uint64
+	Ticks      uint64
+	TickBitmap uint64
+}
+
+var (
+	// UniswapV3Layout is the storage layout of UniswapV3Pool.
+	UniswapV3Layout = V3Layout{Slot0: 0, Liquidity: 4, Ticks: 5, TickBitmap: 6}
+
+	// PancakeV3Layout is the storage layout of PancakeV3Pool. Its slot0 has a
+	// uint32 feeProtocol which spills into a second slot, shifting the state
+	// variables that follow by one.
+	PancakeV3Layout = V3Layout{Slot0: 0, Liquidity: 5, Ticks: 6, TickBitmap: 7}
+)
+
+// Fee tiers of PancakeSwap V3 and their tick spacing.
+var PancakeV3TickSpacing = map[uint32]int32{
+	100:   1,
+	500:   10,
+	2500:  50,
+	10000: 200,
+}
+
+var (
+	mask128 = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 128), common.Big1)
+	mask160 = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 160), common.Big1)
+)
+
+// V3Tick is an initialized tick and the liquidity change when crossing it
+// from left to right.
+type V3Tick struct {
+	Index        int32
+	LiquidityNet *big.Int
+}
+
+// V3Pool is the state of a concentrated-liquidity pool.
+type V3Pool struct {
+	Addr         common.Address
+	Fee          uint32 // Swap fee in hundredths of a basis point
+	TickSpacing  int32
+	SqrtPriceX96 *big.Int
+	Tick         int32
+	Liquidity    *big.Int
+	Ticks        []V3Tick // Initialized ticks, sorted by index
+
+	// The initialized ticks are only known within the tick bitmap words
+	// [minWord, maxWord], swaps needing ticks outside of it fail.
+	minWord int16
+	maxWord int16
+}
+
+// NewV3Pool creates a pool from its full list of initialized ticks.
+func NewV3Pool(addr common.Address, fee uint32, tickSpacing int32, sqrtPriceX96, liquidity *big.Int, ticks []V3Tick) *V3Pool {
+	ticks = append([]V3Tick(nil), ticks...)
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i].Index < ticks[j].Index })
+
+	minWord, _ := tickPosition(MinTick, tickSpacing)
+	maxWord, _ := tickPosition(MaxTick, tickSpacing)
+	return &V3Pool{
+		Addr:         addr,
+		Fee:          fee,
+		TickSpacing:  tickSpacing,
+		SqrtPriceX96: sqrtPriceX96,
+		Tick:         TickAtSqrtRatio(sqrtPriceX96),
+		Liquidity:    liquidity,
+		Ticks:        ticks,
+		minWord:      minWord,
+		maxWord:      maxWord,
+	}
+}
+
+// LoadV3Pool reads the price, the active liquidity and the initialized ticks
+// within words bitmap words on each side of the current tick. The fee and the
+// tick spacing are immutables of the pool contract and not in its storage.
+func LoadV3Pool(state StateReader, addr common.Address, layout V3Layout, fee uint32, tickSpacing int32, words int16) (*V3Pool, error) {
+	slot0 := state.GetState(addr, common.BigToHash(new(big.Int).SetUint64(layout.Slot0))).Big()
+	sqrtPrice := new(big.Int).And(slot0, mask160)
+	if sqrtPrice.Sign() == 0 {
+		return nil, ErrNoLiquidity
+	}
+	liquidity := state.GetState(addr, common.BigToHash(new(big.Int).SetUint64(layout.Liquidity))).Big()
+	liquidity.And(liquidity, mask128)
+
+	tick := int32(signExtend(new(big.Int).Rsh(slot0, 160), 24).Int64())
+	word, _ := tickPosition(tick, tickSpacing)
+	minWord, _ := tickPosition(MinTick, tickSpacing)
+	maxWord, _ := tickPosition(MaxTick, tickSpacing)
+	minWord = int16(max(int32(minWord), int32(word)-int32(words)))
+	maxWord = int16(min(int32(maxWord), int32(word)+int32(words)))
+
+	pool := &V3Pool{
+		Addr:         addr,
+		Fee:          fee,
+		TickSpacing:  tickSpacing,
+		SqrtPriceX96: sqrtPrice,
+		Tick:         tick,
+		Liquidity:    liquidity,
+		minWord:      minWord,
+		maxWord:      maxWord,
+	}
+	for w := int32(minWord); w <= int32(maxWord); w++ {
+		bitmap := state.GetState(addr, mappingSlot(big.NewInt(int64(w)), layout.TickBitmap)).Big()
+		for bit := 0; bit < 256; bit++ {
+			if bitmap.Bit(bit) == 0 {
+				continue
+			}
+			index := (w*256 + int32(bit)) * tickSpacing
+			info := state.GetState(addr, mappingSlot(big.NewInt(int64(index)), layout.Ticks)).Big()
+			pool.Ticks = append(pool.Ticks, V3Tick{
+				Index:        index,
+				LiquidityNet: signExtend(info.Rsh(info, 128), 128),
+			})
+		}
+	}
+	return pool, nil
+}
+
+// Address implements Pool.
+func (p *V3Pool) Address() common.Address {
+	return p.Addr
+}
+
+// Swap implements Pool, following the exact input path of UniswapV3Pool.swap
+// with no price limit.
+func (p *V3Pool) Swap(amountIn *big.Int, zeroForOne bool) (*big.Int, Pool, error) {
+	if amountIn.Sign() <= 0 {
+		return nil, nil, ErrZeroAmount
+	}
+	var (
+		remaining = new(big.Int).Set(amountIn)
+		amountOut = new(big.Int)
+		sqrtPrice = new(big.Int).Set(p.SqrtPriceX96)
+		tick      = p.Tick
+		liquidity = new(big.Int).Set(p.Liquidity)
+		limit     = new(big.Int).Sub(MaxSqrtRatio, common.Big1)
+	)
+	if zeroForOne {
+		limit = new(big.Int).Add(MinSqrtRatio, common.Big1)
+	}
+	for remaining.Sign() > 0 && sqrtPrice.Cmp(limit) != 0 {
+		next, initialized, err := p.nextTick(tick, zeroForOne)
+		if err != nil {
+			return nil, nil, err
+		}
+		next = max(MinTick, min(MaxTick, next))
+
+		sqrtNext := SqrtRatioAtTick(next)
+		target := sqrtNext
+		if (zeroForOne && sqrtNext.Cmp(limit) < 0) || (!zeroForOne && sqrtNext.Cmp(limit) > 0) {
+			target = limit
+		}
+		step := computeSwapStep(sqrtPrice, target, liquidity, remaining, p.Fee)
+		remaining.Sub(remaining, step.amountIn)
+		remaining.Sub(remaining, step.feeAmount)
+		amountOut.Add(amountOut, step.amountOut)
+
+		if step.sqrtPriceNext.Cmp(sqrtNext) == 0 {
+			if initialized {
+				net := p.liquidityNet(next)
+				if zeroForOne {
+					liquidity.Sub(liquidity, net)
+				} else {
+					liquidity.Add(liquidity, net)
+				}
+			}
+			if zeroForOne {
+				tick = next - 1
+			} else {
+				tick = next
+			}
+		} else if step.sqrtPriceNext.Cmp(sqrtPrice) != 0 {
+			tick = TickAtSqrtRatio(step.sqrtPriceNext)
+		}
+		sqrtPrice = step.sqrtPriceNext
+	}
+	if remaining.Sign() > 0 {
+		return nil, nil, ErrNoLiquidity
+	}
+	next := *p
+	next.SqrtPriceX96 = sqrtPrice
+	next.Tick = tick
+	next.Liquidity = liquidity
+	return amountOut, &next, nil
+}
+
+// nextTick returns the next initialized tick within the bitmap word of tick,
+// or the word boundary if there is none, like
+// TickBitmap.nextInitializedTickWithinOneWord.
+func (p *V3Pool) nextTick(tick int32, lte bool) (int32, bool, error) {
+	compressed := floorDiv(tick, p.TickSpacing)
+	if !lte {
+		compressed++
+	}
+	word, bit := tickPosition(compressed*p.TickSpacing, p.TickSpacing)
+	if word < p.minWord || word > p.maxWord {
+		return 0, false, ErrInsufficientTicks
+	}
+	if lte {
+		var (
+			lower = (compressed - int32(bit)) * p.TickSpacing
+			upper = compressed * p.TickSpacing
+		)
+		// The last initialized tick at or below tick, within the word
+		i := sort.Search(len(p.Ticks), func(i int) bool { return p.Ticks[i].Index > upper }) - 1
+		if i >= 0 && p.Ticks[i].Index >= lower {
+			return p.Ticks[i].Index, true, nil
+		}
+		return lower, false, nil
+	}
+	var (
+		lower = compressed * p.TickSpacing
+		upper = (compressed + 255 - int32(bit)) * p.TickSpacing
+	)
+	// The first initialized tick above tick, within the word
+	i := sort.Search(len(p.Ticks), func(i int) bool { return p.Ticks[i].Index >= lower })
+	if i < len(p.Ticks) && p.Ticks[i].Index <= upper {
+		return p.Ticks[i].Index, true, nil
+	}
+	return upper, false, nil
+}
+
+func (p *V3Pool) liquidityNet(tick int32) *big.Int {
+	i := sort.Search(len(p.Ticks), func(i int) bool { return p.Ticks[i].Index >= tick })
+	if i < len(p.Ticks) && p.Ticks[i].Index == tick {
+		return p.Ticks[i].LiquidityNet
+	}
+	return new(big.Int)
+}
+
+// tickPosition returns the bitmap word and bit of a tick.
+func tickPosition(tick, tickSpacing int32) (int16, uint8) {
+	compressed := floorDiv(tick, tickSpacing)
+	return int16(compressed >> 8), uint8(compressed & 0xff)
+}
+
+func floorDiv(a, b int32) int32 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// mappingSlot returns the storage slot of key in a Solidity mapping with a
+// signed integer key type.
+func mappingSlot(key *big.Int, slot uint64) common.Hash {
+	var buf [64]byte
+	if key.Sign() < 0 {
+		// Two's complement over 256 bits
+		key = new(big.Int).Add(key, new(big.Int).Lsh(common.Big1, 256))
+	}
+	key.FillBytes(buf[:32])
+	new(big.Int).SetUint64(slot).FillBytes(buf[32:])
+	return crypto.Keccak256Hash(buf[:])
+}
+
+// signExtend interprets the lowest bits of x as a two's complement number.
+func signExtend(x *big.Int, bits uint) *big.Int {
+	x = new(big.Int).And(x, new(big.Int).Sub(new(big.Int).Lsh(common.Big1, bits), common.Big1))
+	if x.Bit(int(bits-1)) == 1 {
+		x.Sub(x, new(big.Int).Lsh(common.Big1, bits))
+	}
+	return x
+}
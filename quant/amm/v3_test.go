@@ -0,0 +1,181 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSqrtRatioAtTick(t *testing.T) {
+	tests := []struct {
+		tick int32
+		want string
+	}{
+		{MinTick, "4295128739"},
+		{-63970, "3234879690328489470154918904"},
+		{-1, "79224201403219477170569942574"},
+		{0, "79228162514264337593543950336"},
+		{1, "79232123823359799118286999568"},
+		{100000, "11755562826496067164730007768450"},
+		{MaxTick, "1461446703485210103287273052203988822378723970342"},
+	}
+	for _, tt := range tests {
+		if have := SqrtRatioAtTick(tt.tick); have.String() != tt.want {
+			t.Errorf("tick %d: have %v, want %v", tt.tick, have, tt.want)
+		}
+	}
+}
+
+func TestTickAtSqrtRatio(t *testing.T) {
+	if tick := TickAtSqrtRatio(MinSqrtRatio); tick != MinTick {
+		t.Errorf("min ratio: have %d, want %d", tick, MinTick)
+	}
+	if tick := TickAtSqrtRatio(new(big.Int).Sub(MaxSqrtRatio, common.Big1)); tick != MaxTick-1 {
+		t.Errorf("max ratio: have %d, want %d", tick, MaxTick-1)
+	}
+	for _, tick := range []int32{-800000, -63975, -1, 0, 1, 50, 276324} {
+		ratio := SqrtRatioAtTick(tick)
+		if have := TickAtSqrtRatio(ratio); have != tick {
+			t.Errorf("tick %d: have %d at its ratio", tick, have)
+		}
+		if have := TickAtSqrtRatio(new(big.Int).Sub(ratio, common.Big1)); have != tick-1 {
+			t.Errorf("tick %d: have %d just below its ratio", tick, have)
+		}
+	}
+}
+
+// newTestV3Pool creates a USDT/WBNB style pool at tick -63975 with a narrow
+// position in [-64200, -63800] on top of a wide one in [-69000, -59000].
+func newTestV3Pool() *V3Pool {
+	ticks := []V3Tick{
+		{Index: -69000, LiquidityNet: ether(500_000)},
+		{Index: -64200, LiquidityNet: ether(20_000)},
+		{Index: -63800, LiquidityNet: ether(-20_000)},
+		{Index: -59000, LiquidityNet: ether(-500_000)},
+	}
+	price := new(big.Int).Add(SqrtRatioAtTick(-63975), big.NewInt(12345))
+	return NewV3Pool(common.Address{0x01}, 500, 10, price, ether(520_000), ticks)
+}
+
+// Expected values are computed with a line by line port of the pool contract
+// swap path.
+var v3SwapTests = []struct {
+	amountIn   *big.Int
+	zeroForOne bool
+	amountOut  string
+	sqrtPrice  string
+	tick       int32
+	liquidity  *big.Int
+}{
+	// Within the initial range
+	{ether(1000), true, "1665285450517693189", "3233817385937001943940841128", -63977, ether(520_000)},
+	{ether(1), false, "599821993965027280052", "3234223397580731295680125163", -63975, ether(520_000)},
+	// Up to the lower end of the initial range
+	{ether(100_000), true, "165245094523314461362", "3208894063442248339520752787", -64132, ether(520_000)},
+	// Across the narrow position
+	{ether(300_000), true, "488015367223053337010", "3158189094685389617939380281", -64450, ether(500_000)},
+	{ether(1_000_000), true, "1541007149117914835020", "2991335886641087868060376332", -65536, ether(500_000)},
+	{ether(1000), false, "572199937983552763233112", "3391311374330454965351991876", -63026, ether(500_000)},
+}
+
+func TestV3Swap(t *testing.T) {
+	pool := newTestV3Pool()
+	for i, tt := range v3SwapTests {
+		out, next, err := pool.Swap(tt.amountIn, tt.zeroForOne)
+		if err != nil {
+			t.Fatalf("test %d: swap failed: %v", i, err)
+		}
+		after := next.(*V3Pool)
+		if out.String() != tt.amountOut {
+			t.Errorf("test %d: amount out mismatch: have %v, want %v", i, out, tt.amountOut)
+		}
+		if after.SqrtPriceX96.String() != tt.sqrtPrice {
+			t.Errorf("test %d: price mismatch: have %v, want %v", i, after.SqrtPriceX96, tt.sqrtPrice)
+		}
+		if after.Tick != tt.tick || after.Liquidity.Cmp(tt.liquidity) != 0 {
+			t.Errorf("test %d: state mismatch: have tick %d liquidity %v, want %d %v", i, after.Tick, after.Liquidity, tt.tick, tt.liquidity)
+		}
+	}
+	if pool.Tick != -63975 || pool.Liquidity.Cmp(ether(520_000)) != 0 {
+		t.Fatal("swap modified the original pool")
+	}
+	// Swapping past the lowest position leaves no liquidity to fill the rest
+	if _, _, err := pool.Swap(ether(100_000_000), true); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("oversized swap: have %v, want %v", err, ErrNoLiquidity)
+	}
+}
+
+func TestLoadV3Pool(t *testing.T) {
+	var (
+		statedb = newTestState(t)
+		pool    = newTestV3Pool()
+		addr    = pool.Addr
+		layout  = PancakeV3Layout
+	)
+	// slot0 packs the price, the tick and the oracle and fee settings
+	slot0 := new(big.Int).Lsh(big.NewInt(7), 184) // observationIndex
+	slot0.Or(slot0, new(big.Int).Lsh(new(big.Int).And(big.NewInt(int64(pool.Tick)), big.NewInt(0xffffff)), 160))
+	slot0.Or(slot0, pool.SqrtPriceX96)
+	statedb.SetState(addr, common.BigToHash(new(big.Int).SetUint64(layout.Slot0)), common.BigToHash(slot0))
+	statedb.SetState(addr, common.BigToHash(new(big.Int).SetUint64(layout.Liquidity)), common.BigToHash(pool.Liquidity))
+
+	bitmaps := make(map[int16]*big.Int)
+	for _, tick := range pool.Ticks {
+		word, bit := tickPosition(tick.Index, pool.TickSpacing)
+		if bitmaps[word] == nil {
+			bitmaps[word] = new(big.Int)
+		}
+		bitmaps[word].SetBit(bitmaps[word], int(bit), 1)
+
+		// liquidityNet (int128) is packed above liquidityGross (uint128)
+		info := new(big.Int).And(tick.LiquidityNet, mask128)
+		info.Lsh(info, 128).Or(info, new(big.Int).Abs(tick.LiquidityNet))
+		statedb.SetState(addr, mappingSlot(big.NewInt(int64(tick.Index)), layout.Ticks), common.BigToHash(info))
+	}
+	for word, bitmap := range bitmaps {
+		statedb.SetState(addr, mappingSlot(big.NewInt(int64(word)), layout.TickBitmap), common.BigToHash(bitmap))
+	}
+
+	loaded, err := LoadV3Pool(statedb, addr, layout, pool.Fee, pool.TickSpacing, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.SqrtPriceX96.Cmp(pool.SqrtPriceX96) != 0 || loaded.Tick != pool.Tick || loaded.Liquidity.Cmp(pool.Liquidity) != 0 {
+		t.Fatalf("state mismatch: have %v/%d/%v", loaded.SqrtPriceX96, loaded.Tick, loaded.Liquidity)
+	}
+	if len(loaded.Ticks) != len(pool.Ticks) {
+		t.Fatalf("tick count mismatch: have %d, want %d", len(loaded.Ticks), len(pool.Ticks))
+	}
+	for i, tick := range loaded.Ticks {
+		if tick.Index != pool.Ticks[i].Index || tick.LiquidityNet.Cmp(pool.Ticks[i].LiquidityNet) != 0 {
+			t.Fatalf("tick %d mismatch: have %+v, want %+v", i, tick, pool.Ticks[i])
+		}
+	}
+	for i, tt := range v3SwapTests {
+		out, _, err := loaded.Swap(tt.amountIn, tt.zeroForOne)
+		if err != nil {
+			t.Fatalf("test %d: swap failed: %v", i, err)
+		}
+		if out.String() != tt.amountOut {
+			t.Errorf("test %d: amount out mismatch: have %v, want %v", i, out, tt.amountOut)
+		}
+	}
+
+	// With only the current bitmap word loaded, small swaps still work but
+	// larger ones need ticks that are unknown.
+	narrow, err := LoadV3Pool(statedb, addr, layout, pool.Fee, pool.TickSpacing, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, _, err := narrow.Swap(v3SwapTests[0].amountIn, true); err != nil || out.String() != v3SwapTests[0].amountOut {
+		t.Fatalf("small swap mismatch: have %v (%v), want %v", out, err, v3SwapTests[0].amountOut)
+	}
+	if _, _, err := narrow.Swap(ether(300_000), true); !errors.Is(err, ErrInsufficientTicks) {
+		t.Fatalf("large swap: have %v, want %v", err, ErrInsufficientTicks)
+	}
+	if _, err := LoadV3Pool(statedb, common.Address{0x02}, layout, pool.Fee, pool.TickSpacing, 2); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("uninitialised pool: have %v, want %v", err, ErrNoLiquidity)
+	}
+}
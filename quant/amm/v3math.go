@@ -0,0 +1,203 @@
+package amm
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tick range of V3 pools, see TickMath.sol.
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+// feeDenominator is the unit of V3 pool fees, hundredths of a basis point.
+const feeDenominator = 1_000_000
+
+var (
+	q96     = new(big.Int).Lsh(common.Big1, 96)
+	maxU256 = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 256), common.Big1)
+
+	// MinSqrtRatio and MaxSqrtRatio are the sqrt prices at MinTick and MaxTick.
+	MinSqrtRatio    = big.NewInt(4295128739)
+	MaxSqrtRatio, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970342", 10)
+
+	// tickRatios[i] is 2^128 / sqrt(1.0001)^(2^i), used to compute the price
+	// of a tick bit by bit.
+	tickRatios = []*big.Int{
+		hexBig("fffcb933bd6fad37aa2d162d1a594001"),
+		hexBig("fff97272373d413259a46990580e213a"),
+		hexBig("fff2e50f5f656932ef12357cf3c7fdcc"),
+		hexBig("ffe5caca7e10e4e61c3624eaa0941cd0"),
+		hexBig("ffcb9843d60f6159c9db58835c926644"),
+		hexBig("ff973b41fa98c081472e6896dfb254c0"),
+		hexBig("ff2ea16466c96a3843ec78b326b52861"),
+		hexBig("fe5dee046a99a2a811c461f1969c3053"),
+		hexBig("fcbe86c7900a88aedcffc83b479aa3a4"),
+		hexBig("f987a7253ac413176f2b074cf7815e54"),
+		hexBig("f3392b0822b70005940c7a398e4b70f3"),
+		hexBig("e7159475a2c29b7443b29c7fa6e889d9"),
+		hexBig("d097f3bdfd2022b8845ad8f792aa5825"),
+		hexBig("a9f746462d870fdf8a65dc1f90e061e5"),
+		hexBig("70d869a156d2a1b890bb3df62baf32f7"),
+		hexBig("31be135f97d08fd981231505542fcfa6"),
+		hexBig("9aa508b5b7a84e1c677de54f3e99bc9"),
+		hexBig("5d6af8dedb81196699c329225ee604"),
+		hexBig("2216e584f5fa1ea926041bedfe98"),
+		hexBig("48a170391f7dc42444e8fa2"),
+	}
+)
+
+func hexBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid constant " + s)
+	}
+	return n
+}
+
+// SqrtRatioAtTick returns sqrt(1.0001^tick) as a Q64.96, rounded exactly like
+// TickMath.getSqrtRatioAtTick. The tick must be within [MinTick, MaxTick].
+func SqrtRatioAtTick(tick int32) *big.Int {
+	absTick := int64(tick)
+	if absTick < 0 {
+		absTick = -absTick
+	}
+	ratio := new(big.Int).Lsh(common.Big1, 128)
+	if absTick&1 != 0 {
+		ratio.Set(tickRatios[0])
+	}
+	for i := 1; i < len(tickRatios); i++ {
+		if absTick&(1<<i) != 0 {
+			ratio.Mul(ratio, tickRatios[i])
+			ratio.Rsh(ratio, 128)
+		}
+	}
+	if tick > 0 {
+		ratio.Quo(maxU256, ratio)
+	}
+	// Convert the Q128.128 to a Q64.96, rounding up
+	return divRoundingUp(ratio, new(big.Int).Lsh(common.Big1, 32))
+}
+
+// TickAtSqrtRatio returns the greatest tick whose sqrt ratio is at most
+// sqrtPriceX96, like TickMath.getTickAtSqrtRatio.
+func TickAtSqrtRatio(sqrtPriceX96 *big.Int) int32 {
+	// Estimate the tick from the float price and correct the rounding error
+	// against the exact tick math.
+	price, _ := new(big.Float).Quo(new(big.Float).SetInt(sqrtPriceX96), new(big.Float).SetInt(q96)).Float64()
+	tick := int32(math.Floor(2 * math.Log(price) / math.Log(1.0001)))
+	tick = max(MinTick, min(MaxTick, tick))
+
+	for tick > MinTick && SqrtRatioAtTick(tick).Cmp(sqrtPriceX96) > 0 {
+		tick--
+	}
+	for tick < MaxTick && SqrtRatioAtTick(tick+1).Cmp(sqrtPriceX96) <= 0 {
+		tick++
+	}
+	return tick
+}
+
+// amount0Delta returns the token0 amount between two prices for the given
+// liquidity, see SqrtPriceMath.getAmount0Delta.
+func amount0Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	numerator2 := new(big.Int).Sub(sqrtB, sqrtA)
+	if roundUp {
+		return divRoundingUp(mulDivRoundingUp(numerator1, numerator2, sqrtB), sqrtA)
+	}
+	amount := mulDiv(numerator1, numerator2, sqrtB)
+	return amount.Quo(amount, sqrtA)
+}
+
+// amount1Delta returns the token1 amount between two prices for the given
+// liquidity, see SqrtPriceMath.getAmount1Delta.
+func amount1Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	diff := new(big.Int).Sub(sqrtB, sqrtA)
+	if roundUp {
+		return mulDivRoundingUp(liquidity, diff, q96)
+	}
+	return mulDiv(liquidity, diff, q96)
+}
+
+// nextSqrtPriceFromInput returns the price after adding amountIn of the input
+// token, see SqrtPriceMath.getNextSqrtPriceFromInput.
+func nextSqrtPriceFromInput(sqrtPrice, liquidity, amountIn *big.Int, zeroForOne bool) *big.Int {
+	if amountIn.Sign() == 0 {
+		return new(big.Int).Set(sqrtPrice)
+	}
+	if zeroForOne {
+		// Rounds up, so that the price moves at least as far as the input
+		// warrants.
+		numerator1 := new(big.Int).Lsh(liquidity, 96)
+		product := new(big.Int).Mul(amountIn, sqrtPrice)
+		if product.Cmp(maxU256) <= 0 {
+			denominator := new(big.Int).Add(numerator1, product)
+			if denominator.Cmp(maxU256) <= 0 {
+				return mulDivRoundingUp(numerator1, sqrtPrice, denominator)
+			}
+		}
+		// The contract falls back to a less precise formula on overflow
+		denominator := new(big.Int).Quo(numerator1, sqrtPrice)
+		return divRoundingUp(numerator1, denominator.Add(denominator, amountIn))
+	}
+	quotient := new(big.Int).Lsh(amountIn, 96)
+	quotient.Quo(quotient, liquidity)
+	return quotient.Add(quotient, sqrtPrice)
+}
+
+// swapStep is the outcome of swapping within a single price range.
+type swapStep struct {
+	sqrtPriceNext *big.Int
+	amountIn      *big.Int
+	amountOut     *big.Int
+	feeAmount     *big.Int
+}
+
+// computeSwapStep swaps up to amountRemaining of the input token between
+// sqrtPrice and sqrtTarget, see SwapMath.computeSwapStep in exact input mode.
+func computeSwapStep(sqrtPrice, sqrtTarget, liquidity, amountRemaining *big.Int, fee uint32) swapStep {
+	var (
+		zeroForOne = sqrtPrice.Cmp(sqrtTarget) >= 0
+		feeComp    = big.NewInt(int64(feeDenominator - fee))
+		step       swapStep
+	)
+	remainingLessFee := mulDiv(amountRemaining, feeComp, big.NewInt(feeDenominator))
+	if zeroForOne {
+		step.amountIn = amount0Delta(sqrtTarget, sqrtPrice, liquidity, true)
+	} else {
+		step.amountIn = amount1Delta(sqrtPrice, sqrtTarget, liquidity, true)
+	}
+	if remainingLessFee.Cmp(step.amountIn) >= 0 {
+		step.sqrtPriceNext = new(big.Int).Set(sqrtTarget)
+	} else {
+		step.sqrtPriceNext = nextSqrtPriceFromInput(sqrtPrice, liquidity, remainingLessFee, zeroForOne)
+	}
+	reached := step.sqrtPriceNext.Cmp(sqrtTarget) == 0
+	if zeroForOne {
+		if !reached {
+			step.amountIn = amount0Delta(step.sqrtPriceNext, sqrtPrice, liquidity, true)
+		}
+		step.amountOut = amount1Delta(step.sqrtPriceNext, sqrtPrice, liquidity, false)
+	} else {
+		if !reached {
+			step.amountIn = amount1Delta(sqrtPrice, step.sqrtPriceNext, liquidity, true)
+		}
+		step.amountOut = amount0Delta(sqrtPrice, step.sqrtPriceNext, liquidity, false)
+	}
+	if !reached {
+		// The remainder of the input is taken as fee
+		step.feeAmount = new(big.Int).Sub(amountRemaining, step.amountIn)
+	} else {
+		step.feeAmount = mulDivRoundingUp(step.amountIn, big.NewInt(int64(fee)), feeComp)
+	}
+	return step
+}
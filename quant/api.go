@@ -0,0 +1,48 @@
+package quant
+
+import (
+	"errors"
+)
+
+var errNoReloader = errors.New("quant config was not loaded from a file")
+
+// API exposes the quant watch list over the "quant" RPC namespace.
+type API struct {
+	q *Quant
+}
+
+// NewAPI creates a new quant RPC service.
+func NewAPI(q *Quant) *API {
+	return &API{q: q}
+}
+
+// Config returns the currently active watch list.
+func (api *API) Config() Config {
+	return api.q.watch.current()
+}
+
+// SetConfig replaces the active watch list. The pool subscriptions are
+// updated without restarting the node. The settings only read at startup keep
+// their values if unset, and the config is rejected if it changes them.
+func (api *API) SetConfig(config Config) (Config, error) {
+	if err := api.q.SetConfig(config.withStartup(api.q.startup)); err != nil {
+		return Config{}, err
+	}
+	return api.q.watch.current(), nil
+}
+
+// Reload re-reads the [Quant] section of the node config file and applies it.
+// The config is rejected if it changes the settings only read at startup.
+func (api *API) Reload() (Config, error) {
+	if api.q.reload == nil {
+		return Config{}, errNoReloader
+	}
+	config, err := api.q.reload()
+	if err != nil {
+		return Config{}, err
+	}
+	if err := api.q.SetConfig(config); err != nil {
+		return Config{}, err
+	}
+	return api.q.watch.current(), nil
+}
@@ -0,0 +1,240 @@
+package quant
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var errInvalidRange = errors.New("invalid block range")
+
+// StateAtBlockFunc returns the post-state of block, regenerating it if it is
+// no longer available on disk, along with a function releasing it.
+type StateAtBlockFunc func(ctx context.Context, block *types.Block) (*state.StateDB, func(), error)
+
+// BlockReport is the hypothetical outcome of running the strategy against
+// the transactions of a historical block.
+type BlockReport struct {
+	Number   uint64   `json:"number"`
+	Txs      int      `json:"txs"`
+	Swaps    int      `json:"swaps"`    // Transactions making a decodable swap
+	Hits     int      `json:"hits"`     // Swaps with a profitable sandwich
+	Failed   int      `json:"failed"`   // Swaps the strategy failed to evaluate
+	GasSpent uint64   `json:"gasSpent"` // Gas of the bundles that would have been sent
+	Profit   *big.Int `json:"profit"`   // Net profit in BNB
+}
+
+// HitRate returns the share of swaps the strategy found a sandwich for.
+func (r *BlockReport) HitRate() float64 {
+	if r.Swaps == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Swaps)
+}
+
+// MarshalJSON adds the hit rate to the encoded report.
+func (r *BlockReport) MarshalJSON() ([]byte, error) {
+	type report BlockReport
+	return json.Marshal(struct {
+		*report
+		HitRate float64 `json:"hitRate"`
+	}{(*report)(r), r.HitRate()})
+}
+
+// Backtester replays historical blocks through the strategy. Each swap of a
+// block is treated as if it was pending on top of the txs before it, which are
+// applied in order on the state of the parent.
+type Backtester struct {
+	chain    *core.BlockChain
+	strategy *Strategy
+	decoder  *Decoder
+	stateAt  StateAtBlockFunc
+}
+
+// NewBacktester creates a backtester reading blocks from chain and the parent
+// states from stateAt.
+func NewBacktester(chain *core.BlockChain, strategy *Strategy, stateAt StateAtBlockFunc) (*Backtester, error) {
+	decoder, err := NewDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return &Backtester{
+		chain:    chain,
+		strategy: strategy,
+		decoder:  decoder,
+		stateAt:  stateAt,
+	}, nil
+}
+
+// Run backtests the blocks from..to, inclusive, and calls report with the
+// outcome of each of them in order.
+func (b *Backtester) Run(ctx context.Context, from, to uint64, report func(*BlockReport) error) error {
+	if from == 0 || from > to {
+		return fmt.Errorf("%w: %d-%d", errInvalidRange, from, to)
+	}
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r, err := b.runBlock(ctx, number)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", number, err)
+		}
+		if err := report(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Backtester) runBlock(ctx context.Context, number uint64) (*BlockReport, error) {
+	block := b.chain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	parent := b.chain.GetBlock(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, errors.New("parent not found")
+	}
+	statedb, release, err := b.stateAt(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		config  = b.chain.Config()
+		header  = block.Header()
+		signer  = types.MakeSigner(config, header.Number, header.Time)
+		gp      = new(core.GasPool).AddGas(header.GasLimit)
+		evm     = vm.NewEVM(core.NewEVMBlockContext(header, b.chain, nil), statedb, config, vm.Config{})
+		usedGas uint64
+		report  = &BlockReport{Number: number, Txs: len(block.Transactions()), Profit: new(big.Int)}
+	)
+	posa, isPoSA := b.chain.Engine().(consensus.PoSA)
+	for i, tx := range block.Transactions() {
+		// The system txs are only applied when the block is finalised, at
+		// the end of the block.
+		if isPoSA {
+			if isSystemTx, err := posa.IsSystemTransaction(tx, header); err != nil {
+				return nil, err
+			} else if isSystemTx {
+				continue
+			}
+		}
+		if err := b.evaluate(parent.Header(), statedb, signer, tx, report); err != nil {
+			report.Failed++
+			log.Debug("Failed to evaluate backtest swap", "number", number, "hash", tx.Hash(), "err", err)
+		}
+		// Move on to the state the next tx was executed on
+		statedb.SetTxContext(tx.Hash(), i)
+		if _, err := core.ApplyTransaction(evm, gp, statedb, header, tx, &usedGas); err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+	}
+	return report, nil
+}
+
+// evaluate runs the strategy against tx if it is a swap, on statedb holding
+// the post-state of the txs before it in the block, and adds the outcome to
+// the report.
+func (b *Backtester) evaluate(parent *types.Header, statedb *state.StateDB, signer types.Signer, tx *types.Transaction, report *BlockReport) error {
+	intents, err := b.decoder.Decode(tx)
+	if err != nil {
+		return nil // Not a swap
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return err
+	}
+	report.Swaps++
+
+	swap := &PendingSwap{Tx: tx, Hash: tx.Hash(), From: from, Intents: intents}
+	_, opportunities, err := b.strategy.Evaluate(parent, statedb, swap)
+	if err != nil {
+		return err
+	}
+	if len(opportunities) > 0 {
+		report.Hits++
+	}
+	for _, opp := range opportunities {
+		report.GasSpent += opp.Gas
+		report.Profit.Add(report.Profit, opp.Profit)
+	}
+	return nil
+}
+
+// ReportWriter writes block reports in a tabular or streaming format.
+type ReportWriter interface {
+	Write(r *BlockReport) error
+	Flush() error
+}
+
+// NewReportWriter creates a writer encoding reports to w as "csv" or "json"
+// (one object per line).
+func NewReportWriter(w io.Writer, format string) (ReportWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVReportWriter(w)
+	case "json":
+		return &jsonReportWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+var reportHeader = []string{"number", "txs", "swaps", "hits", "failed", "hitRate", "gasSpent", "profit"}
+
+type csvReportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVReportWriter(w io.Writer) (*csvReportWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportHeader); err != nil {
+		return nil, err
+	}
+	return &csvReportWriter{w: cw}, nil
+}
+
+func (w *csvReportWriter) Write(r *BlockReport) error {
+	return w.w.Write([]string{
+		strconv.FormatUint(r.Number, 10),
+		strconv.Itoa(r.Txs),
+		strconv.Itoa(r.Swaps),
+		strconv.Itoa(r.Hits),
+		strconv.Itoa(r.Failed),
+		strconv.FormatFloat(r.HitRate(), 'f', 4, 64),
+		strconv.FormatUint(r.GasSpent, 10),
+		r.Profit.String(),
+	})
+}
+
+func (w *csvReportWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonReportWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonReportWriter) Write(r *BlockReport) error {
+	return w.enc.Encode(r)
+}
+
+func (w *jsonReportWriter) Flush() error {
+	return nil
+}
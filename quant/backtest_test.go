@@ -0,0 +1,144 @@
+package quant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBacktest(t *testing.T) {
+	gspec := &core.Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+		Alloc:   types.GenesisAlloc{testAddr: {Balance: testFunds}},
+	}
+	v3, _ := ParseRouterAbi()
+	swapData, err := v3.Pack("exactInputSingle", ExactInputParams{
+		TokenIn: testUSDT, TokenOut: testWBNB, Fee: big.NewInt(500), Recipient: testAddr, Deadline: big.NewInt(1_700_000_000),
+		AmountIn: big.NewInt(1000), AmountOutMinimum: big.NewInt(990), SqrtPriceLimitX96: common.Big0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Block 1 makes a plain transfer, block 2 a transfer and a swap through
+	// a router without a pool behind it. The swap is only valid after the
+	// transfer before it.
+	signer := types.LatestSigner(gspec.Config)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, b *core.BlockGen) {
+		b.AddTx(types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce: b.TxNonce(testAddr), To: &testRouter, Value: common.Big1, Gas: params.TxGas, GasPrice: b.BaseFee(),
+		}))
+		if i == 1 {
+			b.AddTx(types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+				Nonce: b.TxNonce(testAddr), To: &testRouter, Gas: 100_000, GasPrice: b.BaseFee(), Data: swapData,
+			}))
+		}
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	poolAbi, _ := ParseAbi()
+	strategy := &Strategy{poolAbi: poolAbi, simulator: NewSimulator(chain, nil)}
+
+	var parents []uint64
+	stateAt := func(ctx context.Context, block *types.Block) (*state.StateDB, func(), error) {
+		parents = append(parents, block.NumberU64())
+		statedb, err := chain.StateAt(block.Root())
+		return statedb, func() {}, err
+	}
+	backtester, err := NewBacktester(chain, strategy, stateAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reports []*BlockReport
+	err = backtester.Run(context.Background(), 1, 2, func(r *BlockReport) error {
+		reports = append(reports, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("backtest failed: %v", err)
+	}
+	if len(parents) != 2 || parents[0] != 0 || parents[1] != 1 {
+		t.Fatalf("unexpected parent states %v", parents)
+	}
+	want := []BlockReport{
+		{Number: 1, Txs: 1},
+		{Number: 2, Txs: 2, Swaps: 1},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("report count mismatch: have %d, want %d", len(reports), len(want))
+	}
+	for i, r := range reports {
+		if r.Number != want[i].Number || r.Txs != want[i].Txs || r.Swaps != want[i].Swaps || r.Hits != 0 || r.Failed != 0 || r.Profit.Sign() != 0 {
+			t.Errorf("report %d mismatch: have %+v, want %+v", i, r, want[i])
+		}
+	}
+
+	// Invalid and unknown ranges
+	noop := func(*BlockReport) error { return nil }
+	if err := backtester.Run(context.Background(), 2, 1, noop); !errors.Is(err, errInvalidRange) {
+		t.Fatalf("reversed range: have %v, want %v", err, errInvalidRange)
+	}
+	if err := backtester.Run(context.Background(), 2, 3, noop); err == nil {
+		t.Fatal("backtest beyond the head succeeded")
+	}
+}
+
+func TestReportWriter(t *testing.T) {
+	report := &BlockReport{Number: 7, Txs: 10, Swaps: 4, Hits: 1, Failed: 1, GasSpent: sandwichGas, Profit: big.NewInt(12345)}
+
+	var buf bytes.Buffer
+	w, err := NewReportWriter(&buf, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(report); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "number,txs,swaps,hits,failed,hitRate,gasSpent,profit\n7,10,4,1,1,0.2500,600000,12345\n"
+	if buf.String() != want {
+		t.Fatalf("csv mismatch:\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if w, err = NewReportWriter(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+	w.Write(report)
+	w.Write(report)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one object per line, have %q", buf.String())
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["number"] != 7.0 || obj["hitRate"] != 0.25 || obj["profit"] != 12345.0 {
+		t.Fatalf("unexpected json report %v", obj)
+	}
+	if _, err := NewReportWriter(&buf, "xml"); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
@@ -2,152 +2,505 @@ package quant
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
-type Builder struct {
-	Authorization string
+// Supported builder APIs.
+const (
+	BuilderBloxroute  = "bloxroute" // blxr_submit_bundle
+	Builder48Club     = "48club"    // eth_sendBundle with block and timestamp limits
+	BuilderSendBundle = "eth_sendBundle"
+)
+
+const (
+	defaultBuilderTimeout       = 3 * time.Second
+	defaultBuilderSubmitTimeout = 10 * time.Second
+	defaultBuilderRetries       = 2
+	builderRetryBackoff         = 100 * time.Millisecond
+)
+
+var (
+	errNoBuilders   = errors.New("no bundle builders configured")
+	errEmptyBundle  = errors.New("empty bundle")
+	errNoBundleHash = errors.New("builder returned no bundle hash")
+)
+
+// BuilderConfig is an endpoint bundles are submitted to.
+type BuilderConfig struct {
+	Name    string
+	Type    string // One of BuilderBloxroute, Builder48Club or BuilderSendBundle
+	URL     string
+	Auth    string        // Authorization header, e.g. the bloXroute auth token
+	Network string        `toml:",omitempty"` // Network name of bloXroute, BSC-Mainnet by default
+	Timeout time.Duration `toml:",omitempty"` // Timeout of a single attempt
+	Retries *int          `toml:",omitempty"` // Attempts after the first failed one, 2 if unset
+
+	// SubmitTimeout bounds a call to the builder, retries included, 10s by
+	// default. Each builder has its own, so a slow one doesn't hold up the
+	// others.
+	SubmitTimeout time.Duration `toml:",omitempty"`
 }
 
-func NewBuilder() *Builder {
-	return &Builder{
-		Authorization: "YWRjOTAwNDMtYjVhNy00ZGNiLWIyOTEtZWM0NWViNzVjOTE5Ojg4YmQ5MGNhNGFiZjIwYzgxN2MzODQ4MDRhMjJjNDg2",
+// Bundle is an ordered list of transactions to be included atomically at the
+// top of a block.
+type Bundle struct {
+	Txs             []*types.Transaction
+	BlockNumber     uint64        // Block the bundle targets
+	MaxBlockNumber  uint64        // Last block the bundle is valid for, BlockNumber if zero
+	MaxTimestamp    uint64        // Latest block timestamp, unlimited if zero
+	RevertingHashes []common.Hash // Txs allowed to revert
+}
+
+// BundleSubmitter sends bundles to a block builder.
+type BundleSubmitter interface {
+	// Name returns the name of the builder, used in logs and metrics.
+	Name() string
+
+	// SubmitBundle sends the bundle and returns the hash the builder tracks
+	// it by.
+	SubmitBundle(ctx context.Context, bundle *Bundle) (common.Hash, error)
+}
+
+// NewBundleSubmitter creates a submitter for the configured builder API.
+func NewBundleSubmitter(config BuilderConfig) (BundleSubmitter, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("builder %q has no url", config.Name)
+	}
+	if config.Name == "" {
+		config.Name = config.Type
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultBuilderTimeout
+	}
+	if config.SubmitTimeout == 0 {
+		config.SubmitTimeout = defaultBuilderSubmitTimeout
+	}
+	retries := defaultBuilderRetries
+	if config.Retries != nil {
+		if retries = *config.Retries; retries < 0 {
+			return nil, fmt.Errorf("builder %q has negative retries %d", config.Name, retries)
+		}
 	}
+	client := newBuilderClient(config, retries)
+	switch config.Type {
+	case BuilderBloxroute:
+		network := config.Network
+		if network == "" {
+			network = "BSC-Mainnet"
+		}
+		return &BloxrouteSubmitter{client: client, network: network}, nil
+	case Builder48Club:
+		return &Club48Submitter{client: client}, nil
+	case BuilderSendBundle:
+		return &SendBundleSubmitter{client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown builder type %q", config.Type)
+	}
+}
+
+// BloxrouteSubmitter submits bundles through the bloXroute MEV API.
+type BloxrouteSubmitter struct {
+	client  *builderClient
+	network string
 }
 
-func (b *Builder) RequestBscMevValidators() ([]string, error) {
-	payload := CommonRequest{
-		ID:     "1",
-		Method: "bsc_mev_validators",
-		Params: BscMevParams{
-			BlockchainNetwork: "BSC-Mainnet",
-		},
+func (s *BloxrouteSubmitter) Name() string { return s.client.name }
+
+// SubmitBundle implements BundleSubmitter.
+func (s *BloxrouteSubmitter) SubmitBundle(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	txs, err := encodeBundleTxs(bundle, false)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	params := BlxrSubmitBundleParams{
+		Transaction:       txs,
+		BlockchainNetwork: s.network,
+		BlockNumber:       hexutil.EncodeUint64(bundle.BlockNumber),
+	}
+	if bundle.MaxBlockNumber > bundle.BlockNumber {
+		params.BlocksCount = int(bundle.MaxBlockNumber-bundle.BlockNumber) + 1
+	}
+	if bundle.MaxTimestamp != 0 {
+		maxTimestamp := int64(bundle.MaxTimestamp)
+		params.MaxTimestamp = &maxTimestamp
+	}
+	for _, hash := range bundle.RevertingHashes {
+		params.RevertingHashes = append(params.RevertingHashes, hash.Hex())
 	}
+	result, err := s.client.call(ctx, "blxr_submit_bundle", params)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return parseBundleHash(result)
+}
 
-	result, err := RequestBscMev(b.Authorization, payload)
+// Validators returns the validators currently accepting bloXroute bundles.
+func (s *BloxrouteSubmitter) Validators(ctx context.Context) ([]string, error) {
+	result, err := s.client.call(ctx, "bsc_mev_validators", BscMevParams{BlockchainNetwork: s.network})
 	if err != nil {
 		return nil, err
 	}
-
-	var response BscMevResponse
+	var response struct {
+		Validators []string `json:"validators"`
+	}
 	if err := json.Unmarshal(result, &response); err != nil {
 		return nil, err
 	}
+	return response.Validators, nil
+}
 
-	return response.Result.Validators, nil
+// Club48Submitter submits bundles to 48Club style builders, which take the
+// block range and timestamp limits of the BSC builder API.
+type Club48Submitter struct {
+	client *builderClient
 }
 
-func (b *Builder) RequestBlxrSubmitBundle() error {
-	payload := CommonRequest{
-		ID:     "1",
-		Method: "blxr_submit_bundle",
-		Params: BlxrSubmitBundleParams{
-			Transaction: []string{
-				"0x1",
-				"0x2",
-			},
-			BlockchainNetwork: "bsc",
-			BlockNumber:       "123",
-		},
+func (s *Club48Submitter) Name() string { return s.client.name }
+
+// SubmitBundle implements BundleSubmitter.
+func (s *Club48Submitter) SubmitBundle(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	txs, err := encodeBundleTxs(bundle, true)
+	if err != nil {
+		return common.Hash{}, err
 	}
+	maxBlock := bundle.MaxBlockNumber
+	if maxBlock < bundle.BlockNumber {
+		maxBlock = bundle.BlockNumber
+	}
+	params := club48BundleArgs{
+		Txs:               txs,
+		MaxBlockNumber:    maxBlock,
+		MaxTimestamp:      bundle.MaxTimestamp,
+		RevertingTxHashes: bundle.RevertingHashes,
+	}
+	result, err := s.client.call(ctx, "eth_sendBundle", []interface{}{params})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return parseBundleHash(result)
+}
 
-	_, err := RequestBscMev(b.Authorization, payload)
-	return err
+// SendBundleSubmitter submits bundles with the Flashbots style eth_sendBundle
+// call targeting a single block.
+type SendBundleSubmitter struct {
+	client *builderClient
 }
 
-func (b *Builder) RequestBlxrSimulateBundle() error {
-	payload := CommonRequest{
-		ID:     "1",
-		Method: "blxr_simulate_bundle",
-		Params: BlxrSimulateBundleParams{
-			Transaction:       []string{"0x1", "0x2"},
-			BlockNumber:       "123",
-			StateBlockNumber:  "latest",
-			Timestamp:         1234567890,
-			BlockchainNetwork: "bsc",
-		},
+func (s *SendBundleSubmitter) Name() string { return s.client.name }
+
+// SubmitBundle implements BundleSubmitter.
+func (s *SendBundleSubmitter) SubmitBundle(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	txs, err := encodeBundleTxs(bundle, true)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	params := sendBundleArgs{
+		Txs:               txs,
+		BlockNumber:       hexutil.Uint64(bundle.BlockNumber),
+		RevertingTxHashes: bundle.RevertingHashes,
+	}
+	if bundle.MaxTimestamp != 0 {
+		params.MaxTimestamp = &bundle.MaxTimestamp
 	}
+	result, err := s.client.call(ctx, "eth_sendBundle", []interface{}{params})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return parseBundleHash(result)
+}
 
-	_, err := RequestBscMev(b.Authorization, payload)
-	return err
+type club48BundleArgs struct {
+	Txs               []string      `json:"txs"`
+	MaxBlockNumber    uint64        `json:"maxBlockNumber"`
+	MaxTimestamp      uint64        `json:"maxTimestamp,omitempty"`
+	RevertingTxHashes []common.Hash `json:"revertingTxHashes,omitempty"`
 }
 
-type BlxrSimulateBundleParams struct {
-	Transaction       []string `json:"transaction"`        // 一组不包含 0x 前缀的原始交易字节
-	BlockNumber       string   `json:"block_number"`       // 以十六进制表示的未来区块编号
-	StateBlockNumber  string   `json:"state_block_number"` // 指定要模拟的状态区块，可为 "latest" 等
-	Timestamp         int64    `json:"timestamp"`          // 用于模拟的时间戳，unix时间格式
-	BlockchainNetwork string   `json:"blockchain_network"` // 区块链网络名称，例如 Mainnet
+type sendBundleArgs struct {
+	Txs               []string       `json:"txs"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	MaxTimestamp      *uint64        `json:"maxTimestamp,omitempty"`
+	RevertingTxHashes []common.Hash  `json:"revertingTxHashes,omitempty"`
 }
 
-type BlxrSubmitBundleParams struct {
-	Transaction       []string `json:"transaction"`                // 一组不包含 0x 前缀的原始交易字节，以逗号分隔。
-	BlockchainNetwork string   `json:"blockchain_network"`         // 必须是 BSC-Mainnet。
-	BlockNumber       string   `json:"block_number"`               // 以十六进制表示的未来区块编号。
-	MinTimestamp      *int64   `json:"min_timestamp,omitempty"`    // [可选] 最小时间戳（unix 时间），默认为 None。
-	MaxTimestamp      *int64   `json:"max_timestamp,omitempty"`    // [可选] 最大时间戳（unix 时间），默认为 None。
-	RevertingHashes   []string `json:"reverting_hashes,omitempty"` // [可选] 允许回滚的交易哈希列表，默认空列表，若任意交易回滚则排除整个 bundle。
-	DroppingHashes    []string `json:"dropping_hashes,omitempty"`  // [可选] 在无效时可从 bundle 中移除的交易哈希列表，默认空列表。
-	BlocksCount       int      `json:"blocks_count,omitempty"`     // [可选，默认: 1] 指定 bundle 可用的后续区块数量，最大 20。
-	MevBuilders       struct {
-		All string `json:"all"`
-	} `json:"mev_builders,omitempty"` // [可选，默认: all] 指定接收此 bundle 的 MEV builder。bloxroute 始终可用。
-	AvoidMixedBundles bool `json:"avoid_mixed_bundles,omitempty"` // [可选，默认: false] 若为 false，允许与其他 bundle 或交易混合。
-	EndOfBlock        bool `json:"end_of_block,omitempty"`        // [可选，默认: false] 若为 true，将此 bundle 尽量放在区块末端。
+// encodeBundleTxs returns the hex encoded binary form of the bundle txs.
+// bloXroute expects them without the 0x prefix.
+func encodeBundleTxs(bundle *Bundle, prefix bool) ([]string, error) {
+	if len(bundle.Txs) == 0 {
+		return nil, errEmptyBundle
+	}
+	txs := make([]string, len(bundle.Txs))
+	for i, tx := range bundle.Txs {
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = hexutil.Encode(raw)
+		if !prefix {
+			txs[i] = strings.TrimPrefix(txs[i], "0x")
+		}
+	}
+	return txs, nil
+}
+
+// parseBundleHash extracts the bundle hash from a result that is either the
+// hash itself or an object holding it.
+func parseBundleHash(result json.RawMessage) (common.Hash, error) {
+	var hash common.Hash
+	if err := json.Unmarshal(result, &hash); err == nil {
+		return hash, nil
+	}
+	var obj struct {
+		BundleHash *common.Hash `json:"bundleHash"`
+	}
+	if err := json.Unmarshal(result, &obj); err != nil {
+		return common.Hash{}, err
+	}
+	if obj.BundleHash == nil {
+		return common.Hash{}, errNoBundleHash
+	}
+	return *obj.BundleHash, nil
 }
 
-type CommonRequest struct {
-	ID     string      `json:"id"`     // 请求的唯一标识符
-	Method string      `json:"method"` // 请求的方法名称，如 "submit_bundle"
-	Params interface{} `json:"params"` // 包含提交 bundle 的各项参数
+// builderClient posts JSON-RPC requests to a builder, retrying failed
+// attempts and tracking the latency of each builder.
+type builderClient struct {
+	name          string
+	url           string
+	auth          string
+	retries       int
+	timeout       time.Duration // Timeout of a single attempt
+	submitTimeout time.Duration // Timeout of a call, retries included
+	http          *http.Client
+
+	latency *metrics.Timer
+	success *metrics.Meter
+	failure *metrics.Meter
+	retry   *metrics.Meter
 }
 
-type BscMevParams struct {
-	BlockchainNetwork string `json:"blockchain_network"`
+func newBuilderClient(config BuilderConfig, retries int) *builderClient {
+	prefix := "quant/builder/" + config.Name
+	return &builderClient{
+		name:          config.Name,
+		url:           config.URL,
+		auth:          config.Auth,
+		retries:       retries,
+		timeout:       config.Timeout,
+		submitTimeout: config.SubmitTimeout,
+		http:          new(http.Client),
+		latency:       metrics.GetOrRegisterTimer(prefix+"/latency", nil),
+		success:       metrics.GetOrRegisterMeter(prefix+"/success", nil),
+		failure:       metrics.GetOrRegisterMeter(prefix+"/failure", nil),
+		retry:         metrics.GetOrRegisterMeter(prefix+"/retry", nil),
+	}
 }
 
-type BscMevResponse struct {
-	ID     string `json:"id"`
-	Result struct {
-		Validators []string `json:"validators"`
-	} `json:"result"`
-	JSONRPC string `json:"jsonrpc"`
+type builderRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type builderResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// builderError is an error returned by the builder itself, which is not
+// worth retrying.
+type builderError struct {
+	code    int
+	message string
+}
+
+func (e *builderError) Error() string {
+	return fmt.Sprintf("builder error %d: %s", e.code, e.message)
+}
+
+// builderStatusError is an HTTP error status returned by the builder.
+type builderStatusError struct {
+	code int
+	body string
 }
 
-// 直接使用 json.RawMessage 作为请求返回值
-func RequestBscMev(authorizationHeader string, payload interface{}) (json.RawMessage, error) {
-	url := "https://mev.api.blxrbdn.com"
+func (e *builderStatusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.code, e.body)
+}
+
+// retryable reports whether a failed request is worth retrying: the network
+// errors, the server errors and the rate limited requests.
+func retryable(err error) bool {
+	var (
+		statusErr *builderStatusError
+		netErr    net.Error
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	case errors.As(err, &netErr):
+		return true
+	default:
+		return errors.Is(err, io.ErrUnexpectedEOF)
+	}
+}
+
+// call sends a request, retrying network and server failures until the submit
+// timeout of the builder.
+func (c *builderClient) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.submitTimeout)
+	defer cancel()
 
-	reqBytes, err := json.Marshal(payload)
+	body, err := json.Marshal(builderRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
 	if err != nil {
 		return nil, err
 	}
+	var result json.RawMessage
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		result, err = c.post(ctx, body)
+		c.latency.UpdateSince(start)
+		if err == nil {
+			c.success.Mark(1)
+			return result, nil
+		}
+		c.failure.Mark(1)
+		if attempt >= c.retries || !retryable(err) {
+			return nil, err
+		}
+		c.retry.Mark(1)
+		select {
+		case <-time.After(builderRetryBackoff << attempt):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBytes))
+func (c *builderClient) post(ctx context.Context, body []byte) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", authorizationHeader)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if c.auth != "" {
+		req.Header.Set("Authorization", c.auth)
+	}
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, &builderStatusError{code: resp.StatusCode, body: string(data)}
+	}
+	var response builderResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, &builderError{code: response.Error.Code, message: response.Error.Message}
 	}
+	return response.Result, nil
+}
+
+// SubmitResult is the outcome of submitting a bundle to one builder.
+type SubmitResult struct {
+	Builder    string
+	BundleHash common.Hash
+	Err        error
+}
 
-	return bodyBytes, nil
+// Builder fans bundles out to all the configured builders.
+type Builder struct {
+	submitters []BundleSubmitter
+}
+
+// NewBuilder creates submitters for the given builder endpoints.
+func NewBuilder(configs []BuilderConfig) (*Builder, error) {
+	b := new(Builder)
+	for _, config := range configs {
+		s, err := NewBundleSubmitter(config)
+		if err != nil {
+			return nil, err
+		}
+		b.submitters = append(b.submitters, s)
+	}
+	return b, nil
+}
+
+// Submit sends bundle to all builders concurrently and returns the outcome
+// for each of them, in configuration order.
+func (b *Builder) Submit(ctx context.Context, bundle *Bundle) ([]SubmitResult, error) {
+	if len(b.submitters) == 0 {
+		return nil, errNoBuilders
+	}
+	var (
+		results = make([]SubmitResult, len(b.submitters))
+		wg      sync.WaitGroup
+	)
+	for i, s := range b.submitters {
+		wg.Add(1)
+		go func(i int, s BundleSubmitter) {
+			defer wg.Done()
+			hash, err := s.SubmitBundle(ctx, bundle)
+			results[i] = SubmitResult{Builder: s.Name(), BundleHash: hash, Err: err}
+		}(i, s)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+type BlxrSimulateBundleParams struct {
+	Transaction       []string `json:"transaction"`        // 一组不包含 0x 前缀的原始交易字节
+	BlockNumber       string   `json:"block_number"`       // 以十六进制表示的未来区块编号
+	StateBlockNumber  string   `json:"state_block_number"` // 指定要模拟的状态区块，可为 "latest" 等
+	Timestamp         int64    `json:"timestamp"`          // 用于模拟的时间戳，unix时间格式
+	BlockchainNetwork string   `json:"blockchain_network"` // 区块链网络名称，例如 Mainnet
+}
+
+type BlxrSubmitBundleParams struct {
+	Transaction       []string `json:"transaction"`                // 一组不包含 0x 前缀的原始交易字节，以逗号分隔。
+	BlockchainNetwork string   `json:"blockchain_network"`         // 必须是 BSC-Mainnet。
+	BlockNumber       string   `json:"block_number"`               // 以十六进制表示的未来区块编号。
+	MinTimestamp      *int64   `json:"min_timestamp,omitempty"`    // [可选] 最小时间戳（unix 时间），默认为 None。
+	MaxTimestamp      *int64   `json:"max_timestamp,omitempty"`    // [可选] 最大时间戳（unix 时间），默认为 None。
+	RevertingHashes   []string `json:"reverting_hashes,omitempty"` // [可选] 允许回滚的交易哈希列表，默认空列表，若任意交易回滚则排除整个 bundle。
+	DroppingHashes    []string `json:"dropping_hashes,omitempty"`  // [可选] 在无效时可从 bundle 中移除的交易哈希列表，默认空列表。
+	BlocksCount       int      `json:"blocks_count,omitempty"`     // [可选，默认: 1] 指定 bundle 可用的后续区块数量，最大 20。
+	MevBuilders       struct {
+		All string `json:"all"`
+	} `json:"mev_builders,omitempty"` // [可选，默认: all] 指定接收此 bundle 的 MEV builder。bloxroute 始终可用。
+	AvoidMixedBundles bool `json:"avoid_mixed_bundles,omitempty"` // [可选，默认: false] 若为 false，允许与其他 bundle 或交易混合。
+	EndOfBlock        bool `json:"end_of_block,omitempty"`        // [可选，默认: false] 若为 true，将此 bundle 尽量放在区块末端。
+}
+
+type BscMevParams struct {
+	BlockchainNetwork string `json:"blockchain_network"`
 }
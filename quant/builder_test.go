@@ -1,48 +1,352 @@
 package quant
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
-func TestRequestBscMev(t *testing.T) {
-	builder := NewBuilder()
-	validators, err := builder.RequestBscMevValidators()
-	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
+// fakeBuilder is a JSON-RPC bundle endpoint recording the requests it
+// receives. The first failures requests are answered with the status, a server
+// error by default.
+type fakeBuilder struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	status   int
+	result   interface{}
+	requests []builderRequest
+	params   []json.RawMessage
+	auth     []string
+}
+
+func newFakeBuilder(t *testing.T, result interface{}) *fakeBuilder {
+	b := &fakeBuilder{result: result}
+	b.Server = httptest.NewServer(http.HandlerFunc(b.serve))
+	t.Cleanup(b.Close)
+	return b
+}
+
+func (b *fakeBuilder) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		builderRequest
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.requests = append(b.requests, req.builderRequest)
+	b.params = append(b.params, req.Params)
+	b.auth = append(b.auth, r.Header.Get("Authorization"))
+	if b.failures > 0 {
+		b.failures--
+		status := b.status
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, "overloaded", status)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": b.result})
+}
+
+func testBundle(t *testing.T) *Bundle {
+	var txs []*types.Transaction
+	for i := uint64(0); i < 2; i++ {
+		txs = append(txs, types.NewTransaction(i, testRouter, common.Big0, 21000, big.NewInt(1e9), nil))
+	}
+	return &Bundle{Txs: txs, BlockNumber: 100, RevertingHashes: []common.Hash{txs[1].Hash()}}
+}
+
+func TestBundleSubmitters(t *testing.T) {
+	var (
+		bundle = testBundle(t)
+		hash   = common.HexToHash("0x1234")
+	)
+	raw0, _ := bundle.Txs[0].MarshalBinary()
+
+	tests := []struct {
+		typ    string
+		result interface{}
+		method string
+		check  func(params json.RawMessage) error
+	}{
+		{
+			typ:    BuilderBloxroute,
+			result: map[string]interface{}{"bundleHash": hash},
+			method: "blxr_submit_bundle",
+			check: func(params json.RawMessage) error {
+				var args BlxrSubmitBundleParams
+				if err := json.Unmarshal(params, &args); err != nil {
+					return err
+				}
+				// bloXroute takes the txs without the 0x prefix
+				if len(args.Transaction) != 2 || args.Transaction[0] != strings.TrimPrefix(hexutil.Encode(raw0), "0x") {
+					return errors.New("txs mismatch")
+				}
+				if args.BlockNumber != "0x64" || args.BlockchainNetwork != "BSC-Mainnet" || len(args.RevertingHashes) != 1 {
+					return errors.New("bundle options mismatch")
+				}
+				return nil
+			},
+		},
+		{
+			typ:    Builder48Club,
+			result: hash,
+			method: "eth_sendBundle",
+			check: func(params json.RawMessage) error {
+				var args []club48BundleArgs
+				if err := json.Unmarshal(params, &args); err != nil {
+					return err
+				}
+				if len(args) != 1 || len(args[0].Txs) != 2 || args[0].Txs[0] != hexutil.Encode(raw0) {
+					return errors.New("txs mismatch")
+				}
+				if args[0].MaxBlockNumber != 100 || len(args[0].RevertingTxHashes) != 1 {
+					return errors.New("bundle options mismatch")
+				}
+				return nil
+			},
+		},
+		{
+			typ:    BuilderSendBundle,
+			result: map[string]interface{}{"bundleHash": hash},
+			method: "eth_sendBundle",
+			check: func(params json.RawMessage) error {
+				var args []sendBundleArgs
+				if err := json.Unmarshal(params, &args); err != nil {
+					return err
+				}
+				if len(args) != 1 || len(args[0].Txs) != 2 || args[0].Txs[0] != hexutil.Encode(raw0) {
+					return errors.New("txs mismatch")
+				}
+				if args[0].BlockNumber != 100 || args[0].MaxTimestamp != nil {
+					return errors.New("bundle options mismatch")
+				}
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		fake := newFakeBuilder(t, tt.result)
+		s, err := NewBundleSubmitter(BuilderConfig{Type: tt.typ, URL: fake.URL, Auth: "secret"})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.typ, err)
+		}
+		have, err := s.SubmitBundle(context.Background(), bundle)
+		if err != nil {
+			t.Fatalf("%s: submit failed: %v", tt.typ, err)
+		}
+		if have != hash {
+			t.Errorf("%s: bundle hash mismatch: have %x, want %x", tt.typ, have, hash)
+		}
+		if len(fake.requests) != 1 || fake.requests[0].Method != tt.method {
+			t.Fatalf("%s: unexpected requests %+v", tt.typ, fake.requests)
+		}
+		if fake.auth[0] != "secret" {
+			t.Errorf("%s: auth header mismatch: %q", tt.typ, fake.auth[0])
+		}
+		if err := tt.check(fake.params[0]); err != nil {
+			t.Errorf("%s: invalid params %s: %v", tt.typ, fake.params[0], err)
+		}
+	}
+}
+
+func TestBundleSubmitterRetries(t *testing.T) {
+	hash := common.HexToHash("0x1234")
+	fake := newFakeBuilder(t, hash)
+	fake.failures = 2
+
+	retries := 2
+	config := BuilderConfig{Name: "retry", Type: BuilderSendBundle, URL: fake.URL, Retries: &retries}
+	s, _ := NewBundleSubmitter(config)
+	if have, err := s.SubmitBundle(context.Background(), testBundle(t)); err != nil || have != hash {
+		t.Fatalf("submit failed: %x %v", have, err)
+	}
+	if len(fake.requests) != 3 {
+		t.Fatalf("request count mismatch: have %d, want 3", len(fake.requests))
+	}
+	client := s.(*SendBundleSubmitter).client
+	if client.retry.Snapshot().Count() != 2 || client.failure.Snapshot().Count() != 2 || client.success.Snapshot().Count() != 1 {
+		t.Fatal("builder metrics not updated")
 	}
 
-	expectedValidators := []string{"0x18a...a09", "0xe91..c10"}
-	if !equal(validators, expectedValidators) {
-		t.Fatalf("expected %v, got %v", expectedValidators, validators)
+	// Give up once the retries run out
+	fake.failures = 3
+	if _, err := s.SubmitBundle(context.Background(), testBundle(t)); err == nil {
+		t.Fatal("submit succeeded with a failing builder")
+	}
+	if len(fake.requests) != 6 {
+		t.Fatalf("request count mismatch: have %d, want 6", len(fake.requests))
+	}
+}
+
+func TestBundleSubmitterRetryPolicy(t *testing.T) {
+	hash := common.HexToHash("0x1234")
+	tests := []struct {
+		status   int
+		retries  *int
+		requests int
+	}{
+		{http.StatusServiceUnavailable, nil, 2},      // Retried with the default retries
+		{http.StatusTooManyRequests, nil, 2},         // Rate limited requests are retried
+		{http.StatusBadRequest, nil, 1},              // Client errors are final
+		{http.StatusServiceUnavailable, new(int), 1}, // Retries disabled
+	}
+	for i, tt := range tests {
+		fake := newFakeBuilder(t, hash)
+		fake.failures, fake.status = 1, tt.status
+
+		s, err := NewBundleSubmitter(BuilderConfig{Name: "policy", Type: BuilderSendBundle, URL: fake.URL, Retries: tt.retries})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.SubmitBundle(context.Background(), testBundle(t))
+		if len(fake.requests) != tt.requests {
+			t.Errorf("test %d: request count mismatch: have %d, want %d", i, len(fake.requests), tt.requests)
+		}
+		if (err == nil) != (tt.requests > 1) {
+			t.Errorf("test %d: unexpected error %v", i, err)
+		}
+	}
+	negative := -1
+	if _, err := NewBundleSubmitter(BuilderConfig{Type: BuilderSendBundle, URL: "http://localhost", Retries: &negative}); err == nil {
+		t.Fatal("negative retries accepted")
+	}
+}
+
+func TestBundleSubmitterBuilderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle too large"}}`))
+	}))
+	defer srv.Close()
+
+	// Errors returned by the builder are final
+	s, _ := NewBundleSubmitter(BuilderConfig{Type: Builder48Club, URL: srv.URL, Timeout: time.Second})
+	_, err := s.SubmitBundle(context.Background(), testBundle(t))
+	var bErr *builderError
+	if !errors.As(err, &bErr) || bErr.message != "bundle too large" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.SubmitBundle(context.Background(), &Bundle{}); !errors.Is(err, errEmptyBundle) {
+		t.Fatalf("empty bundle: have %v, want %v", err, errEmptyBundle)
 	}
 }
 
-func TestRequestBlxrSubmitBundle(t *testing.T) {
+func TestBuilderSubmit(t *testing.T) {
+	hash := common.HexToHash("0x1234")
+	good := newFakeBuilder(t, hash)
+	bad := newFakeBuilder(t, hash)
+	bad.failures = 10
 
-	builder := NewBuilder()
-	err := builder.RequestBlxrSubmitBundle()
+	b, err := NewBuilder([]BuilderConfig{
+		{Name: "good", Type: BuilderSendBundle, URL: good.URL},
+		{Name: "bad", Type: Builder48Club, URL: bad.URL, Retries: new(int)},
+	})
 	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
+		t.Fatal(err)
+	}
+	results, err := b.Submit(context.Background(), testBundle(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Builder != "good" || results[1].Builder != "bad" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].Err != nil || results[0].BundleHash != hash || results[1].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if _, err := new(Builder).Submit(context.Background(), testBundle(t)); !errors.Is(err, errNoBuilders) {
+		t.Fatalf("no builders: have %v, want %v", err, errNoBuilders)
+	}
+	if _, err := NewBuilder([]BuilderConfig{{Type: "unknown", URL: good.URL}}); err == nil {
+		t.Fatal("unknown builder type accepted")
 	}
 }
 
-func TestRequestBlxrSimulateBundle(t *testing.T) {
+func TestBuilderSubmitTimeout(t *testing.T) {
+	hash := common.HexToHash("0x1234")
+	good := newFakeBuilder(t, hash)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
 
-	builder := NewBuilder()
-	err := builder.RequestBlxrSimulateBundle()
+	// The slow builder gives up on its own timeout, retries included
+	b, err := NewBuilder([]BuilderConfig{
+		{Name: "good", Type: BuilderSendBundle, URL: good.URL},
+		{Name: "slow", Type: BuilderSendBundle, URL: slow.URL, Timeout: time.Minute, SubmitTimeout: 100 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	results, err := b.Submit(context.Background(), testBundle(t))
 	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("submission took %v", elapsed)
+	}
+	if results[0].Err != nil || results[0].BundleHash != hash {
+		t.Fatalf("good builder failed: %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Fatalf("slow builder: have %v, want %v", results[1].Err, context.DeadlineExceeded)
 	}
 }
 
-func equal(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
+func TestStrategySubmitQueue(t *testing.T) {
+	hash := common.HexToHash("0x1234")
+	fake := newFakeBuilder(t, hash)
+	builder, err := NewBuilder([]BuilderConfig{{Name: "fake", Type: BuilderSendBundle, URL: fake.URL}})
+	if err != nil {
+		t.Fatal(err)
 	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
+	s := &Strategy{builder: builder, bundles: make(chan *Bundle, bundleSubmitQueueSize)}
+
+	// Enqueueing never blocks, the bundles beyond the queue size are dropped
+	for i := 0; i < bundleSubmitQueueSize+1; i++ {
+		s.enqueue(testBundle(t))
+	}
+	if len(s.bundles) != bundleSubmitQueueSize {
+		t.Fatalf("queued bundles: have %d, want %d", len(s.bundles), bundleSubmitQueueSize)
+	}
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.submitLoop(quit)
+		close(done)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		fake.mu.Lock()
+		n := len(fake.requests)
+		fake.mu.Unlock()
+		if n == bundleSubmitQueueSize {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("submitted bundles: have %d, want %d", n, bundleSubmitQueueSize)
 		}
 	}
-	return true
+	close(quit)
+	<-done
 }
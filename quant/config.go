@@ -0,0 +1,224 @@
+package quant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var errStartupSetting = errors.New("quant settings only read at startup changed, restart the node to apply them")
+
+// Config contains the watch list of the quant service. It is loaded from the
+// [Quant] section of the node TOML config and can be replaced at runtime
+// through the quant RPC namespace. The other settings are only read at
+// startup, and replacing the config with different ones is rejected.
+type Config struct {
+	Enabled bool
+	Pools   []common.Address // Pools whose Swap logs are tracked
+	Routers []common.Address // Routers whose pending calls are tracked
+	Senders []common.Address // Accounts whose pending transactions are tracked
+
+	// Balances lists the accounts whose balance changes are reported when
+	// simulating transactions, in addition to the coinbase. It is only read
+	// at startup.
+	Balances []common.Address
+
+	// Account signs the strategy transactions. It must be available in the
+	// node's account manager, e.g. an unlocked keystore account or an account
+	// of the external signer. The strategy doesn't run until it is set. It is
+	// only read at startup.
+	Account common.Address
+
+	// Sink configures where observed transactions and swaps are recorded. It
+	// is only read at startup.
+	Sink SinkConfig
+
+	// Builders lists the endpoints bundles are submitted to, along with their
+	// credentials. It is only read at startup.
+	Builders []BuilderConfig
+}
+
+// DefaultConfig contains the default watch list: the PancakeV3 USDT/WBNB pool
+// and the routers most frequently used to trade against it. It has no trading
+// account, the swaps are only recorded until one is configured.
+var DefaultConfig = Config{
+	Enabled: true,
+	Pools: []common.Address{
+		common.HexToAddress("0x36696169C63e42cd08ce11f5deeBbCeBae652050"),
+	},
+	Routers: []common.Address{
+		common.HexToAddress("0xDa77c035e4d5A748b4aB6674327Fa446F17098A2"),
+		common.HexToAddress("0x000000000008D5760657dD664c7096897E1AE801"),
+		common.HexToAddress("0x802b65b5d9016621E66003aeD0b16615093f328b"),
+		common.HexToAddress("0x1A0A18AC4BECDDbd6389559687d1A73d8927E416"),
+		common.HexToAddress("0x0000000055ECa968153aeFfa4e421f9cd2680f01"),
+		common.HexToAddress("0x17Cd8E8D4c64aa7f2a8Db6947b330885DA56b833"),
+		common.HexToAddress("0x32564234dF8961ae1d640bE2CBA4aEab54151551"),
+		common.HexToAddress("0x9333C74BDd1E118634fE5664ACA7a9710b108Bab"),
+		common.HexToAddress("0x36EbED00Ae87c55e1036a8af96dd96380958F4C3"),
+		common.HexToAddress("0x773ae23983e1e9720BAfe6E214971Ff762D9758D"),
+		common.HexToAddress("0x0000000040Ac064de24cBC1cB9FCbcbC033eE5B6"),
+		common.HexToAddress("0x5D80acAf6C75F2DEaDf935f21Bd09C9cc4d61d9B"),
+		common.HexToAddress("0x69460570c93f9DE5E2edbC3052bf10125f0Ca22d"),
+		common.HexToAddress("0x3e704f2bC43c6408a5Cf0638a977b82bFB20d748"),
+		common.HexToAddress("0x00000047bB99ea4D791bb749D970DE71EE0b1A34"),
+		common.HexToAddress("0xce16F69375520ab01377ce7B88f5BA8C48F8D666"),
+		common.HexToAddress("0x0000000256Cdb6d26cF9FD79229976b0fEaECdF4"),
+		common.HexToAddress("0xF552951F9D5f83E2D94D87FcA14545CD33E93d2C"),
+		common.HexToAddress("0x013bb8a204499523ddF717e0aBAA14E6dC849060"),
+		common.HexToAddress("0xe82c715e37f2f2E190dD2cA86Fb796CAFaF0bEFf"),
+	},
+	Sink: DefaultSinkConfig,
+}
+
+// withStartup returns the config with the unset settings read at startup taken
+// from the startup config.
+func (c Config) withStartup(startup Config) Config {
+	conf := c
+	if !conf.Enabled {
+		conf.Enabled = startup.Enabled
+	}
+	if conf.Balances == nil {
+		conf.Balances = startup.Balances
+	}
+	if conf.Account == (common.Address{}) {
+		conf.Account = startup.Account
+	}
+	if conf.Sink == (SinkConfig{}) {
+		conf.Sink = startup.Sink
+	}
+	if conf.Builders == nil {
+		conf.Builders = startup.Builders
+	}
+	return conf
+}
+
+// checkStartup returns an error listing the settings read at startup that
+// differ between c and the startup config.
+func (c Config) checkStartup(startup Config) error {
+	var changed []string
+	if c.Enabled != startup.Enabled {
+		changed = append(changed, "Enabled")
+	}
+	if !slices.Equal(dedupAddresses(c.Balances), dedupAddresses(startup.Balances)) {
+		changed = append(changed, "Balances")
+	}
+	if c.Account != startup.Account {
+		changed = append(changed, "Account")
+	}
+	if c.Sink != startup.Sink {
+		changed = append(changed, "Sink")
+	}
+	if !reflect.DeepEqual(c.Builders, startup.Builders) && (len(c.Builders) > 0 || len(startup.Builders) > 0) {
+		changed = append(changed, "Builders")
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("%w: %s", errStartupSetting, strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// sanitize removes duplicate entries from the watch list.
+func (c Config) sanitize() Config {
+	conf := c
+	conf.Pools = dedupAddresses(c.Pools)
+	conf.Routers = dedupAddresses(c.Routers)
+	conf.Senders = dedupAddresses(c.Senders)
+	return conf
+}
+
+func dedupAddresses(addrs []common.Address) []common.Address {
+	seen := make(map[common.Address]struct{}, len(addrs))
+	out := make([]common.Address, 0, len(addrs))
+	for _, addr := range addrs {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		out = append(out, addr)
+	}
+	return out
+}
+
+// watchList is the lookup form of Config, safe for concurrent use.
+type watchList struct {
+	mu      sync.RWMutex
+	config  Config
+	pools   map[common.Address]struct{}
+	routers map[common.Address]struct{}
+	senders map[common.Address]struct{}
+}
+
+func newWatchList(config Config) *watchList {
+	w := new(watchList)
+	w.update(config)
+	return w
+}
+
+// update replaces the watched addresses with the ones in config.
+func (w *watchList) update(config Config) {
+	config = config.sanitize()
+
+	// The sink and builders are fixed at startup, don't retain (and expose)
+	// their settings.
+	config.Sink = SinkConfig{}
+	config.Builders = nil
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.config = config
+	w.pools = toSet(config.Pools)
+	w.routers = toSet(config.Routers)
+	w.senders = toSet(config.Senders)
+}
+
+// current returns a copy of the active configuration.
+func (w *watchList) current() Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	conf := w.config
+	conf.Pools = append([]common.Address(nil), w.config.Pools...)
+	conf.Routers = append([]common.Address(nil), w.config.Routers...)
+	conf.Senders = append([]common.Address(nil), w.config.Senders...)
+	return conf
+}
+
+// isPool reports whether addr is a watched pool.
+func (w *watchList) isPool(addr common.Address) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	_, ok := w.pools[addr]
+	return ok
+}
+
+// matches reports whether a pending transaction sent from sender to the given
+// recipient should be tracked.
+func (w *watchList) matches(sender common.Address, to *common.Address) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if _, ok := w.senders[sender]; ok {
+		return true
+	}
+	if to == nil {
+		return false
+	}
+	_, ok := w.routers[*to]
+	return ok
+}
+
+func toSet(addrs []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
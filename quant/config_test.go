@@ -0,0 +1,69 @@
+package quant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWatchList(t *testing.T) {
+	var (
+		pool   = common.HexToAddress("0x01")
+		router = common.HexToAddress("0x02")
+		sender = common.HexToAddress("0x03")
+		other  = common.HexToAddress("0x04")
+	)
+	w := newWatchList(Config{
+		Pools:   []common.Address{pool, pool},
+		Routers: []common.Address{router},
+	})
+	if got := len(w.current().Pools); got != 1 {
+		t.Fatalf("duplicate pools not removed: have %d, want 1", got)
+	}
+	if !w.isPool(pool) || w.isPool(router) {
+		t.Fatal("pool lookup mismatch")
+	}
+	if !w.matches(other, &router) {
+		t.Fatal("tx to watched router not matched")
+	}
+	if w.matches(sender, &other) || w.matches(sender, nil) {
+		t.Fatal("tx from unwatched sender matched")
+	}
+
+	w.update(Config{Senders: []common.Address{sender}})
+	if w.isPool(pool) || w.matches(other, &router) {
+		t.Fatal("stale entries kept after update")
+	}
+	if !w.matches(sender, nil) {
+		t.Fatal("tx from watched sender not matched")
+	}
+}
+
+func TestConfigStartupSettings(t *testing.T) {
+	startup := Config{
+		Enabled:  true,
+		Balances: []common.Address{common.HexToAddress("0x01")},
+		Account:  common.HexToAddress("0x02"),
+		Sink:     DefaultSinkConfig,
+	}
+	// The watch list is replaced with the startup settings kept
+	update := Config{Pools: []common.Address{common.HexToAddress("0x03")}}
+	if err := update.withStartup(startup).checkStartup(startup); err != nil {
+		t.Fatalf("watch list update rejected: %v", err)
+	}
+	if err := update.checkStartup(startup); !errors.Is(err, errStartupSetting) {
+		t.Fatalf("unset startup settings accepted: have %v, want %v", err, errStartupSetting)
+	}
+	// Changed startup settings are rejected
+	changed := startup
+	changed.Account = common.HexToAddress("0x04")
+	if err := changed.withStartup(startup).checkStartup(startup); !errors.Is(err, errStartupSetting) {
+		t.Fatalf("changed account accepted: have %v, want %v", err, errStartupSetting)
+	}
+	changed = startup
+	changed.Sink.Type = SinkMemory
+	if err := changed.checkStartup(startup); !errors.Is(err, errStartupSetting) {
+		t.Fatalf("changed sink accepted: have %v, want %v", err, errStartupSetting)
+	}
+}
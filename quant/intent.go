@@ -0,0 +1,269 @@
+package quant
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// v3PathHop is the size of a token and fee pair in a packed V3 swap path.
+const v3PathHop = common.AddressLength + 3
+
+var (
+	errNotSwap       = errors.New("not a swap")
+	errInvalidPath   = errors.New("invalid swap path")
+	errNestedCall    = errors.New("nested multicall")
+	errShortCalldata = errors.New("calldata too short")
+)
+
+// SwapIntent is a swap requested from a router, decoded from the calldata of
+// a pending transaction. Exact input swaps set AmountIn and AmountOutMin,
+// exact output swaps set AmountOut and AmountInMax.
+type SwapIntent struct {
+	Router    common.Address   `json:"router"`
+	Method    string           `json:"method"`
+	Path      []common.Address `json:"path"`           // Tokens from input to output
+	Fees      []uint32         `json:"fees,omitempty"` // Pool fee of each V3 hop
+	Recipient common.Address   `json:"recipient"`
+
+	ExactInput   bool     `json:"exactInput"`
+	AmountIn     *big.Int `json:"amountIn,omitempty"`
+	AmountOutMin *big.Int `json:"amountOutMin,omitempty"`
+	AmountOut    *big.Int `json:"amountOut,omitempty"`
+	AmountInMax  *big.Int `json:"amountInMax,omitempty"`
+
+	Deadline          *big.Int `json:"deadline,omitempty"`
+	SqrtPriceLimitX96 *big.Int `json:"sqrtPriceLimitX96,omitempty"`
+}
+
+// TokenIn returns the token paid into the swap.
+func (i *SwapIntent) TokenIn() common.Address {
+	return i.Path[0]
+}
+
+// TokenOut returns the token received from the swap.
+func (i *SwapIntent) TokenOut() common.Address {
+	return i.Path[len(i.Path)-1]
+}
+
+// PendingSwap is a watched pending transaction along with the swaps it makes.
+type PendingSwap struct {
+	Tx        *types.Transaction `json:"-"`
+	Hash      common.Hash        `json:"hash"`
+	From      common.Address     `json:"from"`
+	To        common.Address     `json:"to"`
+	Nonce     uint64             `json:"nonce"`
+	Gas       uint64             `json:"gas"`
+	GasPrice  *big.Int           `json:"gasPrice"`
+	Intents   []*SwapIntent      `json:"intents"`
+	Timestamp string             `json:"timestamp"`
+}
+
+type exactInputParams struct {
+	Path             []byte
+	Recipient        common.Address
+	Deadline         *big.Int
+	AmountIn         *big.Int
+	AmountOutMinimum *big.Int
+}
+
+type exactOutputParams struct {
+	Path            []byte
+	Recipient       common.Address
+	Deadline        *big.Int
+	AmountOut       *big.Int
+	AmountInMaximum *big.Int
+}
+
+// Decoder turns router calldata into swap intents.
+type Decoder struct {
+	v3 *abi.ABI
+	v2 *abi.ABI
+}
+
+// NewDecoder creates a decoder for the PancakeV3 swap router and UniswapV2
+// style routers.
+func NewDecoder() (*Decoder, error) {
+	v3, err := ParseRouterAbi()
+	if err != nil {
+		return nil, err
+	}
+	v2, err := ParseV2RouterAbi()
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{v3: v3, v2: v2}, nil
+}
+
+// Decode returns the swaps made by tx. Calls other than swaps, such as the
+// refunds and unwraps bundled in a multicall, are skipped. errNotSwap is
+// returned if tx makes no swap at all.
+func (d *Decoder) Decode(tx *types.Transaction) ([]*SwapIntent, error) {
+	if tx.To() == nil {
+		return nil, errNotSwap
+	}
+	intents, err := d.decode(*tx.To(), tx.Data(), tx.Value(), false)
+	if err != nil {
+		return nil, err
+	}
+	if len(intents) == 0 {
+		return nil, errNotSwap
+	}
+	return intents, nil
+}
+
+func (d *Decoder) decode(router common.Address, data []byte, value *big.Int, nested bool) ([]*SwapIntent, error) {
+	if len(data) < 4 {
+		return nil, errShortCalldata
+	}
+	if method, err := d.v3.MethodById(data[:4]); err == nil {
+		return d.decodeV3(router, method, data[4:], value, nested)
+	}
+	if method, err := d.v2.MethodById(data[:4]); err == nil {
+		intent, err := d.decodeV2(router, method, data[4:], value)
+		if err != nil {
+			return nil, err
+		}
+		return []*SwapIntent{intent}, nil
+	}
+	return nil, errNotSwap
+}
+
+func (d *Decoder) decodeV3(router common.Address, method *abi.Method, data []byte, value *big.Int, nested bool) ([]*SwapIntent, error) {
+	args, err := method.Inputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", method.Name, err)
+	}
+	intent := &SwapIntent{Router: router, Method: method.Name}
+	switch method.Name {
+	case "exactInputSingle":
+		params := abi.ConvertType(args[0], new(ExactInputParams)).(*ExactInputParams)
+		intent.Path = []common.Address{params.TokenIn, params.TokenOut}
+		intent.Fees = []uint32{uint32(params.Fee.Uint64())}
+		intent.Recipient = params.Recipient
+		intent.ExactInput = true
+		intent.AmountIn = params.AmountIn
+		intent.AmountOutMin = params.AmountOutMinimum
+		intent.Deadline = params.Deadline
+		intent.SqrtPriceLimitX96 = params.SqrtPriceLimitX96
+
+	case "exactOutputSingle":
+		params := abi.ConvertType(args[0], new(ExactOutPutParams)).(*ExactOutPutParams)
+		intent.Path = []common.Address{params.TokenIn, params.TokenOut}
+		intent.Fees = []uint32{uint32(params.Fee.Uint64())}
+		intent.Recipient = params.Recipient
+		intent.AmountOut = params.AmountOut
+		intent.AmountInMax = params.AmountInMaximum
+		intent.Deadline = params.Deadline
+		intent.SqrtPriceLimitX96 = params.SqrtPriceLimitX96
+
+	case "exactInput":
+		params := abi.ConvertType(args[0], new(exactInputParams)).(*exactInputParams)
+		if intent.Path, intent.Fees, err = decodeV3Path(params.Path); err != nil {
+			return nil, err
+		}
+		intent.Recipient = params.Recipient
+		intent.ExactInput = true
+		intent.AmountIn = params.AmountIn
+		intent.AmountOutMin = params.AmountOutMinimum
+		intent.Deadline = params.Deadline
+
+	case "exactOutput":
+		params := abi.ConvertType(args[0], new(exactOutputParams)).(*exactOutputParams)
+		if intent.Path, intent.Fees, err = decodeV3Path(params.Path); err != nil {
+			return nil, err
+		}
+		// Exact output paths are encoded from the output token backwards
+		slices.Reverse(intent.Path)
+		slices.Reverse(intent.Fees)
+		intent.Recipient = params.Recipient
+		intent.AmountOut = params.AmountOut
+		intent.AmountInMax = params.AmountInMaximum
+		intent.Deadline = params.Deadline
+
+	case "multicall":
+		if nested {
+			return nil, errNestedCall
+		}
+		var intents []*SwapIntent
+		for _, call := range args[0].([][]byte) {
+			inner, err := d.decode(router, call, value, true)
+			if errors.Is(err, errNotSwap) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			intents = append(intents, inner...)
+		}
+		return intents, nil
+
+	default:
+		return nil, errNotSwap
+	}
+	return []*SwapIntent{intent}, nil
+}
+
+func (d *Decoder) decodeV2(router common.Address, method *abi.Method, data []byte, value *big.Int) (*SwapIntent, error) {
+	args, err := method.Inputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", method.Name, err)
+	}
+	var params struct {
+		AmountIn     *big.Int
+		AmountInMax  *big.Int
+		AmountOut    *big.Int
+		AmountOutMin *big.Int
+		Path         []common.Address
+		To           common.Address
+		Deadline     *big.Int
+	}
+	if err := method.Inputs.Copy(&params, args); err != nil {
+		return nil, err
+	}
+	if len(params.Path) < 2 {
+		return nil, errInvalidPath
+	}
+	intent := &SwapIntent{
+		Router:       router,
+		Method:       method.Name,
+		Path:         params.Path,
+		Recipient:    params.To,
+		AmountIn:     params.AmountIn,
+		AmountInMax:  params.AmountInMax,
+		AmountOut:    params.AmountOut,
+		AmountOutMin: params.AmountOutMin,
+		Deadline:     params.Deadline,
+	}
+	// The native token amount is the tx value
+	switch method.Name {
+	case "swapExactETHForTokens":
+		intent.AmountIn = new(big.Int).Set(value)
+	case "swapETHForExactTokens":
+		intent.AmountInMax = new(big.Int).Set(value)
+	}
+	intent.ExactInput = intent.AmountIn != nil
+	return intent, nil
+}
+
+// decodeV3Path splits a packed V3 path, token (20 bytes) followed by fee
+// (3 bytes) and token for each hop.
+func decodeV3Path(path []byte) ([]common.Address, []uint32, error) {
+	if len(path) < common.AddressLength+v3PathHop || (len(path)-common.AddressLength)%v3PathHop != 0 {
+		return nil, nil, errInvalidPath
+	}
+	var (
+		tokens = []common.Address{common.BytesToAddress(path[:common.AddressLength])}
+		fees   []uint32
+	)
+	for rest := path[common.AddressLength:]; len(rest) > 0; rest = rest[v3PathHop:] {
+		fees = append(fees, uint32(rest[0])<<16|uint32(rest[1])<<8|uint32(rest[2]))
+		tokens = append(tokens, common.BytesToAddress(rest[3:v3PathHop]))
+	}
+	return tokens, fees, nil
+}
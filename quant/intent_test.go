@@ -0,0 +1,186 @@
+package quant
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testRouter = common.HexToAddress("0x1b81D678ffb9C0263b24A97847620C99d213eB14")
+	testUSDT   = common.HexToAddress("0x55d398326f99059fF775485246999027B3197955")
+	testWBNB   = common.HexToAddress("0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c")
+	testCAKE   = common.HexToAddress("0x0E09FaBB73Bd3Ade0a17ECC321fD13a19e81cE82")
+)
+
+func encodeV3Path(tokens []common.Address, fees []uint32) []byte {
+	path := tokens[0].Bytes()
+	for i, fee := range fees {
+		path = append(path, byte(fee>>16), byte(fee>>8), byte(fee))
+		path = append(path, tokens[i+1].Bytes()...)
+	}
+	return path
+}
+
+func TestDecodeSwapIntents(t *testing.T) {
+	decoder, err := NewDecoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3, _ := ParseRouterAbi()
+	v2, _ := ParseV2RouterAbi()
+	pack := func(abi interface {
+		Pack(string, ...interface{}) ([]byte, error)
+	}, method string, args ...interface{}) []byte {
+		data, err := abi.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		return data
+	}
+	var (
+		deadline  = big.NewInt(1_700_000_000)
+		recipient = common.HexToAddress("0x000000000000000000000000000000000000beef")
+	)
+	exactInputSingle := pack(v3, "exactInputSingle", ExactInputParams{
+		TokenIn: testUSDT, TokenOut: testWBNB, Fee: big.NewInt(500), Recipient: recipient, Deadline: deadline,
+		AmountIn: big.NewInt(1000), AmountOutMinimum: big.NewInt(990), SqrtPriceLimitX96: common.Big0,
+	})
+	tests := []struct {
+		name  string
+		data  []byte
+		value *big.Int
+		want  []*SwapIntent
+	}{
+		{
+			name: "exactInputSingle",
+			data: exactInputSingle,
+			want: []*SwapIntent{{
+				Method: "exactInputSingle", Path: []common.Address{testUSDT, testWBNB}, Fees: []uint32{500},
+				ExactInput: true, AmountIn: big.NewInt(1000), AmountOutMin: big.NewInt(990),
+			}},
+		},
+		{
+			name: "exactOutputSingle",
+			data: pack(v3, "exactOutputSingle", ExactOutPutParams{
+				TokenIn: testWBNB, TokenOut: testUSDT, Fee: big.NewInt(100), Recipient: recipient, Deadline: deadline,
+				AmountOut: big.NewInt(600), AmountInMaximum: big.NewInt(2), SqrtPriceLimitX96: common.Big0,
+			}),
+			want: []*SwapIntent{{
+				Method: "exactOutputSingle", Path: []common.Address{testWBNB, testUSDT}, Fees: []uint32{100},
+				AmountOut: big.NewInt(600), AmountInMax: big.NewInt(2),
+			}},
+		},
+		{
+			name: "exactInput",
+			data: pack(v3, "exactInput", exactInputParams{
+				Path:      encodeV3Path([]common.Address{testCAKE, testWBNB, testUSDT}, []uint32{2500, 500}),
+				Recipient: recipient, Deadline: deadline, AmountIn: big.NewInt(50), AmountOutMinimum: big.NewInt(70),
+			}),
+			want: []*SwapIntent{{
+				Method: "exactInput", Path: []common.Address{testCAKE, testWBNB, testUSDT}, Fees: []uint32{2500, 500},
+				ExactInput: true, AmountIn: big.NewInt(50), AmountOutMin: big.NewInt(70),
+			}},
+		},
+		{
+			// The path of exact output swaps starts with the output token
+			name: "exactOutput",
+			data: pack(v3, "exactOutput", exactOutputParams{
+				Path:      encodeV3Path([]common.Address{testUSDT, testWBNB, testCAKE}, []uint32{500, 2500}),
+				Recipient: recipient, Deadline: deadline, AmountOut: big.NewInt(70), AmountInMaximum: big.NewInt(60),
+			}),
+			want: []*SwapIntent{{
+				Method: "exactOutput", Path: []common.Address{testCAKE, testWBNB, testUSDT}, Fees: []uint32{2500, 500},
+				AmountOut: big.NewInt(70), AmountInMax: big.NewInt(60),
+			}},
+		},
+		{
+			// Non-swap calls of a multicall are skipped
+			name: "multicall",
+			data: pack(v3, "multicall", [][]byte{
+				exactInputSingle,
+				pack(v3, "unwrapWETH9", big.NewInt(990), recipient),
+			}),
+			want: []*SwapIntent{{
+				Method: "exactInputSingle", Path: []common.Address{testUSDT, testWBNB}, Fees: []uint32{500},
+				ExactInput: true, AmountIn: big.NewInt(1000), AmountOutMin: big.NewInt(990),
+			}},
+		},
+		{
+			name: "swapExactTokensForTokens",
+			data: pack(v2, "swapExactTokensForTokens", big.NewInt(1000), big.NewInt(990),
+				[]common.Address{testUSDT, testWBNB, testCAKE}, recipient, deadline),
+			want: []*SwapIntent{{
+				Method: "swapExactTokensForTokens", Path: []common.Address{testUSDT, testWBNB, testCAKE},
+				ExactInput: true, AmountIn: big.NewInt(1000), AmountOutMin: big.NewInt(990),
+			}},
+		},
+		{
+			// The input of native token swaps is the tx value
+			name:  "swapExactETHForTokens",
+			data:  pack(v2, "swapExactETHForTokens", big.NewInt(590), []common.Address{testWBNB, testUSDT}, recipient, deadline),
+			value: big.NewInt(params.Ether),
+			want: []*SwapIntent{{
+				Method: "swapExactETHForTokens", Path: []common.Address{testWBNB, testUSDT},
+				ExactInput: true, AmountIn: big.NewInt(params.Ether), AmountOutMin: big.NewInt(590),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		value := tt.value
+		if value == nil {
+			value = common.Big0
+		}
+		tx := types.NewTransaction(0, testRouter, value, 300000, common.Big1, tt.data)
+		intents, err := decoder.Decode(tx)
+		if err != nil {
+			t.Fatalf("%s: decode failed: %v", tt.name, err)
+		}
+		if len(intents) != len(tt.want) {
+			t.Fatalf("%s: intent count mismatch: have %d, want %d", tt.name, len(intents), len(tt.want))
+		}
+		for i, have := range intents {
+			want := tt.want[i]
+			if have.Router != testRouter || have.Recipient != recipient || have.Deadline.Cmp(deadline) != 0 {
+				t.Errorf("%s: call mismatch: %+v", tt.name, have)
+			}
+			if have.Method != want.Method || !slices.Equal(have.Path, want.Path) || !slices.Equal(have.Fees, want.Fees) {
+				t.Errorf("%s: route mismatch: have %s %v %v, want %s %v %v", tt.name, have.Method, have.Path, have.Fees, want.Method, want.Path, want.Fees)
+			}
+			if have.ExactInput != want.ExactInput || !equalAmount(have.AmountIn, want.AmountIn) || !equalAmount(have.AmountOutMin, want.AmountOutMin) ||
+				!equalAmount(have.AmountOut, want.AmountOut) || !equalAmount(have.AmountInMax, want.AmountInMax) {
+				t.Errorf("%s: amounts mismatch: have %+v, want %+v", tt.name, have, want)
+			}
+		}
+	}
+
+	// Calls that are not swaps
+	erc20, _ := ParseERC20Abi()
+	notSwaps := [][]byte{
+		pack(erc20, "balanceOf", recipient),
+		pack(v3, "multicall", [][]byte{pack(v3, "refundETH")}),
+		nil,
+	}
+	for i, data := range notSwaps {
+		tx := types.NewTransaction(0, testRouter, common.Big0, 300000, common.Big1, data)
+		if _, err := decoder.Decode(tx); err == nil {
+			t.Errorf("call %d: decoded as swap", i)
+		}
+	}
+	nested := pack(v3, "multicall", [][]byte{pack(v3, "multicall", [][]byte{exactInputSingle})})
+	if _, err := decoder.Decode(types.NewTransaction(0, testRouter, common.Big0, 300000, common.Big1, nested)); !errors.Is(err, errNestedCall) {
+		t.Fatalf("nested multicall: have %v, want %v", err, errNestedCall)
+	}
+}
+
+func equalAmount(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Cmp(b) == 0
+}
@@ -2,30 +2,25 @@ package quant
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"math"
 	"math/big"
-	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/filters"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
-const poolAddress = "0x36696169C63e42cd08ce11f5deeBbCeBae652050"
-
 type Quant struct {
 	abi            *abi.ABI
 	apiBackend     ethapi.Backend
@@ -33,106 +28,138 @@ type Quant struct {
 	txpoolApi      *ethapi.TxPoolAPI
 	transactionApi *ethapi.TransactionAPI
 	blockChainApi  *ethapi.BlockChainAPI
-	Contract       *bind.BoundContract
-	httpClient     *ethclient.Client
 	storage        *Storage
 	strategy       *Strategy
+	trading        bool // Whether the strategy runs, only once a trading account is configured
+	tokens         *TokenRegistry
+	decoder        *Decoder
+
+	watch    *watchList
+	startup  Config                 // Config the service was started with
+	reload   func() (Config, error) // Re-reads the config file, nil if there is none
+	reloadCh chan struct{}          // Notifies Loop to resubscribe to the watched pools
+	quit     chan struct{}
+	wg       sync.WaitGroup
 }
 
-func NewQuant(apibackend ethapi.Backend, eth *eth.Ethereum) *Quant {
+func NewQuant(apibackend ethapi.Backend, eth *eth.Ethereum, config Config, reload func() (Config, error)) *Quant {
 	nonceLock := new(ethapi.AddrLocker)
 	txApi := ethapi.NewTxPoolAPI(apibackend)
 	transactionApi := ethapi.NewTransactionAPI(apibackend, nonceLock)
 	blockChainApi := ethapi.NewBlockChainAPI(apibackend)
 
-	strategy := NewStrategy(apibackend, eth)
-
-	client, err := ethclient.Dial("http://localhost:8545")
+	signer := NewSigner(eth.AccountManager(), config.Account, eth.BlockChain().Config().ChainID, apibackend)
+	builder, err := NewBuilder(config.Builders)
 	if err != nil {
-		log.Printf("Failed to init ethclient: %v", err)
+		log.Error("Failed to init bundle builders", "err", err)
 		return nil
 	}
+	strategy := NewStrategy(apibackend, eth, signer, builder, config.Balances)
+	trading := config.Account != (common.Address{})
+	if !trading {
+		log.Warn("Quant strategy disabled, no trading account configured")
+	}
 
-	abi, _ := ParseAbi()
-	bc := bind.NewBoundContract(common.HexToAddress(poolAddress), *abi, client, client, client)
+	abi, err := ParseAbi()
+	if err != nil {
+		log.Error("Failed to parse pool abi", "err", err)
+		return nil
+	}
+	decoder, err := NewDecoder()
+	if err != nil {
+		log.Error("Failed to parse router abis", "err", err)
+		return nil
+	}
+	tokens, err := NewTokenRegistry(apibackend)
+	if err != nil {
+		log.Error("Failed to init token registry", "err", err)
+		return nil
+	}
+	sink, err := NewSink(config.Sink)
+	if err != nil {
+		log.Error("Failed to init storage sink", "err", err)
+		return nil
+	}
 
 	return &Quant{
 		apiBackend:     apibackend,
 		eth:            eth,
 		txpoolApi:      txApi,
 		transactionApi: transactionApi,
-		httpClient:     client,
-		Contract:       bc,
-		storage:        NewStorage(),
+		storage:        NewStorage(sink, config.Sink),
 		blockChainApi:  blockChainApi,
 		abi:            abi,
 		strategy:       strategy,
+		trading:        trading,
+		tokens:         tokens,
+		decoder:        decoder,
+		watch:          newWatchList(config),
+		startup:        config,
+		reload:         reload,
+		reloadCh:       make(chan struct{}, 1),
+		quit:           make(chan struct{}),
 	}
 }
 
-func ParseAbi() (*abi.ABI, error) {
-	abiFile, err := os.Open("/home/allenmwang/remote/test/bsc/quant/abi/pancakeV3PoolABI.json")
-	if err != nil {
-		return nil, err
+// APIs returns the RPC services offered by the quant service.
+func (q *Quant) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "quant",
+			Service:   NewAPI(q),
+		},
+		{
+			Namespace: "mev",
+			Service:   NewSBundleAPI(q),
+		},
 	}
-	defer abiFile.Close()
+}
 
-	abiBytes, err := io.ReadAll(abiFile)
-	if err != nil {
-		return nil, err
+// Start implements node.Lifecycle, starting the event loop and, if the
+// strategy runs, the bundle submission workers.
+func (q *Quant) Start() error {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.Loop()
+	}()
+	if !q.trading {
+		return nil
 	}
-
-	parsedABI, err := abi.JSON(strings.NewReader(string(abiBytes)))
-	if err != nil {
-		return nil, err
+	for i := 0; i < bundleSubmitWorkers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.strategy.submitLoop(q.quit)
+		}()
 	}
-	return &parsedABI, nil
+	return nil
 }
 
-func ParseRouterAbi() (*abi.ABI, error) {
-	abiFile, err := os.Open("/home/allenmwang/remote/test/bsc/quant/abi/v3swapRouterABI.json")
-	if err != nil {
-		return nil, err
-	}
-	defer abiFile.Close()
+// Stop implements node.Lifecycle, terminating the event loop and flushing the
+// documents still queued for storage.
+func (q *Quant) Stop() error {
+	close(q.quit)
+	q.wg.Wait()
+	return q.storage.Close()
+}
 
-	abiBytes, err := io.ReadAll(abiFile)
-	if err != nil {
-		return nil, err
+// SetConfig replaces the watch list and makes Loop resubscribe to the new
+// set of pools. The config is rejected if it changes the settings only read
+// at startup.
+func (q *Quant) SetConfig(config Config) error {
+	if err := config.checkStartup(q.startup); err != nil {
+		return err
 	}
-
-	parsedABI, err := abi.JSON(strings.NewReader(string(abiBytes)))
-	if err != nil {
-		return nil, err
+	q.watch.update(config)
+	select {
+	case q.reloadCh <- struct{}{}:
+	default:
 	}
-	return &parsedABI, nil
+	return nil
 }
 
 func (q *Quant) Loop() {
-
-	targetAddresses := map[string]struct{}{
-		"0xDa77c035e4d5A748b4aB6674327Fa446F17098A2": {},
-		"0x000000000008D5760657dD664c7096897E1AE801": {},
-		"0x802b65b5d9016621E66003aeD0b16615093f328b": {},
-		"0x1A0A18AC4BECDDbd6389559687d1A73d8927E416": {},
-		"0x0000000055ECa968153aeFfa4e421f9cd2680f01": {},
-		"0x17Cd8E8D4c64aa7f2a8Db6947b330885DA56b833": {},
-		"0x32564234dF8961ae1d640bE2CBA4aEab54151551": {},
-		"0x9333C74BDd1E118634fE5664ACA7a9710b108Bab": {},
-		"0x36EbED00Ae87c55e1036a8af96dd96380958F4C3": {},
-		"0x773ae23983e1e9720BAfe6E214971Ff762D9758D": {},
-		"0x0000000040Ac064de24cBC1cB9FCbcbC033eE5B6": {},
-		"0x5D80acAf6C75F2DEaDf935f21Bd09C9cc4d61d9B": {},
-		"0x69460570c93f9DE5E2edbC3052bf10125f0Ca22d": {},
-		"0x3e704f2bC43c6408a5Cf0638a977b82bFB20d748": {},
-		"0x00000047bB99ea4D791bb749D970DE71EE0b1A34": {},
-		"0xce16F69375520ab01377ce7B88f5BA8C48F8D666": {},
-		"0x0000000256Cdb6d26cF9FD79229976b0fEaECdF4": {},
-		"0xF552951F9D5f83E2D94D87FcA14545CD33E93d2C": {},
-		"0x013bb8a204499523ddF717e0aBAA14E6dC849060": {},
-		"0xe82c715e37f2f2E190dD2cA86Fb796CAFaF0bEFf": {},
-	}
-
 	swapEventSignature := []byte("Swap(address,address,int256,int256,uint160,uint128,int24,uint128,uint128)")
 	swapEventHash := crypto.Keccak256Hash(swapEventSignature)
 
@@ -141,57 +168,103 @@ func (q *Quant) Loop() {
 	// swap Event
 	logs := make(chan []*types.Log)
 
-	crit := ethereum.FilterQuery{
-		Addresses: []common.Address{common.HexToAddress(poolAddress)},
-		Topics:    [][]common.Hash{{swapEventHash}},
-	}
-
 	eventSystem := filters.NewEventSystem(filters.NewFilterSystem(q.apiBackend, filters.Config{}))
-	eventSystem.SubscribeLogs(crit, logs)
-	eventSystem.SubscribePendingTxs(transaction)
+	pendingSub := eventSystem.SubscribePendingTxs(transaction)
+
+	signer := types.LatestSigner(q.eth.BlockChain().Config())
 	for {
-		select {
-		case txs := <-transaction:
-			for _, tx := range txs {
-				if tx.To() != nil {
-					toAddress := tx.To().Hex()
-					fmt.Printf("To: %s\n", toAddress)
-					if _, found := targetAddresses[toAddress]; found {
-						// 保存到 Elasticsearch
-						timestamp := time.Now().Format(time.RFC3339)
-						txInfo := map[string]interface{}{
-							"hash":      tx.Hash().Hex(),
-							"to":        toAddress,
-							"value":     tx.Value().String(),
-							"gas":       tx.Gas(),
-							"gasPrice":  tx.GasPrice().String(),
-							"nonce":     tx.Nonce(),
-							"timestamp": timestamp,
-						}
-						_, err := q.storage.Put(txInfo, "transactions")
-						if err != nil {
-							log.Printf("Failed to save transaction to Elasticsearch: %v", err)
+		// An empty address list matches every contract, so only subscribe
+		// when at least one pool is watched.
+		var logSub *filters.Subscription
+		if pools := q.watch.current().Pools; len(pools) > 0 {
+			crit := ethereum.FilterQuery{
+				Addresses: pools,
+				Topics:    [][]common.Hash{{swapEventHash}},
+			}
+			sub, err := eventSystem.SubscribeLogs(crit, logs)
+			if err != nil {
+				log.Warn("Failed to subscribe to swap logs", "err", err)
+			}
+			logSub = sub
+		}
+	loop:
+		for {
+			select {
+			case txs := <-transaction:
+				for _, tx := range txs {
+					from, err := types.Sender(signer, tx)
+					if err != nil {
+						continue
+					}
+					if !q.watch.matches(from, tx.To()) {
+						continue
+					}
+					swap, err := q.pendingSwap(tx, from)
+					if err != nil {
+						if !errors.Is(err, errNotSwap) {
+							log.Debug("Failed to decode transaction", "hash", tx.Hash(), "err", err)
 						}
-						fmt.Printf("Hash: %s\n", tx.Hash().Hex())
-						fmt.Printf("Value: %s\n", tx.Value().String())
-						fmt.Printf("Gas: %d\n", tx.Gas())
-						fmt.Printf("Gas Price: %s\n", tx.GasPrice().String())
-						fmt.Printf("Nonce: %d\n", tx.Nonce())
-						fmt.Println("===================================")
-						q.strategy.Try(tx)
+						continue
+					}
+					// 保存到 storage
+					if _, err := q.storage.Put(swap, "transactions"); err != nil {
+						log.Warn("Failed to save transaction", "hash", tx.Hash(), "err", err)
+					}
+					for _, intent := range swap.Intents {
+						log.Trace("Pending swap intent", "hash", tx.Hash(), "method", intent.Method, "in", intent.TokenIn(), "out", intent.TokenOut())
+					}
+					if !q.trading {
+						continue
+					}
+					if _, err := q.strategy.Try(swap); err != nil {
+						log.Debug("Strategy failed", "hash", tx.Hash(), "err", err)
 					}
 				}
+			case logs := <-logs:
+				for _, log := range logs {
+					// Logs of a removed pool may still be in flight.
+					if !q.watch.isPool(log.Address) {
+						continue
+					}
+					q.handleSwapEvent(*log)
+				}
+			case <-q.reloadCh:
+				break loop
+			case <-q.quit:
+				if logSub != nil {
+					logSub.Unsubscribe()
+				}
+				pendingSub.Unsubscribe()
+				return
 			}
-		case logs := <-logs:
-			for _, log := range logs {
-				q.handleSwapEvent(*log)
-			}
+		}
+		if logSub != nil {
+			logSub.Unsubscribe()
 		}
 	}
 }
 
+// pendingSwap decodes the swaps made by a watched pending transaction.
+func (q *Quant) pendingSwap(tx *types.Transaction, from common.Address) (*PendingSwap, error) {
+	intents, err := q.decoder.Decode(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &PendingSwap{
+		Tx:        tx,
+		Hash:      tx.Hash(),
+		From:      from,
+		To:        *tx.To(),
+		Nonce:     tx.Nonce(),
+		Gas:       tx.Gas(),
+		GasPrice:  tx.GasPrice(),
+		Intents:   intents,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
 func (q *Quant) handleSwapEvent(vLog types.Log) error {
-	fmt.Println("Swap event detected:################")
+	log.Trace("Swap event detected", "pool", vLog.Address, "tx", vLog.TxHash)
 	// Parse event data
 	event := struct {
 		Sender             common.Address
@@ -205,35 +278,39 @@ func (q *Quant) handleSwapEvent(vLog types.Log) error {
 		ProtocolFeesToken1 *big.Int
 	}{}
 
-	err := q.Contract.UnpackLog(&event, "Swap", vLog)
+	err := q.unpackSwap(&event, vLog)
+	if err != nil {
+		log.Warn("Failed to unpack swap log", "tx", vLog.TxHash, "err", err)
+		return err
+	}
+
+	// Resolve the pool tokens so amounts are normalised with their decimals
+	pair, err := q.tokens.Pair(context.Background(), vLog.Address)
 	if err != nil {
-		log.Printf("Failed to unpack log: %v", err)
+		log.Warn("Failed to resolve pool tokens", "pool", vLog.Address, "err", err)
 		return err
 	}
 
 	// Convert amounts to readable format
-	readableAmount0 := new(big.Float).Quo(new(big.Float).SetInt(event.Amount0), big.NewFloat(math.Pow10(18))) // Assuming USDT has 6 decimals
-	readableAmount1 := new(big.Float).Quo(new(big.Float).SetInt(event.Amount1), big.NewFloat(math.Pow10(18))) // Assuming BNB has 18 decimals
+	readableAmount0 := pair.Token0.Normalise(event.Amount0)
+	readableAmount1 := pair.Token1.Normalise(event.Amount1)
 
-	protocolFeeToken0 := new(big.Float).Quo(new(big.Float).SetInt(event.ProtocolFeesToken0), big.NewFloat(math.Pow10(18)))
-	protocolFeeToken1 := new(big.Float).Quo(new(big.Float).SetInt(event.ProtocolFeesToken1), big.NewFloat(math.Pow10(18)))
+	protocolFeeToken0 := pair.Token0.Normalise(event.ProtocolFeesToken0)
+	protocolFeeToken1 := pair.Token1.Normalise(event.ProtocolFeesToken1)
 
 	// Calculate fee
 	var fee float64
-	var accuracy big.Accuracy
-	if event.Amount0.Cmp(big.NewInt(0)) > 0 {
-		fee, accuracy = new(big.Float).Quo(protocolFeeToken0, readableAmount0).Float64()
+	if event.Amount0.Sign() > 0 {
+		fee = protocolFeeToken0 / readableAmount0
 	} else {
-		fee, accuracy = new(big.Float).Quo(protocolFeeToken1, readableAmount1).Float64()
+		fee = protocolFeeToken1 / readableAmount1
 	}
-	fmt.Println("fee:", fee, accuracy)
+	log.Trace("Swap event fee", "pool", vLog.Address, "tx", vLog.TxHash, "fee", fee)
 
 	// Get transaction receipt
 	receipt, err := q.transactionApi.GetTransactionReceipt(context.Background(), vLog.TxHash)
-	txHash := vLog.TxHash.Hex()
-	fmt.Println(txHash)
 	if err != nil {
-		log.Printf("Failed to get transaction receipt: %v", err)
+		log.Warn("Failed to get transaction receipt", "tx", vLog.TxHash, "err", err)
 		return err
 	}
 
@@ -253,13 +330,11 @@ func (q *Quant) handleSwapEvent(vLog types.Log) error {
 	// Get block timestamp
 	block, err := q.blockChainApi.GetBlockByHash(context.Background(), receipt["blockHash"].(common.Hash), false)
 	if err != nil {
-		log.Printf("Failed to get block: %v", err)
+		log.Warn("Failed to get block", "tx", vLog.TxHash, "err", err)
 		return err
 	}
 	timestamp := time.Unix(int64(block["timestamp"].(hexutil.Uint64)), 0).Format(time.RFC3339)
 
-	//timestamp := block["timestamp"].(hexutil.Uint64)
-
 	var toAddress string
 	if receipt["to"].(*common.Address) != nil {
 		toAddress = receipt["to"].(*common.Address).Hex()
@@ -268,22 +343,24 @@ func (q *Quant) handleSwapEvent(vLog types.Log) error {
 	}
 
 	swapEvent := SwapEvent{
-		Pair:             poolAddress,
-		Token0:           "USDT",
-		Token1:           "BNB",
+		Pair:             vLog.Address.Hex(),
+		Token0:           pair.Token0.Symbol,
+		Token0Address:    pair.Token0.Address.Hex(),
+		Token1:           pair.Token1.Symbol,
+		Token1Address:    pair.Token1.Address.Hex(),
 		TransactionInfo:  TBase{TransactionHash: vLog.TxHash.Hex(), From: receipt["from"].(common.Address).String(), To: toAddress},
 		Sender:           event.Sender.Hex(),
 		Recipient:        event.Recipient.Hex(),
 		Tick:             event.Tick.Int64(),
-		Amount0:          func() float64 { f, _ := readableAmount0.Float64(); return f }(),
+		Amount0:          readableAmount0,
 		Amount0Origin:    event.Amount0.Int64(),
-		Amount1:          func() float64 { f, _ := readableAmount1.Float64(); return f }(),
+		Amount1:          readableAmount1,
 		Amount1Origin:    event.Amount1.Int64(),
 		SqrtPriceX96:     event.SqrtPriceX96.Int64(),
 		Liquidity:        event.Liquidity.Int64(),
-		FeeAmount0:       func() float64 { f, _ := protocolFeeToken0.Float64(); return f }(),
+		FeeAmount0:       protocolFeeToken0,
 		FeeAmount0Origin: event.ProtocolFeesToken0.Int64(),
-		FeeAmount1:       func() float64 { f, _ := protocolFeeToken1.Float64(); return f }(),
+		FeeAmount1:       protocolFeeToken1,
 		FeeAmount1Origin: event.ProtocolFeesToken1.Int64(),
 		Fee:              fee,
 		GasFee:           gasFee.Int64(),
@@ -291,64 +368,33 @@ func (q *Quant) handleSwapEvent(vLog types.Log) error {
 		GasPrice:         effectiveGasPrice.ToInt().Int64(),
 		Timestamp:        timestamp,
 	}
-	// fmt.Printf(`Swap event details:
-	// Pair: %s
-	// Token0: %s
-	// Token1: %s
-	// Transaction Hash: %s
-	// Transaction from: %s
-	// Transaction to: %s
-	// Sender: %s
-	// Recipient: %s
-	// Tick: %d
-	// Amount0: %f USDT
-	// Amount0 origin: %d USDT
-	// Amount1: %f BNB
-	// Amount1 origin: %d BNB
-	// SqrtPriceX96: %d
-	// Liquidity: %d
-	// Fee Amount0: %f USDT
-	// Fee Amount0 origin: %d USDT
-	// Fee Amount1: %f BNB
-	// Fee Amount1 origin: %d BNB
-	// Fee: %f
-	// GasFee: %d Gwei
-	// GasUsed: %d
-	// GasPrice: %d Gwei
-	// Timestamp: %s
-	// `,
-	// 	swapEvent.Pair,
-	// 	swapEvent.Token0,
-	// 	swapEvent.Token1,
-	// 	swapEvent.TransactionInfo.TransactionHash,
-	// 	swapEvent.TransactionInfo.From,
-	// 	swapEvent.TransactionInfo.To,
-	// 	swapEvent.Sender,
-	// 	swapEvent.Recipient,
-	// 	swapEvent.Tick,
-	// 	swapEvent.Amount0,
-	// 	swapEvent.Amount0Origin,
-	// 	swapEvent.Amount1,
-	// 	swapEvent.Amount1Origin,
-	// 	swapEvent.SqrtPriceX96,
-	// 	swapEvent.Liquidity,
-	// 	swapEvent.FeeAmount0,
-	// 	swapEvent.FeeAmount0Origin,
-	// 	swapEvent.FeeAmount1,
-	// 	swapEvent.FeeAmount1Origin,
-	// 	swapEvent.Fee,
-	// 	swapEvent.GasFee,
-	// 	swapEvent.GasUsed,
-	// 	swapEvent.GasPrice,
-	// 	swapEvent.Timestamp,
-	// )
-
-	// Save to Elasticsearch
+
+	// Save to storage
 	err = q.storage.BulkPut([]interface{}{swapEvent}, "swap_events")
 	if err != nil {
-		log.Printf("Failed to save swap event to Elasticsearch: %v", err)
+		log.Warn("Failed to save swap event", "tx", vLog.TxHash, "err", err)
 		return err
 	}
 
 	return nil
 }
+
+// unpackSwap decodes a Swap log emitted by any of the watched pools.
+func (q *Quant) unpackSwap(out interface{}, vLog types.Log) error {
+	event := q.abi.Events["Swap"]
+	if len(vLog.Topics) == 0 || vLog.Topics[0] != event.ID {
+		return fmt.Errorf("unexpected event signature")
+	}
+	if len(vLog.Data) > 0 {
+		if err := q.abi.UnpackIntoInterface(out, "Swap", vLog.Data); err != nil {
+			return err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopics(out, indexed, vLog.Topics[1:])
+}
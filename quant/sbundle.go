@@ -6,8 +6,10 @@ import (
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -15,6 +17,15 @@ import (
 	"github.com/holiman/uint256"
 )
 
+var (
+	ErrBundleNotInRange  = errors.New("bundle not valid for current block")
+	ErrInvalidBundleBody = errors.New("invalid bundle body")
+	ErrTxFailed          = errors.New("tx failed")
+	ErrInvalidRefund     = errors.New("invalid refund")
+	ErrRefundTooSmall    = errors.New("refund does not cover the payout gas")
+	ErrNegativeProfit    = errors.New("negative profit")
+)
+
 // SBundle is a bundle of transactions that must be executed atomically
 // unlike ordinary bundle it also supports refunds
 type SBundle struct {
@@ -57,10 +68,17 @@ type SimBundleResult struct {
 	GasUsed         uint64
 	MevGasPrice     *uint256.Int
 	BodyLogs        []SimBundleBodyLogs
+	Payouts         []BundlePayout
 	Revert          []byte
 	ExecError       string
 }
 
+// BundlePayout is a refund paid out of the collected fees after a bundle ran.
+type BundlePayout struct {
+	Address common.Address `json:"address"`
+	Value   *uint256.Int   `json:"value"`
+}
+
 type SimBundleBodyLogs struct {
 	TxLogs     []*types.Log        `json:"txLogs,omitempty"`
 	BundleLogs []SimBundleBodyLogs `json:"bundleLogs,omitempty"`
@@ -92,28 +110,39 @@ func MakeBundle(txs []*types.Transaction, bloclNumber uint64, MaxBloclNumber uin
 
 // SimBundle simulates a bundle and returns the result
 // Arguments are the same as in ApplyTransaction with the same change semantics:
-// - statedb is modified
+// - statedb is modified (also on failure, pass a copy to be able to discard it)
 // - header is not modified
 // - gp is modified
 // - usedGas is modified (by txs that were applied)
-// Payout transactions will not be applied to the state.
-// GasUsed in return will include the gas that might be used by the payout txs.
+// The profit is the balance change of the coinbase and, on Parlia, of the
+// system address collecting the fees. Refunds are applied to the state as plain
+// transfers out of that profit.
+// GasUsed in return will include the gas that would be used by the payout txs.
 func SimBundle(config *params.ChainConfig, bc *core.BlockChain, author *common.Address, gp *core.GasPool, statedb *state.StateDB, header *types.Header, b *SBundle, txIdx int, usedGas *uint64, cfg vm.Config, logs bool) (SimBundleResult, error) {
 	res := NewSimBundleResult()
 
 	currBlock := header.Number.Uint64()
 	if currBlock < b.Inclusion.BlockNumber || currBlock > b.Inclusion.MaxBlockNumber {
-		return res, errors.New("bundle not valid for current block")
+		return res, ErrBundleNotInRange
+	}
+	// Body elements receiving a refund don't contribute to the refundable value
+	refundIdx := make(map[int]bool, len(b.Validity.Refund))
+	for _, el := range b.Validity.Refund {
+		if el.BodyIdx < 0 || el.BodyIdx >= len(b.Body) {
+			return res, ErrInvalidRefund
+		}
+		refundIdx[el.BodyIdx] = true
 	}
 
 	var (
+		recipients     = feeRecipients(config, header)
 		coinbaseDelta  = new(uint256.Int)
 		coinbaseBefore *uint256.Int
 	)
-	for _, el := range b.Body {
+	for i, el := range b.Body {
 		coinbaseDelta.Set(common.U2560)
 		// Coinbase 是builder的账户
-		coinbaseBefore = statedb.GetBalance(header.Coinbase)
+		coinbaseBefore = feeBalance(statedb, recipients)
 
 		if el.Tx != nil {
 			statedb.SetTxContext(el.Tx.Hash(), txIdx)
@@ -127,7 +156,7 @@ func SimBundle(config *params.ChainConfig, bc *core.BlockChain, author *common.A
 				res.ExecError = result.Err.Error()
 			}
 			if receipt.Status != types.ReceiptStatusSuccessful && !el.CanRevert {
-				return res, errors.New("tx failed")
+				return res, ErrTxFailed
 			}
 			res.GasUsed += receipt.GasUsed
 			if logs {
@@ -138,6 +167,8 @@ func SimBundle(config *params.ChainConfig, bc *core.BlockChain, author *common.A
 			if err != nil {
 				return res, err
 			}
+			txIdx += el.Bundle.txCount()
+
 			// basically return first exec error if exists, helpful for single-tx sbundles
 			if len(res.Revert) == 0 {
 				res.Revert = innerRes.Revert
@@ -150,20 +181,180 @@ func SimBundle(config *params.ChainConfig, bc *core.BlockChain, author *common.A
 				res.BodyLogs = append(res.BodyLogs, SimBundleBodyLogs{BundleLogs: innerRes.BodyLogs})
 			}
 		} else {
-			return res, errors.New("invalid bundle body")
+			return res, ErrInvalidBundleBody
 		}
 
-		coinbaseAfter := statedb.GetBalance(header.Coinbase)
+		coinbaseAfter := feeBalance(statedb, recipients)
 		coinbaseDelta.Set(coinbaseAfter)
 		coinbaseDelta.Sub(coinbaseDelta, coinbaseBefore)
 
 		res.TotalProfit.Add(res.TotalProfit, coinbaseDelta)
+		if !refundIdx[i] {
+			res.RefundableValue.Add(res.RefundableValue, coinbaseDelta)
+		}
+	}
+
+	// Pay out the refunds from the collected fees
+	if len(b.Validity.Refund) > 0 {
+		payouts, err := bundleRefunds(config, header, b, res.RefundableValue)
+		if err != nil {
+			return res, err
+		}
+		for _, payout := range payouts {
+			if feeBalance(statedb, recipients).Cmp(payout.Value) < 0 {
+				return res, ErrNegativeProfit
+			}
+			payFees(statedb, recipients, payout.Value)
+			statedb.AddBalance(payout.Address, payout.Value, tracing.BalanceChangeTransfer)
+
+			res.GasUsed += params.TxGas
+			res.Payouts = append(res.Payouts, payout)
+
+			cost := new(uint256.Int).Add(payout.Value, payoutTxFee(header))
+			if res.TotalProfit.Cmp(cost) < 0 {
+				return res, ErrNegativeProfit
+			}
+			res.TotalProfit.Sub(res.TotalProfit, cost)
+		}
 	}
 
-	res.MevGasPrice.Div(res.TotalProfit, new(uint256.Int).SetUint64(res.GasUsed))
+	if res.GasUsed > 0 {
+		res.MevGasPrice.Div(res.TotalProfit, new(uint256.Int).SetUint64(res.GasUsed))
+	}
 	return res, nil
 }
 
+// feeRecipients returns the accounts collecting the fees and payments of the
+// block: the coinbase and, on Parlia, the system address, where the fees stay
+// until the block is finalised.
+func feeRecipients(config *params.ChainConfig, header *types.Header) []common.Address {
+	if config.Parlia != nil && header.Coinbase != consensus.SystemAddress {
+		return []common.Address{header.Coinbase, consensus.SystemAddress}
+	}
+	return []common.Address{header.Coinbase}
+}
+
+// feeBalance returns the total balance of the fee recipients.
+func feeBalance(statedb *state.StateDB, recipients []common.Address) *uint256.Int {
+	balance := new(uint256.Int)
+	for _, addr := range recipients {
+		balance.Add(balance, statedb.GetBalance(addr))
+	}
+	return balance
+}
+
+// payFees deducts value from the fee recipients, the collected fees first and
+// the coinbase last. The caller must check that their balance covers it.
+func payFees(statedb *state.StateDB, recipients []common.Address, value *uint256.Int) {
+	remaining := value.Clone()
+	for i := len(recipients) - 1; i >= 0 && !remaining.IsZero(); i-- {
+		amount := statedb.GetBalance(recipients[i]).Clone()
+		if amount.Cmp(remaining) > 0 {
+			amount.Set(remaining)
+		}
+		statedb.SubBalance(recipients[i], amount, tracing.BalanceChangeTransfer)
+		remaining.Sub(remaining, amount)
+	}
+}
+
+// bundleRefunds computes the refund transfers of a bundle. Each refund
+// constraint grants a percentage of the refundable value to the sender of the
+// referenced body transaction, or, if a refund config is present, splits it
+// between the configured addresses. The cost of the payout transaction is
+// deducted from every transfer.
+func bundleRefunds(config *params.ChainConfig, header *types.Header, b *SBundle, refundable *uint256.Int) ([]BundlePayout, error) {
+	var total int
+	for _, el := range b.Validity.Refund {
+		if el.Percent < 0 || el.Percent > 100 {
+			return nil, ErrInvalidRefund
+		}
+		total += el.Percent
+	}
+	if total > 100 {
+		return nil, ErrInvalidRefund
+	}
+	if len(b.Validity.RefundConfig) > 0 {
+		var split int
+		for _, el := range b.Validity.RefundConfig {
+			if el.Percent < 0 {
+				return nil, ErrInvalidRefund
+			}
+			split += el.Percent
+		}
+		if split != 100 {
+			return nil, ErrInvalidRefund
+		}
+	}
+	var (
+		signer  = types.MakeSigner(config, header.Number, header.Time)
+		fee     = payoutTxFee(header)
+		amounts = make(map[common.Address]*uint256.Int)
+		order   []common.Address
+	)
+	credit := func(addr common.Address, value *uint256.Int) {
+		if _, ok := amounts[addr]; !ok {
+			amounts[addr] = new(uint256.Int)
+			order = append(order, addr)
+		}
+		amounts[addr].Add(amounts[addr], value)
+	}
+	for _, el := range b.Validity.Refund {
+		refund := new(uint256.Int).Mul(refundable, uint256.NewInt(uint64(el.Percent)))
+		refund.Div(refund, uint256.NewInt(100))
+
+		if len(b.Validity.RefundConfig) > 0 {
+			for _, rc := range b.Validity.RefundConfig {
+				share := new(uint256.Int).Mul(refund, uint256.NewInt(uint64(rc.Percent)))
+				credit(rc.Address, share.Div(share, uint256.NewInt(100)))
+			}
+			continue
+		}
+		tx := b.Body[el.BodyIdx].Tx
+		if tx == nil {
+			return nil, ErrInvalidRefund
+		}
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		credit(sender, refund)
+	}
+	payouts := make([]BundlePayout, 0, len(order))
+	for _, addr := range order {
+		if amounts[addr].Cmp(fee) <= 0 {
+			return nil, ErrRefundTooSmall
+		}
+		payouts = append(payouts, BundlePayout{
+			Address: addr,
+			Value:   new(uint256.Int).Sub(amounts[addr], fee),
+		})
+	}
+	return payouts, nil
+}
+
+// payoutTxFee returns the cost of a plain transfer paying out a refund.
+func payoutTxFee(header *types.Header) *uint256.Int {
+	if header.BaseFee == nil {
+		return new(uint256.Int)
+	}
+	fee, _ := uint256.FromBig(header.BaseFee)
+	return fee.Mul(fee, uint256.NewInt(params.TxGas))
+}
+
+// txCount returns the number of transactions in the bundle, including the
+// nested ones.
+func (b *SBundle) txCount() int {
+	var count int
+	for _, el := range b.Body {
+		if el.Tx != nil {
+			count++
+		} else if el.Bundle != nil {
+			count += el.Bundle.txCount()
+		}
+	}
+	return count
+}
+
 func ApplyTransactionWithResult(config *params.ChainConfig, bc core.ChainContext, author *common.Address, gp *core.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, *core.ExecutionResult, error) {
 	msg, err := core.TransactionToMessage(tx, types.MakeSigner(config, header.Number, header.Time), header.BaseFee)
 	if err != nil {
@@ -0,0 +1,174 @@
+package quant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxSBundleDepth is the maximum nesting level of bundles accepted over RPC.
+const maxSBundleDepth = 5
+
+var (
+	errSBundleTooDeep  = errors.New("bundle nesting too deep")
+	errSBundleNoBody   = errors.New("bundle has no body")
+	errSBundleBodyType = errors.New("bundle body must contain exactly one of tx or bundle")
+)
+
+// SBundleArgs is the JSON form of an SBundle, following the layout of the
+// MEV-Share mev_sendBundle request.
+type SBundleArgs struct {
+	Inclusion SBundleInclusionArgs `json:"inclusion"`
+	Body      []SBundleBodyArgs    `json:"body"`
+	Validity  *BundleValidity      `json:"validity,omitempty"`
+}
+
+type SBundleInclusionArgs struct {
+	BlockNumber    hexutil.Uint64  `json:"block"`
+	MaxBlockNumber *hexutil.Uint64 `json:"maxBlock,omitempty"`
+}
+
+type SBundleBodyArgs struct {
+	Tx        *hexutil.Bytes `json:"tx,omitempty"`
+	Bundle    *SBundleArgs   `json:"bundle,omitempty"`
+	CanRevert bool           `json:"canRevert,omitempty"`
+}
+
+// ToSBundle decodes the raw transactions and nested bundles.
+func (args *SBundleArgs) ToSBundle() (*SBundle, error) {
+	return args.toSBundle(0)
+}
+
+func (args *SBundleArgs) toSBundle(depth int) (*SBundle, error) {
+	if depth >= maxSBundleDepth {
+		return nil, errSBundleTooDeep
+	}
+	if len(args.Body) == 0 {
+		return nil, errSBundleNoBody
+	}
+	bundle := &SBundle{
+		Inclusion: BundleInclusion{
+			BlockNumber:    uint64(args.Inclusion.BlockNumber),
+			MaxBlockNumber: uint64(args.Inclusion.BlockNumber),
+		},
+	}
+	if args.Inclusion.MaxBlockNumber != nil {
+		bundle.Inclusion.MaxBlockNumber = uint64(*args.Inclusion.MaxBlockNumber)
+	}
+	if args.Validity != nil {
+		bundle.Validity = *args.Validity
+	}
+	for _, el := range args.Body {
+		body := BundleBody{CanRevert: el.CanRevert}
+		switch {
+		case el.Tx != nil && el.Bundle == nil:
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(*el.Tx); err != nil {
+				return nil, err
+			}
+			body.Tx = tx
+		case el.Bundle != nil && el.Tx == nil:
+			inner, err := el.Bundle.toSBundle(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			body.Bundle = inner
+		default:
+			return nil, errSBundleBodyType
+		}
+		bundle.Body = append(bundle.Body, body)
+	}
+	return bundle, nil
+}
+
+// SimSBundleOverrides customises the block a bundle is simulated in.
+type SimSBundleOverrides struct {
+	ParentBlock *rpc.BlockNumberOrHash `json:"parentBlock,omitempty"`
+	Coinbase    *common.Address        `json:"coinbase,omitempty"`
+	Timestamp   *hexutil.Uint64        `json:"timestamp,omitempty"`
+}
+
+// SimSBundleResponse is the outcome of mev_simulateSBundle. A bundle that
+// fails to execute is reported through Success and Error.
+type SimSBundleResponse struct {
+	Success         bool                `json:"success"`
+	Error           string              `json:"error,omitempty"`
+	StateBlock      hexutil.Uint64      `json:"stateBlock"`
+	MevGasPrice     *hexutil.Big        `json:"mevGasPrice"`
+	Profit          *hexutil.Big        `json:"profit"`
+	RefundableValue *hexutil.Big        `json:"refundableValue"`
+	GasUsed         hexutil.Uint64      `json:"gasUsed"`
+	Payouts         []BundlePayout      `json:"payouts,omitempty"`
+	Revert          hexutil.Bytes       `json:"revert,omitempty"`
+	ExecError       string              `json:"execError,omitempty"`
+	BodyLogs        []SimBundleBodyLogs `json:"logs,omitempty"`
+}
+
+// SBundleAPI offers bundle simulation under the "mev" RPC namespace.
+type SBundleAPI struct {
+	q *Quant
+}
+
+// NewSBundleAPI creates a new bundle simulation RPC service.
+func NewSBundleAPI(q *Quant) *SBundleAPI {
+	return &SBundleAPI{q: q}
+}
+
+// SimulateSBundle executes a bundle, including its nested bundles and refunds,
+// on top of the given parent block (latest by default).
+func (api *SBundleAPI) SimulateSBundle(ctx context.Context, args SBundleArgs, overrides *SimSBundleOverrides) (*SimSBundleResponse, error) {
+	bundle, err := args.ToSBundle()
+	if err != nil {
+		return nil, err
+	}
+	parentNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if overrides != nil && overrides.ParentBlock != nil {
+		parentNrOrHash = *overrides.ParentBlock
+	}
+	statedb, parent, err := api.q.apiBackend.StateAndHeaderByNumberOrHash(ctx, parentNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	header, err := api.q.strategy.simulator.NextHeader(parent)
+	if err != nil {
+		return nil, err
+	}
+	if overrides != nil {
+		if overrides.Coinbase != nil {
+			header.Coinbase = *overrides.Coinbase
+		}
+		if overrides.Timestamp != nil {
+			header.Time = uint64(*overrides.Timestamp)
+		}
+	}
+	var (
+		chain   = api.q.eth.BlockChain()
+		gp      = new(core.GasPool).AddGas(header.GasLimit)
+		usedGas uint64
+	)
+	result, err := SimBundle(chain.Config(), chain, &header.Coinbase, gp, statedb, header, bundle, 0, &usedGas, vm.Config{}, true)
+
+	resp := &SimSBundleResponse{
+		StateBlock:      hexutil.Uint64(parent.Number.Uint64()),
+		MevGasPrice:     (*hexutil.Big)(result.MevGasPrice.ToBig()),
+		Profit:          (*hexutil.Big)(result.TotalProfit.ToBig()),
+		RefundableValue: (*hexutil.Big)(result.RefundableValue.ToBig()),
+		GasUsed:         hexutil.Uint64(result.GasUsed),
+		Payouts:         result.Payouts,
+		Revert:          result.Revert,
+		ExecError:       result.ExecError,
+		BodyLogs:        result.BodyLogs,
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	resp.Success = true
+	return resp, nil
+}
@@ -0,0 +1,262 @@
+package quant
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	userKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	userAddr   = crypto.PubkeyToAddress(userKey.PublicKey)
+)
+
+type sbundleTester struct {
+	t      *testing.T
+	chain  *core.BlockChain
+	header *types.Header
+}
+
+func newSBundleTester(t *testing.T) *sbundleTester {
+	chain := newTestChain(t, types.GenesisAlloc{
+		userAddr:    {Balance: testFunds},
+		testReverts: {Code: revertingCode, Balance: common.Big0},
+	})
+	header, err := NewSimulator(chain, nil).NextHeader(chain.CurrentBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &sbundleTester{t: t, chain: chain, header: header}
+}
+
+// tx creates a transfer paying exactly the base fee, so that only explicit
+// value transfers to the coinbase count as profit.
+func (st *sbundleTester) tx(key []byte, nonce uint64, to common.Address, value *big.Int) *types.Transaction {
+	prv, _ := crypto.ToECDSA(key)
+	tx, err := types.SignTx(types.NewTransaction(nonce, to, value, 100_000, st.header.BaseFee, nil), types.LatestSigner(st.chain.Config()), prv)
+	if err != nil {
+		st.t.Fatal(err)
+	}
+	return tx
+}
+
+func (st *sbundleTester) simulate(b *SBundle) (SimBundleResult, error) {
+	statedb, err := st.chain.State()
+	if err != nil {
+		st.t.Fatal(err)
+	}
+	var (
+		gp      = new(core.GasPool).AddGas(st.header.GasLimit)
+		usedGas uint64
+	)
+	return SimBundle(st.chain.Config(), st.chain, &st.header.Coinbase, gp, statedb, st.header, b, 0, &usedGas, vm.Config{}, true)
+}
+
+func TestSimBundleRefund(t *testing.T) {
+	st := newSBundleTester(t)
+	var (
+		user      = crypto.FromECDSA(userKey)
+		searcher  = crypto.FromECDSA(testKey)
+		recipient = common.HexToAddress("0x000000000000000000000000000000000000beef")
+		bribe     = big.NewInt(params.Ether)
+		number    = st.header.Number.Uint64()
+	)
+	// The user tx is backrun by a searcher paying the coinbase, half of which
+	// is refunded to the user.
+	bundle := &SBundle{
+		Inclusion: BundleInclusion{BlockNumber: number, MaxBlockNumber: number + 1},
+		Body: []BundleBody{
+			{Tx: st.tx(user, 0, recipient, common.Big1)},
+			{Bundle: MakeBundle([]*types.Transaction{st.tx(searcher, 0, st.header.Coinbase, bribe)}, number, number)},
+		},
+		Validity: BundleValidity{Refund: []RefundConstraint{{BodyIdx: 0, Percent: 50}}},
+	}
+	res, err := st.simulate(bundle)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	want := uint256.MustFromBig(bribe)
+	if res.RefundableValue.Cmp(want) != 0 {
+		t.Fatalf("refundable value mismatch: have %v, want %v", res.RefundableValue, want)
+	}
+	if len(res.Payouts) != 1 || res.Payouts[0].Address != userAddr {
+		t.Fatalf("payout mismatch: %+v", res.Payouts)
+	}
+	half := new(uint256.Int).Div(want, uint256.NewInt(2))
+	fee := payoutTxFee(st.header)
+	if have, want := res.Payouts[0].Value, new(uint256.Int).Sub(half, fee); have.Cmp(want) != 0 {
+		t.Fatalf("payout value mismatch: have %v, want %v", have, want)
+	}
+	if res.TotalProfit.Cmp(half) != 0 {
+		t.Fatalf("profit mismatch: have %v, want %v", res.TotalProfit, half)
+	}
+	if res.GasUsed != 3*params.TxGas {
+		t.Fatalf("gas used mismatch: have %d, want %d", res.GasUsed, 3*params.TxGas)
+	}
+	wantPrice := new(uint256.Int).Div(half, uint256.NewInt(3*params.TxGas))
+	if res.MevGasPrice.Cmp(wantPrice) != 0 {
+		t.Fatalf("mev gas price mismatch: have %v, want %v", res.MevGasPrice, wantPrice)
+	}
+	if len(res.BodyLogs) != 2 || len(res.BodyLogs[1].BundleLogs) != 1 {
+		t.Fatalf("body logs mismatch: %+v", res.BodyLogs)
+	}
+}
+
+// TestSimBundleParlia checks that the fees collected in the system address count
+// as profit on Parlia, where the coinbase receives nothing until the block is
+// finalised.
+func TestSimBundleParlia(t *testing.T) {
+	st := newSBundleTester(t)
+	var (
+		user     = crypto.FromECDSA(userKey)
+		searcher = crypto.FromECDSA(testKey)
+		tip      = big.NewInt(100 * params.GWei)
+		number   = st.header.Number.Uint64()
+		config   = *st.chain.Config()
+	)
+	config.Parlia = &params.ParliaConfig{}
+
+	// The searcher pays the validator through the priority fee only
+	prv, _ := crypto.ToECDSA(searcher)
+	backrun, err := types.SignTx(types.NewTransaction(0, common.Address{}, common.Big0, 100_000, new(big.Int).Add(st.header.BaseFee, tip), nil), types.LatestSigner(&config), prv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := &SBundle{
+		Inclusion: BundleInclusion{BlockNumber: number, MaxBlockNumber: number},
+		Body: []BundleBody{
+			{Tx: st.tx(user, 0, common.Address{}, common.Big0)},
+			{Tx: backrun},
+		},
+		Validity: BundleValidity{Refund: []RefundConstraint{{BodyIdx: 0, Percent: 50}}},
+	}
+	statedb, err := st.chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		gp       = new(core.GasPool).AddGas(st.header.GasLimit)
+		usedGas  uint64
+		coinbase = statedb.GetBalance(st.header.Coinbase).Clone()
+	)
+	res, err := SimBundle(&config, st.chain, &st.header.Coinbase, gp, statedb, st.header, bundle, 0, &usedGas, vm.Config{}, false)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	fees := uint256.MustFromBig(new(big.Int).Mul(tip, big.NewInt(int64(params.TxGas))))
+	if res.RefundableValue.Cmp(fees) != 0 {
+		t.Fatalf("refundable value mismatch: have %v, want %v", res.RefundableValue, fees)
+	}
+	half := new(uint256.Int).Div(fees, uint256.NewInt(2))
+	if res.TotalProfit.Cmp(half) != 0 {
+		t.Fatalf("profit mismatch: have %v, want %v", res.TotalProfit, half)
+	}
+	if want := new(uint256.Int).Div(half, uint256.NewInt(3*params.TxGas)); res.MevGasPrice.Cmp(want) != 0 {
+		t.Fatalf("mev gas price mismatch: have %v, want %v", res.MevGasPrice, want)
+	}
+	// The refund is paid out of the collected fees
+	if len(res.Payouts) != 1 || res.Payouts[0].Address != userAddr {
+		t.Fatalf("payout mismatch: %+v", res.Payouts)
+	}
+	if have := statedb.GetBalance(st.header.Coinbase); have.Cmp(coinbase) != 0 {
+		t.Fatalf("coinbase balance changed: have %v, want %v", have, coinbase)
+	}
+	if have, want := statedb.GetBalance(consensus.SystemAddress), new(uint256.Int).Sub(fees, res.Payouts[0].Value); have.Cmp(want) != 0 {
+		t.Fatalf("system address balance mismatch: have %v, want %v", have, want)
+	}
+}
+
+func TestSimBundleErrors(t *testing.T) {
+	st := newSBundleTester(t)
+	var (
+		searcher = crypto.FromECDSA(testKey)
+		number   = st.header.Number.Uint64()
+	)
+	reverting := func(canRevert bool) *SBundle {
+		return &SBundle{
+			Inclusion: BundleInclusion{BlockNumber: number, MaxBlockNumber: number},
+			Body:      []BundleBody{{Tx: st.tx(searcher, 0, testReverts, common.Big0), CanRevert: canRevert}},
+		}
+	}
+	if _, err := st.simulate(reverting(false)); !errors.Is(err, ErrTxFailed) {
+		t.Fatalf("reverting tx: have %v, want %v", err, ErrTxFailed)
+	}
+	res, err := st.simulate(reverting(true))
+	if err != nil {
+		t.Fatalf("revertible tx failed the bundle: %v", err)
+	}
+	if res.ExecError == "" || len(res.Revert) == 0 {
+		t.Fatalf("revert not reported: %+v", res)
+	}
+
+	late := MakeBundle([]*types.Transaction{st.tx(searcher, 0, common.Address{}, common.Big0)}, number+1, number+2)
+	if _, err := st.simulate(late); !errors.Is(err, ErrBundleNotInRange) {
+		t.Fatalf("future bundle: have %v, want %v", err, ErrBundleNotInRange)
+	}
+
+	// Refunding more than the bundle made
+	greedy := MakeBundle([]*types.Transaction{st.tx(searcher, 0, common.Address{}, common.Big0)}, number, number)
+	greedy.Validity.Refund = []RefundConstraint{{BodyIdx: 0, Percent: 101}}
+	if _, err := st.simulate(greedy); !errors.Is(err, ErrInvalidRefund) {
+		t.Fatalf("invalid refund: have %v, want %v", err, ErrInvalidRefund)
+	}
+	greedy.Validity.Refund = []RefundConstraint{{BodyIdx: 1, Percent: 10}}
+	if _, err := st.simulate(greedy); !errors.Is(err, ErrInvalidRefund) {
+		t.Fatalf("refund out of range: have %v, want %v", err, ErrInvalidRefund)
+	}
+}
+
+func TestSBundleArgs(t *testing.T) {
+	st := newSBundleTester(t)
+	raw, err := st.tx(crypto.FromECDSA(testKey), 0, common.Address{}, common.Big0).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := hexutil.Bytes(raw)
+	maxBlock := hexutil.Uint64(12)
+	args := SBundleArgs{
+		Inclusion: SBundleInclusionArgs{BlockNumber: 10, MaxBlockNumber: &maxBlock},
+		Body: []SBundleBodyArgs{
+			{Tx: &tx, CanRevert: true},
+			{Bundle: &SBundleArgs{Inclusion: SBundleInclusionArgs{BlockNumber: 11}, Body: []SBundleBodyArgs{{Tx: &tx}}}},
+		},
+	}
+	bundle, err := args.ToSBundle()
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if bundle.Inclusion.BlockNumber != 10 || bundle.Inclusion.MaxBlockNumber != 12 {
+		t.Fatalf("inclusion mismatch: %+v", bundle.Inclusion)
+	}
+	if !bundle.Body[0].CanRevert || bundle.Body[0].Tx == nil {
+		t.Fatalf("tx body mismatch: %+v", bundle.Body[0])
+	}
+	if inner := bundle.Body[1].Bundle; inner == nil || inner.Inclusion.MaxBlockNumber != 11 {
+		t.Fatalf("nested bundle mismatch: %+v", bundle.Body[1])
+	}
+	if bundle.txCount() != 2 {
+		t.Fatalf("tx count mismatch: have %d, want 2", bundle.txCount())
+	}
+
+	// Nesting beyond the limit is rejected
+	deep := &SBundleArgs{Body: []SBundleBodyArgs{{Tx: &tx}}}
+	for i := 0; i < maxSBundleDepth; i++ {
+		deep = &SBundleArgs{Body: []SBundleBodyArgs{{Bundle: deep}}}
+	}
+	if _, err := deep.ToSBundle(); !errors.Is(err, errSBundleTooDeep) {
+		t.Fatalf("deep bundle: have %v, want %v", err, errSBundleTooDeep)
+	}
+	if _, err := (&SBundleArgs{Body: []SBundleBodyArgs{{}}}).ToSBundle(); !errors.Is(err, errSBundleBodyType) {
+		t.Fatalf("empty body: have %v, want %v", err, errSBundleBodyType)
+	}
+}
@@ -0,0 +1,123 @@
+package quant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	errNoSignerAccount = errors.New("no quant signer account configured")
+	errStaleNonceBlock = errors.New("nonces already reserved for a later block")
+)
+
+// nonceSource returns the next nonce of an account, taking the transactions
+// pending in the txpool into account.
+type nonceSource interface {
+	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
+}
+
+// Signer signs the strategy transactions with an account of the node's
+// account manager, so the key can live in the keystore (unlocked at startup)
+// or behind an external signer such as clef.
+type Signer struct {
+	am      *accounts.Manager
+	account accounts.Account
+	chainID *big.Int
+	signer  types.Signer
+	nonces  nonceSource
+
+	lock  sync.Mutex
+	block uint64 // Block the nonces are reserved for
+	next  uint64 // Nonce following the reserved ones
+}
+
+// NewSigner creates a signer for addr on the chain with the given id.
+func NewSigner(am *accounts.Manager, addr common.Address, chainID *big.Int, nonces nonceSource) *Signer {
+	return &Signer{
+		am:      am,
+		account: accounts.Account{Address: addr},
+		chainID: chainID,
+		signer:  types.LatestSignerForChainID(chainID),
+		nonces:  nonces,
+	}
+}
+
+// Address returns the address of the signing account.
+func (s *Signer) Address() common.Address {
+	return s.account.Address
+}
+
+// SignTx signs tx with the configured account.
+func (s *Signer) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	if s.account.Address == (common.Address{}) {
+		return nil, errNoSignerAccount
+	}
+	wallet, err := s.am.Find(s.account)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := wallet.SignTx(s.account, tx, s.chainID)
+	if err != nil {
+		return nil, err
+	}
+	// External signers may apply their own signing rules, make sure the
+	// result is valid on this chain.
+	from, err := types.Sender(s.signer, signed)
+	if err != nil {
+		return nil, err
+	}
+	if from != s.account.Address {
+		return nil, fmt.Errorf("signer returned tx from %s, expected %s", from, s.account.Address)
+	}
+	return signed, nil
+}
+
+// Nonces reserves n consecutive nonces of the account for a bundle targeting
+// the given block. The first one is the next nonce of the account in the
+// txpool, or the one following the nonces already reserved for the block, so
+// that the bundles of a block never share nonces. The reservations are dropped
+// once a later block is targeted: the bundles of the earlier blocks either
+// landed, which the txpool nonce reflects, or expired.
+func (s *Signer) Nonces(ctx context.Context, block uint64, n int) ([]uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	next, err := s.nonces.GetPoolNonce(ctx, s.account.Address)
+	if err != nil {
+		return nil, err
+	}
+	if block < s.block {
+		return nil, fmt.Errorf("%w: block %d, reserving for %d", errStaleNonceBlock, block, s.block)
+	}
+	if block == s.block && s.next > next {
+		next = s.next
+	}
+	nonces := make([]uint64, n)
+	for i := range nonces {
+		nonces[i] = next + uint64(i)
+	}
+	s.block, s.next = block, next+uint64(n)
+	return nonces, nil
+}
+
+// Release gives back the nonces reserved for a bundle of the given block that
+// won't be sent, so that the next bundle of the block reuses them instead of
+// leaving a gap. Only the latest reservation can be released.
+func (s *Signer) Release(block uint64, nonces []uint64) {
+	if len(nonces) == 0 {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if block == s.block && s.next == nonces[len(nonces)-1]+1 {
+		s.next = nonces[0]
+	}
+}
@@ -0,0 +1,107 @@
+package quant
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testNonces map[common.Address]uint64
+
+func (n testNonces) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	return n[addr], nil
+}
+
+func newTestSigner(t *testing.T, addr common.Address, nonces testNonces) *Signer {
+	t.Helper()
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	if _, err := ks.ImportECDSA(testKey, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(accounts.Account{Address: testAddr}, ""); err != nil {
+		t.Fatal(err)
+	}
+	am := accounts.NewManager(&accounts.Config{}, ks)
+	t.Cleanup(func() { am.Close() })
+	return NewSigner(am, addr, big.NewInt(56), nonces)
+}
+
+func TestSignerSignTx(t *testing.T) {
+	s := newTestSigner(t, testAddr, nil)
+
+	tx, err := s.SignTx(types.NewTransaction(0, common.Address{}, common.Big1, 21000, big.NewInt(1e9), nil))
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if tx.ChainId().Cmp(big.NewInt(56)) != 0 {
+		t.Fatalf("chain id mismatch: have %v, want 56", tx.ChainId())
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(56)), tx)
+	if err != nil || from != testAddr {
+		t.Fatalf("sender mismatch: have %v (%v), want %v", from, err, testAddr)
+	}
+
+	// Accounts unknown to the manager can't sign
+	unknown := newTestSigner(t, common.HexToAddress("0x000000000000000000000000000000000000beef"), nil)
+	if _, err := unknown.SignTx(tx); !errors.Is(err, accounts.ErrUnknownAccount) {
+		t.Fatalf("unknown account: have %v, want %v", err, accounts.ErrUnknownAccount)
+	}
+	if _, err := newTestSigner(t, common.Address{}, nil).SignTx(tx); !errors.Is(err, errNoSignerAccount) {
+		t.Fatalf("no account: have %v, want %v", err, errNoSignerAccount)
+	}
+}
+
+func TestSignerNonces(t *testing.T) {
+	pool := testNonces{testAddr: 7}
+	s := newTestSigner(t, testAddr, pool)
+
+	check := func(block uint64, want uint64) {
+		t.Helper()
+		nonces, err := s.Nonces(context.Background(), block, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, nonce := range nonces {
+			if nonce != want+uint64(i) {
+				t.Fatalf("block %d: nonce %d mismatch: have %d, want %d", block, i, nonce, want+uint64(i))
+			}
+		}
+	}
+	check(10, 7)
+
+	// The bundles of the same block take the following nonces
+	check(10, 10)
+
+	// The reservations are dropped for a later block
+	pool[testAddr] = 8
+	check(11, 8)
+
+	// The pool nonce is used once the reserved ones are included
+	pool[testAddr] = 20
+	check(11, 20)
+
+	if _, err := s.Nonces(context.Background(), 10, 3); !errors.Is(err, errStaleNonceBlock) {
+		t.Fatalf("stale block: have %v, want %v", err, errStaleNonceBlock)
+	}
+}
+
+func TestSignerRelease(t *testing.T) {
+	s := newTestSigner(t, testAddr, testNonces{testAddr: 7})
+
+	first, _ := s.Nonces(context.Background(), 10, 3)
+	second, _ := s.Nonces(context.Background(), 10, 3)
+
+	// Only the latest reservation is released
+	s.Release(10, first)
+	s.Release(10, second)
+	if nonces, _ := s.Nonces(context.Background(), 10, 3); nonces[0] != second[0] {
+		t.Fatalf("released nonces not reused: have %d, want %d", nonces[0], second[0])
+	}
+}
@@ -0,0 +1,200 @@
+package quant
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// defaultBlockInterval is the block time in seconds assumed for engines that
+// don't report one.
+const defaultBlockInterval = 3
+
+var errNoTransactions = errors.New("no transactions to simulate")
+
+// SimTxResult is the outcome of a single transaction of a simulated bundle.
+type SimTxResult struct {
+	TxHash       common.Hash    `json:"txHash"`
+	Receipt      *types.Receipt `json:"receipt,omitempty"`
+	Logs         []*types.Log   `json:"logs,omitempty"`
+	GasUsed      uint64         `json:"gasUsed"`
+	Reverted     bool           `json:"reverted"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Error        string         `json:"error,omitempty"` // Set if the tx could not be included at all
+}
+
+// SimResult is the outcome of simulating an ordered list of transactions on
+// top of a parent block.
+type SimResult struct {
+	Header        *types.Header               `json:"header"`
+	Txs           []*SimTxResult              `json:"txs"`
+	GasUsed       uint64                      `json:"gasUsed"`
+	BalanceDeltas map[common.Address]*big.Int `json:"balanceDeltas"`
+}
+
+// Failed reports whether any transaction reverted or could not be applied.
+func (r *SimResult) Failed() bool {
+	for _, tx := range r.Txs {
+		if tx.Reverted || tx.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Simulator executes transaction bundles as if they were included in the
+// block following a given parent, without touching the canonical state.
+type Simulator struct {
+	chain   *core.BlockChain
+	watched []common.Address // Accounts whose balance changes are reported
+}
+
+// NewSimulator creates a simulator reporting the balance changes of the
+// watched accounts in addition to the ones receiving the block fees.
+func NewSimulator(chain *core.BlockChain, watched []common.Address) *Simulator {
+	return &Simulator{
+		chain:   chain,
+		watched: dedupAddresses(watched),
+	}
+}
+
+// Simulate runs txs in order on a copy of the head state.
+func (s *Simulator) Simulate(txs []*types.Transaction) (*SimResult, error) {
+	parent := s.chain.CurrentBlock()
+	statedb, err := s.chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	return s.SimulateAt(parent, statedb, txs)
+}
+
+// SimulateAt runs txs in order on statedb, which must be the post-state of
+// parent. The state is modified, callers should pass a copy if they need to
+// keep the original.
+func (s *Simulator) SimulateAt(parent *types.Header, statedb *state.StateDB, txs []*types.Transaction) (*SimResult, error) {
+	if len(txs) == 0 {
+		return nil, errNoTransactions
+	}
+	header, err := s.NextHeader(parent)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		config  = s.chain.Config()
+		gasPool = new(core.GasPool).AddGas(header.GasLimit)
+		usedGas uint64
+		watched = append([]common.Address{header.Coinbase}, s.watched...)
+	)
+	// Parlia collects the transaction fees in the system address and only
+	// distributes them when the block is finalised.
+	if config.Parlia != nil {
+		watched = append(watched, consensus.SystemAddress)
+	}
+	before := make(map[common.Address]*big.Int, len(watched))
+	for _, addr := range watched {
+		before[addr] = statedb.GetBalance(addr).ToBig()
+	}
+	// The output of the outermost call frame carries the revert reason.
+	var output []byte
+	hooks := &tracing.Hooks{
+		OnExit: func(depth int, out []byte, gasUsed uint64, err error, reverted bool) {
+			if depth == 0 {
+				output = common.CopyBytes(out)
+			}
+		},
+	}
+	blockContext := core.NewEVMBlockContext(header, s.chain, &header.Coinbase)
+	vmenv := vm.NewEVM(blockContext, statedb, config, vm.Config{Tracer: hooks})
+
+	result := &SimResult{
+		Header:        header,
+		BalanceDeltas: make(map[common.Address]*big.Int, len(watched)),
+	}
+	for i, tx := range txs {
+		output = nil
+		res := &SimTxResult{TxHash: tx.Hash()}
+		result.Txs = append(result.Txs, res)
+
+		snapshot := statedb.Snapshot()
+		statedb.SetTxContext(tx.Hash(), i)
+		receipt, err := core.ApplyTransactionPersonal(vmenv, gasPool, statedb, header, tx, &usedGas)
+		if err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			res.Error = err.Error()
+			continue
+		}
+		statedb.Finalise(true)
+
+		res.Receipt = receipt
+		res.Logs = receipt.Logs
+		res.GasUsed = receipt.GasUsed
+		if receipt.Status == types.ReceiptStatusFailed {
+			res.Reverted = true
+			res.RevertReason = revertReason(output)
+		}
+	}
+	result.GasUsed = usedGas
+	for _, addr := range watched {
+		after := statedb.GetBalance(addr).ToBig()
+		result.BalanceDeltas[addr] = after.Sub(after, before[addr])
+	}
+	return result, nil
+}
+
+// NextHeader assembles the header of the block following parent, with the
+// coinbase set to the next in-turn validator. It is not sealed.
+func (s *Simulator) NextHeader(parent *types.Header) (*types.Header, error) {
+	var (
+		config   = s.chain.Config()
+		engine   = s.chain.Engine()
+		interval = uint64(defaultBlockInterval)
+	)
+	if posa, ok := engine.(consensus.PoSA); ok {
+		interval = posa.BlockInterval()
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + interval,
+		Difficulty: big.NewInt(2),
+		Coinbase:   parent.Coinbase,
+	}
+	if validator, err := engine.NextInTurnValidator(s.chain, parent); err == nil {
+		header.Coinbase = validator
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if config.IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if parent.ExcessBlobGas != nil && parent.BlobGasUsed != nil {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		}
+		header.ExcessBlobGas = &excessBlobGas
+	}
+	return header, nil
+}
+
+// revertReason decodes the Error(string) payload of a revert, falling back to
+// the raw return data.
+func revertReason(output []byte) string {
+	if len(output) == 0 {
+		return ""
+	}
+	if reason, err := abi.UnpackRevert(output); err == nil {
+		return reason
+	}
+	return fmt.Sprintf("0x%x", output)
+}
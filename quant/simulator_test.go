@@ -0,0 +1,124 @@
+package quant
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testFunds   = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	testGasTip  = big.NewInt(10 * params.GWei)
+	testReverts = common.HexToAddress("0x0000000000000000000000000000000000dead01")
+)
+
+// revertingCode is the runtime code of a contract reverting every call with
+// Error("nope").
+var revertingCode = common.FromHex("0x6064600c60003960646000fd" +
+	"08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000004" +
+	"6e6f706500000000000000000000000000000000000000000000000000000000")
+
+func newTestChain(t *testing.T, alloc types.GenesisAlloc) *core.BlockChain {
+	t.Helper()
+
+	alloc[testAddr] = types.Account{Balance: testFunds}
+	gspec := &core.Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: 30_000_000,
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+		Alloc:    alloc,
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	return chain
+}
+
+func signTestTx(t *testing.T, chain *core.BlockChain, nonce uint64, to common.Address, value *big.Int) *types.Transaction {
+	t.Helper()
+
+	tx, err := types.SignTx(types.NewTransaction(nonce, to, value, 100_000, testGasTip, nil), types.LatestSigner(chain.Config()), testKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	return tx
+}
+
+func TestSimulateBundle(t *testing.T) {
+	chain := newTestChain(t, types.GenesisAlloc{
+		testReverts: {Code: revertingCode, Balance: common.Big0},
+	})
+	var (
+		recipient = common.HexToAddress("0x000000000000000000000000000000000000beef")
+		value     = big.NewInt(params.Ether)
+		head      = chain.CurrentBlock()
+	)
+	sim := NewSimulator(chain, []common.Address{testAddr, recipient})
+	txs := []*types.Transaction{
+		signTestTx(t, chain, 0, recipient, value),
+		signTestTx(t, chain, 1, testReverts, common.Big0),
+		signTestTx(t, chain, 1, recipient, value), // Nonce already used
+	}
+	result, err := sim.Simulate(txs)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if result.Header.Number.Uint64() != head.Number.Uint64()+1 {
+		t.Fatalf("block number mismatch: have %v, want %d", result.Header.Number, head.Number.Uint64()+1)
+	}
+	if result.Header.BaseFee == nil {
+		t.Fatal("base fee not set")
+	}
+	if len(result.Txs) != 3 {
+		t.Fatalf("result count mismatch: have %d, want 3", len(result.Txs))
+	}
+	if tx := result.Txs[0]; tx.Reverted || tx.Error != "" || tx.GasUsed != params.TxGas {
+		t.Fatalf("transfer result mismatch: %+v", tx)
+	}
+	if tx := result.Txs[1]; !tx.Reverted || tx.RevertReason != "nope" {
+		t.Fatalf("revert result mismatch: %+v", tx)
+	}
+	if tx := result.Txs[2]; tx.Error == "" || tx.Receipt != nil {
+		t.Fatalf("expected nonce error, have %+v", tx)
+	}
+	if !result.Failed() {
+		t.Fatal("bundle with a reverted tx not reported as failed")
+	}
+	if result.GasUsed != result.Txs[0].GasUsed+result.Txs[1].GasUsed {
+		t.Fatalf("gas used mismatch: have %d, want %d", result.GasUsed, result.Txs[0].GasUsed+result.Txs[1].GasUsed)
+	}
+	if delta := result.BalanceDeltas[recipient]; delta.Cmp(value) != 0 {
+		t.Fatalf("recipient delta mismatch: have %v, want %v", delta, value)
+	}
+	// The sender pays the transfer and the gas of both included txs.
+	spent := new(big.Int).Neg(value)
+	for _, tx := range result.Txs[:2] {
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.GasUsed), testGasTip)
+		spent.Sub(spent, fee)
+	}
+	if delta := result.BalanceDeltas[testAddr]; delta.Cmp(spent) != 0 {
+		t.Fatalf("sender delta mismatch: have %v, want %v", delta, spent)
+	}
+	// The canonical state must be untouched
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce := statedb.GetNonce(testAddr); nonce != 0 {
+		t.Fatalf("head state modified, nonce %d", nonce)
+	}
+}
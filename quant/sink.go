@@ -0,0 +1,256 @@
+package quant
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Sink types supported by NewSink.
+const (
+	SinkElastic = "elasticsearch"
+	SinkFile    = "file"
+	SinkMemory  = "memory"
+	SinkSQLite  = "sqlite"
+)
+
+var (
+	storageQueuedMeter  = metrics.NewRegisteredMeter("quant/storage/queued", nil)
+	storageDroppedMeter = metrics.NewRegisteredMeter("quant/storage/dropped", nil)
+	storageFailedMeter  = metrics.NewRegisteredMeter("quant/storage/failed", nil)
+	storageFlushTimer   = metrics.NewRegisteredTimer("quant/storage/flush", nil)
+
+	errStorageFull   = errors.New("storage queue full")
+	errStorageClosed = errors.New("storage closed")
+)
+
+// Sink is a destination for the documents recorded by the quant service,
+// such as observed swaps and pending transactions. Documents are grouped by
+// index, which is the table or file they belong to.
+type Sink interface {
+	// Put stores a single document and returns its identifier, if the
+	// backend assigns one.
+	Put(doc interface{}, index string) (string, error)
+
+	// BulkPut stores a batch of documents of the same index.
+	BulkPut(docs []interface{}, index string) error
+
+	// Close flushes and releases the resources held by the sink.
+	Close() error
+}
+
+// SinkConfig contains the settings of the sink documents are written to.
+type SinkConfig struct {
+	Type          string        // One of "elasticsearch", "file", "sqlite" or "memory"
+	ElasticURL    string        // Elasticsearch endpoint, may contain credentials
+	FileDir       string        // Directory of the JSONL files, relative to the data directory
+	FileMaxSize   uint64        // Size in bytes after which a JSONL file is rotated
+	SQLitePath    string        // Database file of the sqlite sink, relative to the data directory
+	BatchSize     int           // Number of queued documents triggering a flush
+	BufferSize    int           // Number of documents queued before new ones are dropped
+	FlushInterval time.Duration // Maximum time a document stays queued
+}
+
+// DefaultSinkConfig writes documents to rotating JSONL files in the data
+// directory, so the node does not depend on any external service.
+var DefaultSinkConfig = SinkConfig{
+	Type:          SinkFile,
+	FileDir:       "quant",
+	FileMaxSize:   256 * 1024 * 1024,
+	SQLitePath:    "quant.sqlite",
+	BatchSize:     256,
+	BufferSize:    16384,
+	FlushInterval: time.Second,
+}
+
+// NewSink creates the sink described by config.
+func NewSink(config SinkConfig) (Sink, error) {
+	switch config.Type {
+	case SinkElastic:
+		return NewElasticSink(config.ElasticURL)
+	case SinkFile:
+		return NewFileSink(config.FileDir, config.FileMaxSize)
+	case SinkSQLite:
+		return NewSQLiteSink(config.SQLitePath)
+	case SinkMemory:
+		return NewMemorySink(), nil
+	default:
+		return nil, fmt.Errorf("unknown quant sink type %q", config.Type)
+	}
+}
+
+// MemorySink keeps all documents in memory. It is meant for tests.
+type MemorySink struct {
+	lock sync.Mutex
+	docs map[string][]interface{}
+}
+
+// NewMemorySink creates an empty in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{docs: make(map[string][]interface{})}
+}
+
+// Put implements Sink, the returned identifier is the position in the index.
+func (s *MemorySink) Put(doc interface{}, index string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.docs[index] = append(s.docs[index], doc)
+	return strconv.Itoa(len(s.docs[index]) - 1), nil
+}
+
+// BulkPut implements Sink.
+func (s *MemorySink) BulkPut(docs []interface{}, index string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.docs[index] = append(s.docs[index], docs...)
+	return nil
+}
+
+// Close implements Sink.
+func (s *MemorySink) Close() error {
+	return nil
+}
+
+// Docs returns a copy of the documents stored in index.
+func (s *MemorySink) Docs(index string) []interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]interface{}(nil), s.docs[index]...)
+}
+
+type storageItem struct {
+	index string
+	doc   interface{}
+}
+
+// Storage queues documents and writes them to a Sink in batches from a
+// background goroutine, so that a slow or unreachable sink never blocks the
+// caller. Documents are dropped when the queue is full.
+type Storage struct {
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+
+	queue     chan storageItem
+	closeOnce sync.Once
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewStorage creates a storage flushing to sink according to config.
+func NewStorage(sink Sink, config SinkConfig) *Storage {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultSinkConfig.BatchSize
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultSinkConfig.BufferSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultSinkConfig.FlushInterval
+	}
+	s := &Storage{
+		sink:          sink,
+		batchSize:     config.BatchSize,
+		flushInterval: config.FlushInterval,
+		queue:         make(chan storageItem, config.BufferSize),
+		quit:          make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.loop()
+	return s
+}
+
+// Put queues a document. The identifier is always empty since the document
+// is written asynchronously.
+func (s *Storage) Put(doc interface{}, index string) (string, error) {
+	select {
+	case <-s.quit:
+		return "", errStorageClosed
+	default:
+	}
+	select {
+	case s.queue <- storageItem{index: index, doc: doc}:
+		storageQueuedMeter.Mark(1)
+		return "", nil
+	default:
+		storageDroppedMeter.Mark(1)
+		return "", errStorageFull
+	}
+}
+
+// BulkPut queues a batch of documents.
+func (s *Storage) BulkPut(docs []interface{}, index string) error {
+	for _, doc := range docs {
+		if _, err := s.Put(doc, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the queued documents and closes the underlying sink.
+func (s *Storage) Close() error {
+	s.closeOnce.Do(func() { close(s.quit) })
+	s.wg.Wait()
+	return s.sink.Close()
+}
+
+func (s *Storage) loop() {
+	defer s.wg.Done()
+
+	var (
+		pending int
+		batches = make(map[string][]interface{})
+		ticker  = time.NewTicker(s.flushInterval)
+	)
+	defer ticker.Stop()
+
+	add := func(item storageItem) {
+		batches[item.index] = append(batches[item.index], item.doc)
+		pending++
+	}
+	flush := func() {
+		if pending == 0 {
+			return
+		}
+		start := time.Now()
+		for index, docs := range batches {
+			if err := s.sink.BulkPut(docs, index); err != nil {
+				storageFailedMeter.Mark(int64(len(docs)))
+				log.Error("Failed to flush quant documents", "index", index, "docs", len(docs), "err", err)
+			}
+			delete(batches, index)
+		}
+		pending = 0
+		storageFlushTimer.UpdateSince(start)
+	}
+	for {
+		select {
+		case item := <-s.queue:
+			add(item)
+			if pending >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.quit:
+			for {
+				select {
+				case item := <-s.queue:
+					add(item)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
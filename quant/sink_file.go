@@ -0,0 +1,140 @@
+package quant
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileSink appends documents as JSON lines to one file per index. Once a file
+// grows beyond the size limit it is renamed with a timestamp suffix and a new
+// one is started.
+type FileSink struct {
+	dir     string
+	maxSize uint64
+
+	lock  sync.Mutex
+	files map[string]*jsonlFile
+}
+
+type jsonlFile struct {
+	file   *os.File
+	writer *bufio.Writer
+	size   uint64
+}
+
+// NewFileSink creates a file sink writing into dir. A maxSize of zero
+// disables rotation.
+func NewFileSink(dir string, maxSize uint64) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSink{
+		dir:     dir,
+		maxSize: maxSize,
+		files:   make(map[string]*jsonlFile),
+	}, nil
+}
+
+// Put implements Sink. File sinks do not assign identifiers.
+func (s *FileSink) Put(doc interface{}, index string) (string, error) {
+	return "", s.BulkPut([]interface{}{doc}, index)
+}
+
+// BulkPut implements Sink.
+func (s *FileSink) BulkPut(docs []interface{}, index string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := s.open(index)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		blob, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		blob = append(blob, '\n')
+		if _, err := f.writer.Write(blob); err != nil {
+			return err
+		}
+		f.size += uint64(len(blob))
+	}
+	if err := f.writer.Flush(); err != nil {
+		return err
+	}
+	if s.maxSize > 0 && f.size >= s.maxSize {
+		return s.rotate(index)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *FileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var errs []error
+	for index, f := range s.files {
+		if err := f.close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(s.files, index)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close %d files: %v", len(errs), errs[0])
+	}
+	return nil
+}
+
+func (s *FileSink) path(index string) string {
+	return filepath.Join(s.dir, index+".jsonl")
+}
+
+// open returns the active file of index, opening it in append mode if needed.
+func (s *FileSink) open(index string) (*jsonlFile, error) {
+	if f, ok := s.files[index]; ok {
+		return f, nil
+	}
+	file, err := os.OpenFile(s.path(index), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	f := &jsonlFile{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		size:   uint64(stat.Size()),
+	}
+	s.files[index] = f
+	return f, nil
+}
+
+// rotate closes the active file of index and moves it aside. The next write
+// starts a new file.
+func (s *FileSink) rotate(index string) error {
+	f := s.files[index]
+	delete(s.files, index)
+	if err := f.close(); err != nil {
+		return err
+	}
+	rotated := filepath.Join(s.dir, fmt.Sprintf("%s-%s.jsonl", index, time.Now().UTC().Format("20060102T150405.000000000")))
+	return os.Rename(s.path(index), rotated)
+}
+
+func (f *jsonlFile) close() error {
+	if err := f.writer.Flush(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,128 @@
+package quant
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver of the sqlite sink
+)
+
+// sqliteIndexName restricts the index names, which are used as table names.
+var sqliteIndexName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteSink stores documents as JSON rows in a SQLite database, one table
+// per index. Rows carry the insertion time, so the documents can be queried
+// with the SQLite JSON functions.
+type SQLiteSink struct {
+	db *sql.DB
+
+	lock   sync.Mutex
+	tables map[string]bool // Tables known to exist
+}
+
+// NewSQLiteSink opens or creates the SQLite database at path.
+func NewSQLiteSink(path string) (*SQLiteSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	// A single connection serializes the writes, which SQLite does anyway.
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+	return &SQLiteSink{db: db, tables: make(map[string]bool)}, nil
+}
+
+// Put implements Sink, the returned identifier is the row id in the index.
+func (s *SQLiteSink) Put(doc interface{}, index string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.createTable(index); err != nil {
+		return "", err
+	}
+	blob, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	res, err := s.db.Exec(`INSERT INTO "`+index+`" (doc, created) VALUES (?, ?)`, string(blob), time.Now().UnixMilli())
+	if err != nil {
+		return "", err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// BulkPut implements Sink. The documents are inserted in one transaction.
+func (s *SQLiteSink) BulkPut(docs []interface{}, index string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.createTable(index); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO "` + index + `" (doc, created) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	created := time.Now().UnixMilli()
+	for _, doc := range docs {
+		blob, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(string(blob), created); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close implements Sink.
+func (s *SQLiteSink) Close() error {
+	return s.db.Close()
+}
+
+// createTable creates the table of index if needed. The caller must hold the
+// lock.
+func (s *SQLiteSink) createTable(index string) error {
+	if s.tables[index] {
+		return nil
+	}
+	if !sqliteIndexName.MatchString(index) {
+		return fmt.Errorf("invalid sqlite sink index %q", index)
+	}
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS "` + index + `" (
+		id      INTEGER PRIMARY KEY AUTOINCREMENT,
+		doc     TEXT    NOT NULL,
+		created INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	s.tables[index] = true
+	return nil
+}
@@ -0,0 +1,161 @@
+package quant
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// slowSink blocks every write until released.
+type slowSink struct {
+	*MemorySink
+	release chan struct{}
+}
+
+func (s *slowSink) BulkPut(docs []interface{}, index string) error {
+	<-s.release
+	return s.MemorySink.BulkPut(docs, index)
+}
+
+func TestStorageBatching(t *testing.T) {
+	sink := NewMemorySink()
+	storage := NewStorage(sink, SinkConfig{BatchSize: 2, BufferSize: 16, FlushInterval: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if _, err := storage.Put(i, "numbers"); err != nil {
+			t.Fatalf("put %d failed: %v", i, err)
+		}
+	}
+	// The first two documents fill a batch, the last one is flushed on close.
+	if err := storage.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if docs := sink.Docs("numbers"); len(docs) != 3 {
+		t.Fatalf("document count mismatch: have %d, want 3", len(docs))
+	}
+	if _, err := storage.Put(3, "numbers"); err != errStorageClosed {
+		t.Fatalf("put after close: have %v, want %v", err, errStorageClosed)
+	}
+}
+
+func TestStorageNonBlocking(t *testing.T) {
+	sink := &slowSink{MemorySink: NewMemorySink(), release: make(chan struct{})}
+	storage := NewStorage(sink, SinkConfig{BatchSize: 1, BufferSize: 1, FlushInterval: time.Hour})
+
+	// Keep putting until the queue is full, none of the calls may block on
+	// the stalled sink.
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 10; i++ {
+			if _, err := storage.Put(i, "numbers"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != errStorageFull {
+			t.Fatalf("error mismatch: have %v, want %v", err, errStorageFull)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("put blocked on a slow sink")
+	}
+	close(sink.release)
+	storage.Close()
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir, 32)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	doc := map[string]string{"hash": "0x0000000000000000000000000000000000000000"}
+	for i := 0; i < 3; i++ {
+		if err := sink.BulkPut([]interface{}{doc}, "transactions"); err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "transactions*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every document exceeds the limit, so each one ends up in its own
+	// rotated file.
+	if len(files) != 3 {
+		t.Fatalf("file count mismatch: have %d, want 3", len(files))
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var have map[string]string
+			if err := json.Unmarshal(scanner.Bytes(), &have); err != nil {
+				t.Fatalf("invalid line in %s: %v", file, err)
+			}
+			if have["hash"] != doc["hash"] {
+				t.Fatalf("document mismatch: have %v, want %v", have, doc)
+			}
+		}
+		f.Close()
+	}
+}
+
+func TestSQLiteSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quant.sqlite")
+	sink, err := NewSink(SinkConfig{Type: SinkSQLite, SQLitePath: path})
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	id, err := sink.Put(map[string]int{"n": 0}, "numbers")
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if id != "1" {
+		t.Fatalf("identifier mismatch: have %s, want 1", id)
+	}
+	if err := sink.BulkPut([]interface{}{map[string]int{"n": 1}, map[string]int{"n": 2}}, "numbers"); err != nil {
+		t.Fatalf("bulk put failed: %v", err)
+	}
+	if _, err := sink.Put(1, `numbers"; DROP TABLE numbers; --`); err == nil {
+		t.Fatal("invalid index accepted")
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	// The documents are kept when the database is reopened
+	reopened, err := NewSQLiteSink(path)
+	if err != nil {
+		t.Fatalf("failed to reopen sink: %v", err)
+	}
+	defer reopened.Close()
+
+	rows, err := reopened.db.Query(`SELECT json_extract(doc, '$.n') FROM numbers ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var have []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		have = append(have, n)
+	}
+	if len(have) != 3 || have[0] != 0 || have[1] != 1 || have[2] != 2 {
+		t.Fatalf("documents mismatch: have %v, want [0 1 2]", have)
+	}
+}
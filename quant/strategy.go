@@ -1,46 +1,76 @@
 package quant
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quant/amm"
 )
 
-var v3RouterAdress common.Address
+var v3RouterAddress common.Address
 var usdtAddress common.Address
-var bnbAdress common.Address
-var userAdress common.Address
-var bloxroute common.Address
+var wbnbAddress common.Address
+var bloxrouteBuilder common.Address
 
 func init() {
-	v3RouterAdress = common.HexToAddress("0x1b81d678ffb9c0263b24a97847620c99d213eb14") // 替换为实际的 spender 地址
+	v3RouterAddress = common.HexToAddress("0x1b81d678ffb9c0263b24a97847620c99d213eb14") // 替换为实际的 spender 地址
 	usdtAddress = common.HexToAddress("0x55d398326f99059fF775485246999027B3197955")
 	usdtAddress = common.HexToAddress("0x55d398326f99059fF775485246999027B3197955")
-	bnbAdress = common.HexToAddress("0xBB4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c") // WBNB address
-	userAdress = common.HexToAddress("0x8cB48323AcdAaA5f12CA0a1E5d59a97fd22CbeDA")
-	bloxroute = common.HexToAddress("0x74c5F8C6ffe41AD4789602BDB9a48E6Cad623520")
+	wbnbAddress = common.HexToAddress("0xBB4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c") // WBNB address
+	// Payment address of the bloXroute builder
+	bloxrouteBuilder = common.HexToAddress("0x74c5F8C6ffe41AD4789602BDB9a48E6Cad623520")
 }
 
+const (
+	swapGasLimit     = 300000
+	bundleGasPrice   = 1e9              // 1 Gwei
+	bribeAmount      = 1e15             // Paid to the builder for each bundle
+	maxFrontRunRatio = 100              // Upper bound of the front-run, relative to the victim input
+	sandwichGas      = 2 * swapGasLimit // Gas limit of the front and back txs
+
+	// sandwichTickWords is the number of tick bitmap words loaded around the
+	// current price, each covering 256 tick spacings.
+	sandwichTickWords = 2
+
+	// bundleSubmitQueueSize is the number of bundles waiting to be sent to
+	// the builders, the ones found while it is full are dropped.
+	bundleSubmitQueueSize = 16
+
+	// bundleSubmitWorkers is the number of bundles sent concurrently.
+	bundleSubmitWorkers = 4
+)
+
+var bundleDropMeter = metrics.NewRegisteredMeter("quant/bundle/drop", nil)
+
 type Strategy struct {
 	poolAbi    *abi.ABI
 	routerAbi  *abi.ABI
 	txs        chan *types.Transaction
 	apiBackend ethapi.Backend
 	eth        *eth.Ethereum
+	simulator  *Simulator
+	signer     *Signer
+	builder    *Builder
+	bundles    chan *Bundle // Bundles waiting to be sent to the builders
 }
 
-func NewStrategy(apibackend ethapi.Backend, eth *eth.Ethereum) *Strategy {
+// NewStrategy creates a strategy trading from the signer's account, whose
+// simulations report the balance changes of the given accounts along with the
+// trading account and the coinbase. Bundles are submitted through builder.
+func NewStrategy(apibackend ethapi.Backend, eth *eth.Ethereum, signer *Signer, builder *Builder, watched []common.Address) *Strategy {
 	txs := make(chan *types.Transaction, 100)
 	abi, _ := ParseAbi()
 	routerAbi, _ := ParseRouterAbi()
@@ -50,6 +80,10 @@ func NewStrategy(apibackend ethapi.Backend, eth *eth.Ethereum) *Strategy {
 		routerAbi:  routerAbi,
 		apiBackend: apibackend,
 		eth:        eth,
+		simulator:  NewSimulator(eth.BlockChain(), append([]common.Address{signer.Address()}, watched...)),
+		signer:     signer,
+		builder:    builder,
+		bundles:    make(chan *Bundle, bundleSubmitQueueSize),
 	}
 }
 
@@ -88,103 +122,242 @@ type ExactOutPutParams struct {
 	Recipient         common.Address
 	Deadline          *big.Int
 	AmountOut         *big.Int
-	AmountOutMinimum  *big.Int
+	AmountInMaximum   *big.Int
 	SqrtPriceLimitX96 *big.Int
 }
 
-func (s *Strategy) Try(tx *types.Transaction) (*types.Receipt, error) {
-	eth := s.eth
+// Opportunity is a profitable sandwich around one of the swaps of a pending
+// transaction.
+type Opportunity struct {
+	Intent   *SwapIntent
+	Pool     common.Address
+	Sandwich *amm.Sandwich
+	Profit   *big.Int // Net profit in BNB
+	Gas      uint64   // Gas limit of the front and back txs
+}
 
-	chainConfig := eth.BlockChain().Config()
-	chain := eth.BlockChain()
-	statedb, err := eth.BlockChain().State()
+// Try simulates a pending swap on the head state and, for each profitable
+// sandwich around it, builds the bundle txs and submits them.
+func (s *Strategy) Try(swap *PendingSwap) (*types.Receipt, error) {
+	log.Trace("Trying pending swap", "hash", swap.Hash, "intents", len(swap.Intents))
+
+	// Execute the transaction on top of the head state as if it was included
+	// in the next block
+	parent := s.eth.BlockChain().CurrentBlock()
+	statedb, err := s.eth.BlockChain().StateAt(parent.Root)
 	if err != nil {
 		return nil, err
 	}
-	gasPool := new(core.GasPool).AddGas(chain.GasLimit())
-	hash := tx.Hash().Hex()
-
-	fmt.Println(hash)
-	// Create a new context to be used in the EVM environment
-	//var processor []core.ReceiptProcessor
-	header := chain.CurrentHeader()
-	blockContext := core.NewEVMBlockContext(header, ethapi.NewChainContext(context.Background(), s.apiBackend), nil)
-	vmenv := vm.NewEVM(blockContext, statedb, chainConfig, vm.Config{})
-
-	snapshot := statedb.Snapshot()
-	var zero uint64 = 0
-	// Start executing the transaction
-	statedb.SetTxContext(tx.Hash(), 1)
-	receipt, err := core.ApplyTransactionPersonal(vmenv, gasPool, statedb, chain.CurrentHeader(), tx, &zero)
+	receipt, opportunities, err := s.Evaluate(parent, statedb, swap)
 	if err != nil {
 		return nil, err
 	}
-	statedb.RevertToSnapshot(snapshot)
-
-	for _, vLog := range receipt.Logs {
-		// 检查事件的签名是否匹配
-		if vLog.Topics[0] == common.HexToHash("0x19b47279256b2a23a1665c810c8d55a1758940ee09377d4f8d26497a3577dc83") {
-			// 解析事件
-			var event Event
-			err := s.poolAbi.UnpackIntoInterface(&event, "Swap", vLog.Data)
-			if err != nil {
-				log.Printf("failed to unpack log: %v", err)
-				continue
-			}
+	for _, opp := range opportunities {
+		log.Debug("Found sandwich opportunity", "hash", swap.Hash, "pool", opp.Pool, "in", opp.Sandwich.AmountIn, "profit", opp.Profit)
+
+		// The front, back and bribe txs take the next nonces of the trading
+		// account, not shared with the other bundles of the block.
+		block := parent.Number.Uint64() + 1
+		nonces, err := s.signer.Nonces(context.Background(), block, 3)
+		if err != nil {
+			return nil, err
+		}
+		txs, err := s.sandwichTxs(swap, opp, nonces)
+		if err != nil {
+			s.signer.Release(block, nonces)
+			return nil, err
+		}
+		s.enqueue(&Bundle{Txs: txs, BlockNumber: block})
+	}
+	return receipt, nil
+}
 
-			// 定义 100 * 1e18
-			threshold := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+// sandwichTxs builds and signs the txs of the bundle sandwiching the pending
+// swap: the front tx, the swap, the back tx and the bribe tx, using the given
+// nonces.
+func (s *Strategy) sandwichTxs(swap *PendingSwap, opp *Opportunity, nonces []uint64) ([]*types.Transaction, error) {
+	intent, sandwich := opp.Intent, opp.Sandwich
+	fee := new(big.Int).SetUint64(uint64(intent.Fees[0]))
 
-			// 判断 event.Amount0 是否大于 100 * 1e18
-			if event.Amount1.Cmp(threshold) > 0 {
-				fmt.Println("Amount0 is greater than 100 * 1e18")
+	frontTx, err := s.CtreateExactInputTx(intent.TokenIn(), intent.TokenOut(), fee, sandwich.AmountIn, nonces[0])
+	if err != nil {
+		return nil, err
+	}
+	frontTx, _, err = s.SignTx(frontTx)
+	if err != nil {
+		return nil, err
+	}
+	backTx, err := s.CtreateExactInputTx(intent.TokenOut(), intent.TokenIn(), fee, sandwich.FrontOut, nonces[1])
+	if err != nil {
+		return nil, err
+	}
+	backTx, _, err = s.SignTx(backTx)
+	if err != nil {
+		return nil, err
+	}
+	bribeTx, _, err := s.BloxRouteTx(nonces[2], big.NewInt(bribeAmount), big.NewInt(bundleGasPrice))
+	if err != nil {
+		return nil, err
+	}
+	return []*types.Transaction{frontTx, swap.Tx, backTx, bribeTx}, nil
+}
 
+// Evaluate simulates swap on statedb, the post-state of parent, and sizes a
+// sandwich around each of its single hop exact input swaps through a
+// PancakeV3 pool. statedb is not modified. The receipt of the swap is
+// returned along with the profitable sandwiches.
+func (s *Strategy) Evaluate(parent *types.Header, statedb *state.StateDB, swap *PendingSwap) (*types.Receipt, []*Opportunity, error) {
+	result, err := s.simulator.SimulateAt(parent, statedb.Copy(), []*types.Transaction{swap.Tx})
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.Txs[0].Error != "" {
+		return nil, nil, errors.New(result.Txs[0].Error)
+	}
+	receipt := result.Txs[0].Receipt
+	if receipt.Status == types.ReceiptStatusFailed {
+		return receipt, nil, nil
+	}
+	// The first Swap log is emitted by the pool of the first hop
+	var pool common.Address
+	for _, vLog := range receipt.Logs {
+		if len(vLog.Topics) > 0 && vLog.Topics[0] == s.poolAbi.Events["Swap"].ID {
+			pool = vLog.Address
+			break
+		}
+	}
+	if pool == (common.Address{}) {
+		return receipt, nil, nil
+	}
+	var opportunities []*Opportunity
+	for _, intent := range swap.Intents {
+		// Only single hop exact input swaps can be sized, the others don't
+		// bound the price impact of the front-run.
+		if !intent.ExactInput || len(intent.Path) != 2 || len(intent.Fees) != 1 {
+			continue
+		}
+		sandwich, profit, err := s.sandwich(statedb, pool, intent)
+		if err != nil {
+			if !errors.Is(err, amm.ErrUnprofitable) {
+				log.Debug("Failed to size sandwich", "hash", swap.Hash, "pool", pool, "err", err)
 			}
-			var txs []*types.Transaction
-			var txsData []string
-
-			// front tx
-			frontTx, frontData, err := s.FrontTrans(big.NewInt(1e18), 3)
-			txs = append(txs, frontTx)
-			txsData = append(txsData, frontData)
-			// victim tx
-			victimTx := tx
-			victimData, err := s.GetData(victimTx)
-			if err != nil {
-				log.Fatalf("Failed to marshal transaction: %v", err)
-			}
-			txs = append(txs, victimTx)
-			txsData = append(txsData, victimData)
+			continue
+		}
+		opportunities = append(opportunities, &Opportunity{
+			Intent:   intent,
+			Pool:     pool,
+			Sandwich: sandwich,
+			Profit:   profit,
+			Gas:      sandwichGas,
+		})
+	}
+	return receipt, opportunities, nil
+}
 
-			// back tx
-			backTx, backData, err := s.BackTrans(big.NewInt(1e18), 4)
-			txs = append(txs, backTx)
-			txsData = append(txsData, backData)
+// enqueue hands bundle over to the submission workers, so that the builders
+// don't hold up the pending txs. The bundle is dropped if the queue is full.
+func (s *Strategy) enqueue(bundle *Bundle) {
+	select {
+	case s.bundles <- bundle:
+	default:
+		bundleDropMeter.Mark(1)
+		log.Warn("Bundle submission queue full, dropping bundle", "block", bundle.BlockNumber)
+	}
+}
 
-			// bribe tx
-			bribeTx, bribeData, err := s.BloxRouteTx(5, big.NewInt(1e15), big.NewInt(1e9))
-			txs = append(txs, bribeTx)
-			txsData = append(txsData, bribeData)
+// submitLoop sends the queued bundles to the builders until quit is closed.
+func (s *Strategy) submitLoop(quit <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-quit
+		cancel()
+	}()
+	for {
+		select {
+		case bundle := <-s.bundles:
+			s.submit(ctx, bundle)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
 
-			// 打印事件详情
-			fmt.Printf("Swap Event: %+v\n", event)
+// submit sends bundle to the configured builders and logs the outcome.
+func (s *Strategy) submit(ctx context.Context, bundle *Bundle) {
+	results, err := s.builder.Submit(ctx, bundle)
+	if err != nil {
+		log.Warn("Failed to submit bundle", "block", bundle.BlockNumber, "err", err)
+		return
+	}
+	for _, res := range results {
+		if res.Err != nil {
+			log.Warn("Failed to submit bundle", "builder", res.Builder, "block", bundle.BlockNumber, "err", res.Err)
+			continue
 		}
+		log.Debug("Bundle submitted", "hash", res.BundleHash, "builder", res.Builder, "block", bundle.BlockNumber)
 	}
-	return receipt, nil
 }
 
-func (s *Strategy) SimulateTxs(tx []*types.Transaction) (*types.Receipt, error) {
+// sandwich sizes a sandwich around intent, a single hop swap through pool,
+// on statedb. Costs are priced in the input token of the victim, the net
+// profit is returned in BNB along with the sandwich.
+func (s *Strategy) sandwich(statedb *state.StateDB, addr common.Address, intent *SwapIntent) (*amm.Sandwich, *big.Int, error) {
+	spacing, ok := amm.PancakeV3TickSpacing[intent.Fees[0]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown fee tier %d", intent.Fees[0])
+	}
+	pool, err := amm.LoadV3Pool(statedb, addr, amm.PancakeV3Layout, intent.Fees[0], spacing, sandwichTickWords)
+	if err != nil {
+		return nil, nil, err
+	}
+	zeroForOne := bytes.Compare(intent.TokenIn().Bytes(), intent.TokenOut().Bytes()) < 0
+
+	// The gas and the bribe are paid in BNB, price them in the input token
+	// through the pool itself.
+	cost := big.NewInt(sandwichGas*bundleGasPrice + bribeAmount)
+	switch wbnbAddress {
+	case intent.TokenIn():
+	case intent.TokenOut():
+		if cost, _, err = pool.Swap(cost, !zeroForOne); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("no BNB price for %s", intent.TokenIn().Hex())
+	}
+	victim := amm.Victim{
+		AmountIn:     intent.AmountIn,
+		AmountOutMin: intent.AmountOutMin,
+		ZeroForOne:   zeroForOne,
+	}
+	maxIn := new(big.Int).Mul(intent.AmountIn, big.NewInt(maxFrontRunRatio))
+	sandwich, err := amm.OptimalSandwich(pool, victim, maxIn, amm.Costs{Gas: cost})
+	if err != nil {
+		return nil, nil, err
+	}
+	profit := sandwich.Profit
+	if intent.TokenIn() != wbnbAddress {
+		if profit, _, err = pool.Swap(profit, zeroForOne); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sandwich, profit, nil
+}
 
+// SimulateTxs executes txs in order on a copy of the head state, as if they
+// were included in the next block, and reports the outcome of each of them.
+func (s *Strategy) SimulateTxs(txs []*types.Transaction) (*SimResult, error) {
+	return s.simulator.Simulate(txs)
 }
 
 func (s *Strategy) CtreateExactInputTx(tokenIn common.Address, tokenOut common.Address,
-	amountIn *big.Int, nonce uint64) (*types.Transaction, error) {
+	fee *big.Int, amountIn *big.Int, nonce uint64) (*types.Transaction, error) {
 
 	params := ExactInputParams{
 		TokenIn:           tokenIn,
 		TokenOut:          tokenOut,
-		Fee:               big.NewInt(500),
-		Recipient:         userAdress,
+		Fee:               fee,
+		Recipient:         s.signer.Address(),
 		Deadline:          big.NewInt(time.Now().Add(time.Hour).Unix()), // 设置为当前时间加1小时
 		AmountIn:          amountIn,
 		AmountOutMinimum:  big.NewInt(0),
@@ -193,7 +366,7 @@ func (s *Strategy) CtreateExactInputTx(tokenIn common.Address, tokenOut common.A
 
 	data, err := s.routerAbi.Pack("exactInputSingle", params)
 	if err != nil {
-		log.Fatalf("Failed to pack data: %v", err)
+		return nil, fmt.Errorf("failed to pack swap data: %w", err)
 	}
 
 	// {
@@ -204,8 +377,7 @@ func (s *Strategy) CtreateExactInputTx(tokenIn common.Address, tokenOut common.A
 	// 	GasPrice: gasPrice,
 	// 	Data:     data,
 	// }
-	gasPrice := big.NewInt(1e9) //  1 Gwei
-	tx := types.NewTransaction(nonce, v3RouterAdress, big.NewInt(0), 300000, gasPrice, data)
+	tx := types.NewTransaction(nonce, v3RouterAddress, big.NewInt(0), swapGasLimit, big.NewInt(bundleGasPrice), data)
 	return tx, nil
 
 }
@@ -213,71 +385,63 @@ func (s *Strategy) CtreateExactInputTx(tokenIn common.Address, tokenOut common.A
 func (s *Strategy) CtreateExactOutPutTx(tokenIn common.Address, tokenOut common.Address,
 	amountOut *big.Int, nonce uint64) (*types.Transaction, error) {
 
-	params := ExactInputParams{
+	params := ExactOutPutParams{
 		TokenIn:           tokenIn,
 		TokenOut:          tokenOut,
 		Fee:               big.NewInt(500),
-		Recipient:         userAdress,
+		Recipient:         s.signer.Address(),
 		Deadline:          big.NewInt(time.Now().Add(time.Hour).Unix()), // 设置为当前时间加1小时
-		AmountIn:          amountOut,
-		AmountOutMinimum:  big.NewInt(0),
+		AmountOut:         amountOut,
+		AmountInMaximum:   abi.MaxUint256,
 		SqrtPriceLimitX96: big.NewInt(0),
 	}
 
 	data, err := s.routerAbi.Pack("exactOutputSingle", params)
 	if err != nil {
-		log.Fatalf("Failed to pack data: %v", err)
+		return nil, fmt.Errorf("failed to pack swap data: %w", err)
 	}
-	gasPrice := big.NewInt(1e9) //  1 Gwei
-	tx := types.NewTransaction(nonce, v3RouterAdress, big.NewInt(0), 300000, gasPrice, data)
+	tx := types.NewTransaction(nonce, v3RouterAddress, big.NewInt(0), swapGasLimit, big.NewInt(bundleGasPrice), data)
 	return tx, nil
 
 }
 
 // 买入bnb, 使用ExactOut，固定买入一个bnb
 func (s *Strategy) FrontTrans(bnb *big.Int, nonce uint64) (*types.Transaction, string, error) {
-	tx, err := s.CtreateExactOutPutTx(usdtAddress, bnbAdress, bnb, nonce)
+	tx, err := s.CtreateExactOutPutTx(usdtAddress, wbnbAddress, bnb, nonce)
 	if err != nil {
 		return nil, "", err
 	}
-	rawTxHex, err := s.SignTx(tx)
-	return tx, rawTxHex, err
+	return s.SignTx(tx)
 }
 
 // 卖出bnb，使用ExactInput，固定卖出一个bnb
 func (s *Strategy) BackTrans(bnb *big.Int, nonce uint64) (*types.Transaction, string, error) {
-	tx, err := s.CtreateExactInputTx(bnbAdress, usdtAddress, bnb, nonce)
+	tx, err := s.CtreateExactInputTx(wbnbAddress, usdtAddress, big.NewInt(500), bnb, nonce)
 	if err != nil {
 		return nil, "", err
 	}
-	rawTxHex, err := s.SignTx(tx)
-	return tx, rawTxHex, err
+	return s.SignTx(tx)
 }
 
+// BloxRouteTx pays amount to the bloXroute builder, which only includes the
+// bundles paying it.
 func (s *Strategy) BloxRouteTx(nonce uint64, amount *big.Int, gasPrice *big.Int) (*types.Transaction, string, error) {
-	tx := types.NewTransaction(nonce, v3RouterAdress, amount, 300000, gasPrice, nil)
-	rawTxHex, err := s.SignTx(tx)
-	return tx, rawTxHex, err
+	tx := types.NewTransaction(nonce, bloxrouteBuilder, amount, params.TxGas, gasPrice, nil)
+	return s.SignTx(tx)
 }
 
-func (s *Strategy) SignTx(tx *types.Transaction) (string, error) {
-	// 签名交易
-	chainID := big.NewInt(56)
-	privateKey, err := crypto.HexToECDSA("your_private_key_here")
+// SignTx signs tx with the trading account and returns the signed tx along
+// with its hex encoded binary form.
+func (s *Strategy) SignTx(tx *types.Transaction) (*types.Transaction, string, error) {
+	signedTx, err := s.signer.SignTx(tx)
 	if err != nil {
-		log.Fatalf("Failed to parse private key: %v", err)
-	}
-
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		log.Fatalf("Failed to sign transaction: %v", err)
+		return nil, "", err
 	}
-
 	txData, err := s.GetData(signedTx)
 	if err != nil {
-		log.Fatalf("Failed to sign transaction: %v", err)
+		return nil, "", err
 	}
-	return txData, nil
+	return signedTx, txData, nil
 }
 
 func (s *Strategy) GetData(tx *types.Transaction) (string, error) {
@@ -0,0 +1,171 @@
+package quant
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Token is the metadata of an ERC-20 token.
+type Token struct {
+	Address  common.Address `json:"address"`
+	Symbol   string         `json:"symbol"`
+	Decimals uint8          `json:"decimals"`
+}
+
+// Normalise converts a raw token amount into whole token units.
+func (t *Token) Normalise(amount *big.Int) float64 {
+	if amount == nil {
+		return 0
+	}
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Decimals)), nil)
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(unit)).Float64()
+	return f
+}
+
+// Pair is a pool together with the metadata of the two tokens it trades.
+type Pair struct {
+	Address common.Address `json:"address"`
+	Token0  *Token         `json:"token0"`
+	Token1  *Token         `json:"token1"`
+}
+
+// TokenRegistry resolves token and pool metadata by calling the contracts
+// against the local head state. Results are cached for the lifetime of the
+// registry since symbol, decimals and pool tokens are immutable in practice.
+type TokenRegistry struct {
+	backend  ethapi.Backend
+	erc20Abi *abi.ABI
+	poolAbi  *abi.ABI
+
+	lock   sync.RWMutex
+	tokens map[common.Address]*Token
+	pairs  map[common.Address]*Pair
+}
+
+// NewTokenRegistry creates a token registry executing calls on backend.
+func NewTokenRegistry(backend ethapi.Backend) (*TokenRegistry, error) {
+	erc20Abi, err := ParseERC20Abi()
+	if err != nil {
+		return nil, err
+	}
+	poolAbi, err := ParseAbi()
+	if err != nil {
+		return nil, err
+	}
+	return &TokenRegistry{
+		backend:  backend,
+		erc20Abi: erc20Abi,
+		poolAbi:  poolAbi,
+		tokens:   make(map[common.Address]*Token),
+		pairs:    make(map[common.Address]*Pair),
+	}, nil
+}
+
+// Token returns the metadata of the token at addr.
+func (r *TokenRegistry) Token(ctx context.Context, addr common.Address) (*Token, error) {
+	r.lock.RLock()
+	token, ok := r.tokens[addr]
+	r.lock.RUnlock()
+	if ok {
+		return token, nil
+	}
+	ret, err := r.call(ctx, r.erc20Abi, addr, "decimals")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decimals of %s: %w", addr, err)
+	}
+	out, err := r.erc20Abi.Unpack("decimals", ret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack decimals of %s: %w", addr, err)
+	}
+	token = &Token{Address: addr, Decimals: *abi.ConvertType(out[0], new(uint8)).(*uint8)}
+
+	// The symbol is informational only, fall back to the address for tokens
+	// not implementing it.
+	token.Symbol = addr.Hex()
+	if ret, err := r.call(ctx, r.erc20Abi, addr, "symbol"); err == nil {
+		if symbol, ok := decodeSymbol(r.erc20Abi, ret); ok {
+			token.Symbol = symbol
+		}
+	}
+	r.lock.Lock()
+	r.tokens[addr] = token
+	r.lock.Unlock()
+	return token, nil
+}
+
+// Pair returns the pool at addr along with the metadata of its tokens.
+func (r *TokenRegistry) Pair(ctx context.Context, addr common.Address) (*Pair, error) {
+	r.lock.RLock()
+	pair, ok := r.pairs[addr]
+	r.lock.RUnlock()
+	if ok {
+		return pair, nil
+	}
+	var tokens [2]*Token
+	for i, method := range []string{"token0", "token1"} {
+		ret, err := r.call(ctx, r.poolAbi, addr, method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s of pool %s: %w", method, addr, err)
+		}
+		out, err := r.poolAbi.Unpack(method, ret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack %s of pool %s: %w", method, addr, err)
+		}
+		token, err := r.Token(ctx, *abi.ConvertType(out[0], new(common.Address)).(*common.Address))
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = token
+	}
+	pair = &Pair{Address: addr, Token0: tokens[0], Token1: tokens[1]}
+
+	r.lock.Lock()
+	r.pairs[addr] = pair
+	r.lock.Unlock()
+	return pair, nil
+}
+
+// call executes a read-only contract method against the latest local state.
+func (r *TokenRegistry) call(ctx context.Context, contract *abi.ABI, to common.Address, method string) ([]byte, error) {
+	data, err := contract.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	input := hexutil.Bytes(data)
+	args := ethapi.TransactionArgs{To: &to, Input: &input}
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	result, err := ethapi.DoCall(ctx, r.backend, args, latest, nil, nil, r.backend.RPCEVMTimeout(), r.backend.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	if result.Failed() {
+		return nil, result.Err
+	}
+	return result.Return(), nil
+}
+
+// decodeSymbol decodes the return value of symbol(). A few old tokens return
+// bytes32 instead of string, which is handled as well.
+func decodeSymbol(erc20Abi *abi.ABI, ret []byte) (string, bool) {
+	if out, err := erc20Abi.Unpack("symbol", ret); err == nil {
+		if symbol, ok := out[0].(string); ok && symbol != "" {
+			return symbol, true
+		}
+	}
+	if len(ret) == 32 {
+		if symbol := string(bytes.TrimRight(ret, "\x00")); symbol != "" {
+			return symbol, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,76 @@
+package quant
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEmbeddedAbis(t *testing.T) {
+	pool, err := ParseAbi()
+	if err != nil {
+		t.Fatalf("failed to parse pool abi: %v", err)
+	}
+	if _, ok := pool.Events["Swap"]; !ok {
+		t.Fatal("pool abi misses the Swap event")
+	}
+	router, err := ParseRouterAbi()
+	if err != nil {
+		t.Fatalf("failed to parse router abi: %v", err)
+	}
+	if _, ok := router.Methods["exactInputSingle"]; !ok {
+		t.Fatal("router abi misses exactInputSingle")
+	}
+	v2, err := ParseV2RouterAbi()
+	if err != nil {
+		t.Fatalf("failed to parse v2 router abi: %v", err)
+	}
+	if _, ok := v2.Methods["swapExactTokensForTokens"]; !ok {
+		t.Fatal("v2 router abi misses swapExactTokensForTokens")
+	}
+	if _, err := ParseERC20Abi(); err != nil {
+		t.Fatalf("failed to parse erc20 abi: %v", err)
+	}
+}
+
+func TestDecodeSymbol(t *testing.T) {
+	erc20, err := ParseERC20Abi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret, err := erc20.Methods["symbol"].Outputs.Pack("USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if symbol, ok := decodeSymbol(erc20, ret); !ok || symbol != "USDT" {
+		t.Fatalf("string symbol mismatch: have %q, want %q", symbol, "USDT")
+	}
+	// Tokens like MKR return the symbol as bytes32
+	raw := common.RightPadBytes([]byte("MKR"), 32)
+	if symbol, ok := decodeSymbol(erc20, raw); !ok || symbol != "MKR" {
+		t.Fatalf("bytes32 symbol mismatch: have %q, want %q", symbol, "MKR")
+	}
+	if _, ok := decodeSymbol(erc20, nil); ok {
+		t.Fatal("empty return value decoded")
+	}
+}
+
+func TestTokenNormalise(t *testing.T) {
+	tests := []struct {
+		decimals uint8
+		amount   *big.Int
+		want     float64
+	}{
+		{18, new(big.Int).Mul(big.NewInt(15), big.NewInt(1e17)), 1.5},
+		{6, big.NewInt(-2500000), -2.5},
+		{0, big.NewInt(42), 42},
+		{18, nil, 0},
+	}
+	for i, tt := range tests {
+		token := &Token{Decimals: tt.decimals}
+		if have := token.Normalise(tt.amount); have != tt.want {
+			t.Errorf("test %d: have %v, want %v", i, have, tt.want)
+		}
+	}
+}